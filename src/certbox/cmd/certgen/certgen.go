@@ -16,4 +16,17 @@ const (
 	ActionConvertDERtoPEM       = "CONVERT_DER_PEM"
 	ActionExtractPKCS12         = "EXTRACT_P12"
 	ActionCreatePKCS12          = "CREATE_PKCS12"
+	ActionGenerateCRL           = "GENERATE_CRL"
+	ActionParseCRL              = "PARSE_CRL"
+	ActionOCSPResponder         = "OCSP_RESPONDER"
+	ActionSignCSR               = "SIGN_CSR"
+	ActionRenewCertificate      = "RENEW_CERTIFICATE"
+	ActionFaithfulClone         = "FAITHFUL_CLONE_CERTIFICATE"
+	ActionCloneChain            = "CLONE_CERTIFICATE_CHAIN"
+	ActionProbeServer           = "PROBE_SERVER"
+	ActionInspectCertificate    = "INSPECT_CERTIFICATE"
+	ActionInspectText           = "INSPECT_TEXT"
+	ActionVerifyChain           = "VERIFY_CHAIN"
+	ActionLint                  = "LINT"
+	ActionDiffCertificates      = "DIFF_CERTIFICATES"
 )
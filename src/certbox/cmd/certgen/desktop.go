@@ -4,13 +4,20 @@ package main
 
 import (
 	"bufio"
+	"context"
 	"encoding/json"
 	"fmt"
+	"net"
+	"net/http"
 	"os"
+	"os/signal"
 	"path"
 	"runtime"
+	"syscall"
 
 	"github.com/tls-inspector/certbox"
+	"github.com/tls-inspector/certbox/ocsp"
+	"github.com/tls-inspector/certbox/probe"
 )
 
 func main() {
@@ -45,6 +52,32 @@ func main() {
 		convertPemDer(parameterBytes)
 	case ActionConvertDERtoPEM:
 		convertDerPem(parameterBytes)
+	case ActionGenerateCRL:
+		generateCRL(parameterBytes)
+	case ActionParseCRL:
+		parseCRL(parameterBytes)
+	case ActionOCSPResponder:
+		ocspResponder(parameterBytes)
+	case ActionSignCSR:
+		signCSR(parameterBytes)
+	case ActionRenewCertificate:
+		renewCertificate(parameterBytes)
+	case ActionFaithfulClone:
+		faithfulCloneCertificate(parameterBytes)
+	case ActionCloneChain:
+		cloneCertificateChain(parameterBytes)
+	case ActionProbeServer:
+		probeServer(parameterBytes)
+	case ActionInspectCertificate:
+		inspectCertificate(parameterBytes)
+	case ActionInspectText:
+		inspectText(parameterBytes)
+	case ActionVerifyChain:
+		verifyChain(parameterBytes)
+	case ActionLint:
+		lintCertificate(parameterBytes)
+	case ActionDiffCertificates:
+		diffCertificates(parameterBytes)
 	default:
 		fatalError("Unknown action " + action)
 	}
@@ -213,3 +246,218 @@ func convertDerPem(parameterBytes []byte) {
 
 	json.NewEncoder(os.Stdout).Encode(result)
 }
+
+func generateCRL(parameterBytes []byte) {
+	parameters := certbox.GenerateCRLParameters{}
+	if err := json.Unmarshal(parameterBytes, &parameters); err != nil {
+		fatalError(err)
+	}
+
+	result, err := certbox.GenerateCRL(parameters)
+	if err != nil {
+		fatalError(err)
+	}
+
+	json.NewEncoder(os.Stdout).Encode(result)
+}
+
+func parseCRL(parameterBytes []byte) {
+	parameters := certbox.ParseCRLParameters{}
+	if err := json.Unmarshal(parameterBytes, &parameters); err != nil {
+		fatalError(err)
+	}
+
+	result, err := certbox.ParseCRL(parameters)
+	if err != nil {
+		fatalError(err)
+	}
+
+	json.NewEncoder(os.Stdout).Encode(result)
+}
+
+type OCSPResponderParameters struct {
+	ocsp.Options
+	// Address to listen on, defaults to a random port on the loopback interface
+	Address string
+}
+
+func ocspResponder(parameterBytes []byte) {
+	parameters := OCSPResponderParameters{}
+	if err := json.Unmarshal(parameterBytes, &parameters); err != nil {
+		fatalError(err)
+	}
+
+	responder, err := ocsp.NewResponder(parameters.Options)
+	if err != nil {
+		fatalError(err)
+	}
+
+	address := parameters.Address
+	if address == "" {
+		address = "127.0.0.1:0"
+	}
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		fatalError(err)
+	}
+
+	type responseType struct {
+		Address string
+	}
+	// The responder runs until the process is interrupted or terminated, so the address is reported as soon as it's
+	// listening rather than when the process exits
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	server := &http.Server{Handler: responder}
+	go func() {
+		<-ctx.Done()
+		server.Shutdown(context.Background())
+	}()
+
+	json.NewEncoder(os.Stdout).Encode(responseType{listener.Addr().String()})
+
+	if err := server.Serve(listener); err != nil && err != http.ErrServerClosed {
+		fatalError(err)
+	}
+}
+
+func signCSR(parameterBytes []byte) {
+	parameters := certbox.SignCSRParameters{}
+	if err := json.Unmarshal(parameterBytes, &parameters); err != nil {
+		fatalError(err)
+	}
+
+	certificate, err := certbox.SignCSR(parameters)
+	if err != nil {
+		fatalError(err)
+	}
+
+	json.NewEncoder(os.Stdout).Encode(certificate)
+}
+
+func renewCertificate(parameterBytes []byte) {
+	parameters := certbox.RenewCertificateParameters{}
+	if err := json.Unmarshal(parameterBytes, &parameters); err != nil {
+		fatalError(err)
+	}
+
+	certificate, err := certbox.RenewCertificate(parameters)
+	if err != nil {
+		fatalError(err)
+	}
+
+	json.NewEncoder(os.Stdout).Encode(certificate)
+}
+
+func faithfulCloneCertificate(parameterBytes []byte) {
+	parameters := certbox.FaithfulCloneCertificateParameters{}
+	if err := json.Unmarshal(parameterBytes, &parameters); err != nil {
+		fatalError(err)
+	}
+
+	certificates, err := certbox.FaithfulCloneCertificate(parameters)
+	if err != nil {
+		fatalError(err)
+	}
+
+	json.NewEncoder(os.Stdout).Encode(certificates)
+}
+
+func cloneCertificateChain(parameterBytes []byte) {
+	parameters := certbox.CloneCertificateChainParameters{}
+	if err := json.Unmarshal(parameterBytes, &parameters); err != nil {
+		fatalError(err)
+	}
+
+	certificates, err := certbox.CloneCertificateChain(parameters)
+	if err != nil {
+		fatalError(err)
+	}
+
+	json.NewEncoder(os.Stdout).Encode(certificates)
+}
+
+func probeServer(parameterBytes []byte) {
+	parameters := probe.Options{}
+	if err := json.Unmarshal(parameterBytes, &parameters); err != nil {
+		fatalError(err)
+	}
+
+	result, err := probe.Probe(parameters)
+	if err != nil {
+		fatalError(err)
+	}
+
+	json.NewEncoder(os.Stdout).Encode(result)
+}
+
+func inspectCertificate(parameterBytes []byte) {
+	parameters := certbox.InspectCertificateParameters{}
+	if err := json.Unmarshal(parameterBytes, &parameters); err != nil {
+		fatalError(err)
+	}
+
+	details, err := certbox.InspectCertificate(parameters)
+	if err != nil {
+		fatalError(err)
+	}
+
+	json.NewEncoder(os.Stdout).Encode(details)
+}
+
+func inspectText(parameterBytes []byte) {
+	parameters := certbox.InspectTextParameters{}
+	if err := json.Unmarshal(parameterBytes, &parameters); err != nil {
+		fatalError(err)
+	}
+
+	text, err := certbox.InspectText(parameters)
+	if err != nil {
+		fatalError(err)
+	}
+
+	json.NewEncoder(os.Stdout).Encode(text)
+}
+
+func verifyChain(parameterBytes []byte) {
+	parameters := certbox.VerifyChainParameters{}
+	if err := json.Unmarshal(parameterBytes, &parameters); err != nil {
+		fatalError(err)
+	}
+
+	result, err := certbox.VerifyChain(parameters)
+	if err != nil {
+		fatalError(err)
+	}
+
+	json.NewEncoder(os.Stdout).Encode(result)
+}
+
+func lintCertificate(parameterBytes []byte) {
+	parameters := certbox.LintParameters{}
+	if err := json.Unmarshal(parameterBytes, &parameters); err != nil {
+		fatalError(err)
+	}
+
+	findings, err := certbox.Lint(parameters)
+	if err != nil {
+		fatalError(err)
+	}
+
+	json.NewEncoder(os.Stdout).Encode(findings)
+}
+
+func diffCertificates(parameterBytes []byte) {
+	parameters := certbox.DiffCertificatesParameters{}
+	if err := json.Unmarshal(parameterBytes, &parameters); err != nil {
+		fatalError(err)
+	}
+
+	diff, err := certbox.DiffCertificates(parameters)
+	if err != nil {
+		fatalError(err)
+	}
+
+	json.NewEncoder(os.Stdout).Encode(diff)
+}
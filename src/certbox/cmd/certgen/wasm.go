@@ -33,6 +33,17 @@ func main() {
 	js.Global().Set("ExportCSR", jsExportCSR())
 	js.Global().Set("ExportCertificates", jsExportCertificates())
 	js.Global().Set("ZipFiles", jsZipFiles())
+	js.Global().Set("GenerateCRL", jsGenerateCRL())
+	js.Global().Set("ParseCRL", jsParseCRL())
+	js.Global().Set("SignCSR", jsSignCSR())
+	js.Global().Set("RenewCertificate", jsRenewCertificate())
+	js.Global().Set("FaithfulCloneCertificate", jsFaithfulCloneCertificate())
+	js.Global().Set("CloneCertificateChain", jsCloneCertificateChain())
+	js.Global().Set("InspectCertificate", jsInspectCertificate())
+	js.Global().Set("InspectText", jsInspectText())
+	js.Global().Set("VerifyChain", jsVerifyChain())
+	js.Global().Set("Lint", jsLint())
+	js.Global().Set("DiffCertificates", jsDiffCertificates())
 	<-make(chan bool)
 }
 
@@ -252,6 +263,269 @@ func jsZipFiles() js.Func {
 	})
 }
 
+func jsGenerateCRL() js.Func {
+	return js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		fmt.Printf("invoke: GenerateCRL()\n")
+
+		defer func() {
+			recover()
+		}()
+
+		params := certbox.GenerateCRLParameters{}
+		if err := json.Unmarshal([]byte(args[0].String()), &params); err != nil {
+			return WasmError(err)
+		}
+		response, err := certbox.GenerateCRL(params)
+		if err != nil {
+			return WasmError(err)
+		}
+		data, err := json.Marshal(response)
+		if err != nil {
+			return WasmError(err)
+		}
+		return string(data)
+	})
+}
+
+func jsParseCRL() js.Func {
+	return js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		fmt.Printf("invoke: ParseCRL()\n")
+
+		defer func() {
+			recover()
+		}()
+
+		parameters := certbox.ParseCRLParameters{
+			Data: jsValueToByte(args[0]),
+		}
+		response, err := certbox.ParseCRL(parameters)
+		if err != nil {
+			return WasmError(err)
+		}
+		data, err := json.Marshal(response)
+		if err != nil {
+			return WasmError(err)
+		}
+		return string(data)
+	})
+}
+
+func jsSignCSR() js.Func {
+	return js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		fmt.Printf("invoke: SignCSR()\n")
+
+		defer func() {
+			recover()
+		}()
+
+		params := certbox.SignCSRParameters{}
+		if err := json.Unmarshal([]byte(args[0].String()), &params); err != nil {
+			return WasmError(err)
+		}
+		response, err := certbox.SignCSR(params)
+		if err != nil {
+			return WasmError(err)
+		}
+		data, err := json.Marshal(response)
+		if err != nil {
+			return WasmError(err)
+		}
+		return string(data)
+	})
+}
+
+func jsRenewCertificate() js.Func {
+	return js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		fmt.Printf("invoke: RenewCertificate()\n")
+
+		defer func() {
+			recover()
+		}()
+
+		params := certbox.RenewCertificateParameters{}
+		if err := json.Unmarshal([]byte(args[0].String()), &params); err != nil {
+			return WasmError(err)
+		}
+		response, err := certbox.RenewCertificate(params)
+		if err != nil {
+			return WasmError(err)
+		}
+		data, err := json.Marshal(response)
+		if err != nil {
+			return WasmError(err)
+		}
+		return string(data)
+	})
+}
+
+func jsFaithfulCloneCertificate() js.Func {
+	return js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		fmt.Printf("invoke: FaithfulCloneCertificate()\n")
+
+		defer func() {
+			recover()
+		}()
+
+		params := certbox.FaithfulCloneCertificateParameters{}
+		if err := json.Unmarshal([]byte(args[0].String()), &params); err != nil {
+			return WasmError(err)
+		}
+		response, err := certbox.FaithfulCloneCertificate(params)
+		if err != nil {
+			return WasmError(err)
+		}
+		data, err := json.Marshal(response)
+		if err != nil {
+			return WasmError(err)
+		}
+		return string(data)
+	})
+}
+
+func jsCloneCertificateChain() js.Func {
+	return js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		fmt.Printf("invoke: CloneCertificateChain()\n")
+
+		defer func() {
+			recover()
+		}()
+
+		params := certbox.CloneCertificateChainParameters{}
+		if err := json.Unmarshal([]byte(args[0].String()), &params); err != nil {
+			return WasmError(err)
+		}
+		response, err := certbox.CloneCertificateChain(params)
+		if err != nil {
+			return WasmError(err)
+		}
+		data, err := json.Marshal(response)
+		if err != nil {
+			return WasmError(err)
+		}
+		return string(data)
+	})
+}
+
+func jsInspectCertificate() js.Func {
+	return js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		fmt.Printf("invoke: InspectCertificate()\n")
+
+		defer func() {
+			recover()
+		}()
+
+		params := certbox.InspectCertificateParameters{}
+		if err := json.Unmarshal([]byte(args[0].String()), &params); err != nil {
+			return WasmError(err)
+		}
+		response, err := certbox.InspectCertificate(params)
+		if err != nil {
+			return WasmError(err)
+		}
+		data, err := json.Marshal(response)
+		if err != nil {
+			return WasmError(err)
+		}
+		return string(data)
+	})
+}
+
+func jsInspectText() js.Func {
+	return js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		fmt.Printf("invoke: InspectText()\n")
+
+		defer func() {
+			recover()
+		}()
+
+		params := certbox.InspectTextParameters{}
+		if err := json.Unmarshal([]byte(args[0].String()), &params); err != nil {
+			return WasmError(err)
+		}
+		response, err := certbox.InspectText(params)
+		if err != nil {
+			return WasmError(err)
+		}
+		data, err := json.Marshal(response)
+		if err != nil {
+			return WasmError(err)
+		}
+		return string(data)
+	})
+}
+
+func jsVerifyChain() js.Func {
+	return js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		fmt.Printf("invoke: VerifyChain()\n")
+
+		defer func() {
+			recover()
+		}()
+
+		params := certbox.VerifyChainParameters{}
+		if err := json.Unmarshal([]byte(args[0].String()), &params); err != nil {
+			return WasmError(err)
+		}
+		response, err := certbox.VerifyChain(params)
+		if err != nil {
+			return WasmError(err)
+		}
+		data, err := json.Marshal(response)
+		if err != nil {
+			return WasmError(err)
+		}
+		return string(data)
+	})
+}
+
+func jsLint() js.Func {
+	return js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		fmt.Printf("invoke: Lint()\n")
+
+		defer func() {
+			recover()
+		}()
+
+		params := certbox.LintParameters{}
+		if err := json.Unmarshal([]byte(args[0].String()), &params); err != nil {
+			return WasmError(err)
+		}
+		response, err := certbox.Lint(params)
+		if err != nil {
+			return WasmError(err)
+		}
+		data, err := json.Marshal(response)
+		if err != nil {
+			return WasmError(err)
+		}
+		return string(data)
+	})
+}
+
+func jsDiffCertificates() js.Func {
+	return js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		fmt.Printf("invoke: DiffCertificates()\n")
+
+		defer func() {
+			recover()
+		}()
+
+		params := certbox.DiffCertificatesParameters{}
+		if err := json.Unmarshal([]byte(args[0].String()), &params); err != nil {
+			return WasmError(err)
+		}
+		response, err := certbox.DiffCertificates(params)
+		if err != nil {
+			return WasmError(err)
+		}
+		data, err := json.Marshal(response)
+		if err != nil {
+			return WasmError(err)
+		}
+		return string(data)
+	})
+}
+
 func jsValueToByte(v js.Value) []byte {
 	length := v.Length()
 	data := make([]byte, length)
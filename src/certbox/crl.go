@@ -0,0 +1,53 @@
+package certbox
+
+import (
+	"encoding/pem"
+	"fmt"
+
+	"github.com/tls-inspector/certbox/tls"
+)
+
+// GenerateCRLParameters describes the parameters for generating a certificate revocation list
+type GenerateCRLParameters struct {
+	Request tls.CRLRequest
+	Issuer  tls.Certificate
+	Format  string
+}
+
+// ExportedCRL describes the response from generating a certificate revocation list
+type ExportedCRL struct {
+	Name string
+	Data []byte
+}
+
+// GenerateCRL will generate a certificate revocation list signed by the given issuer in either PEM or DER format
+func GenerateCRL(parameters GenerateCRLParameters) (*ExportedCRL, error) {
+	data, err := tls.GenerateCRL(parameters.Request, &parameters.Issuer)
+	if err != nil {
+		return nil, err
+	}
+
+	switch parameters.Format {
+	case FormatPEM:
+		data = pem.EncodeToMemory(&pem.Block{Type: "X509 CRL", Bytes: data})
+	case FormatDER:
+		break
+	default:
+		return nil, fmt.Errorf("unknown export format %s", parameters.Format)
+	}
+
+	return &ExportedCRL{
+		Name: filenameSafeString(parameters.Issuer.Subject.CommonName) + "_" + parameters.Request.Number + ".crl",
+		Data: data,
+	}, nil
+}
+
+// ParseCRLParameters describes the parameters for parsing a certificate revocation list
+type ParseCRLParameters struct {
+	Data []byte
+}
+
+// ParseCRL will return a CRL request that describes the given PEM or DER encoded certificate revocation list
+func ParseCRL(parameters ParseCRLParameters) (*tls.CRLRequest, error) {
+	return tls.ParseCRL(parameters.Data)
+}
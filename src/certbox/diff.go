@@ -0,0 +1,30 @@
+package certbox
+
+import (
+	"fmt"
+
+	"github.com/tls-inspector/certbox/tls"
+)
+
+// DiffCertificatesParameters parameters for comparing a certificate with another certificate or a certificate request
+type DiffCertificatesParameters struct {
+	A tls.Certificate
+	// B is the certificate to compare with A. Ignored if Request is set.
+	B *tls.Certificate
+	// Request is compared with A as the certificate that would be generated by Issuer. Issuer may be nil for a
+	// self-signed certificate.
+	Request *tls.CertificateRequest
+	Issuer  *tls.Certificate
+}
+
+// DiffCertificates will compare a certificate with another certificate or a certificate request field by field
+func DiffCertificates(parameters DiffCertificatesParameters) (*tls.CertificateDiff, error) {
+	if parameters.Request != nil {
+		return tls.DiffCertificateRequest(parameters.A, *parameters.Request, parameters.Issuer)
+	}
+	if parameters.B == nil {
+		return nil, fmt.Errorf("a certificate or certificate request to compare with is required")
+	}
+
+	return tls.DiffCertificates(parameters.A, *parameters.B)
+}
@@ -28,6 +28,16 @@ type ExportedCertificate struct {
 
 // ExportCertificates will generate appropriate files for the given certificates
 func ExportCertificates(parameters ExportCertificatesParameters) ([]ExportedCertificate, error) {
+	return exportCertificates(parameters, tls.ExportPKCS12)
+}
+
+// ExportCertificatesDeterministic is ExportCertificates with PKCS12 salts derived from the given entropy. THIS IS ONLY
+// FOR TESTS.
+func ExportCertificatesDeterministic(parameters ExportCertificatesParameters, entropy *tls.DeterministicEntropy) ([]ExportedCertificate, error) {
+	return exportCertificates(parameters, entropy.ExportPKCS12)
+}
+
+func exportCertificates(parameters ExportCertificatesParameters, exportPKCS12 func(*tls.Certificate, *tls.Certificate, string) ([]byte, error)) ([]ExportedCertificate, error) {
 	var root *tls.Certificate
 	for _, certificate := range parameters.Certificates {
 		if !certificate.X509().IsCA {
@@ -46,45 +56,45 @@ func ExportCertificates(parameters ExportCertificatesParameters) ([]ExportedCert
 				return nil, err
 			}
 
-			exportedCertificates = append(exportedCertificates, []ExportedCertificate{
-				{
-					Name: filenameSafeString(certificate.Subject.CommonName) + "_" + certificate.Serial[0:8] + ".crt",
-					Data: certData,
-				},
-				{
-					Name: filenameSafeString(certificate.Subject.CommonName) + "_" + certificate.Serial[0:8] + ".key",
+			exportedCertificates = append(exportedCertificates, ExportedCertificate{
+				Name: certificateFilename(certificate) + ".crt",
+				Data: certData,
+			})
+			if keyData != nil {
+				exportedCertificates = append(exportedCertificates, ExportedCertificate{
+					Name: certificateFilename(certificate) + ".key",
 					Data: keyData,
-				},
-			}...)
+				})
+			}
 		case FormatDER:
 			certData, keyData, err := tls.ExportDER(&certificate)
 			if err != nil {
 				return nil, err
 			}
 
-			exportedCertificates = append(exportedCertificates, []ExportedCertificate{
-				{
-					Name: filenameSafeString(certificate.Subject.CommonName) + "_" + certificate.Serial[0:8] + ".crt",
-					Data: certData,
-				},
-				{
-					Name: filenameSafeString(certificate.Subject.CommonName) + "_" + certificate.Serial[0:8] + ".key",
+			exportedCertificates = append(exportedCertificates, ExportedCertificate{
+				Name: certificateFilename(certificate) + ".crt",
+				Data: certData,
+			})
+			if keyData != nil {
+				exportedCertificates = append(exportedCertificates, ExportedCertificate{
+					Name: certificateFilename(certificate) + ".key",
 					Data: keyData,
-				},
-			}...)
+				})
+			}
 		case FormatP12:
 			var ca *tls.Certificate
 			if !certificate.CertificateAuthority {
 				ca = root
 			}
 
-			p12Data, err := tls.ExportPKCS12(&certificate, ca, parameters.Password)
+			p12Data, err := exportPKCS12(&certificate, ca, parameters.Password)
 			if err != nil {
 				return nil, err
 			}
 
 			exportedCertificates = append(exportedCertificates, ExportedCertificate{
-				Name: filenameSafeString(certificate.Subject.CommonName) + "_" + certificate.Serial[0:8] + ".p12",
+				Name: certificateFilename(certificate) + ".p12",
 				Data: p12Data,
 			})
 		default:
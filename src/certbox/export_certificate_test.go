@@ -0,0 +1,30 @@
+package certbox_test
+
+import (
+	"testing"
+
+	"github.com/tls-inspector/certbox"
+	"github.com/tls-inspector/certbox/tls"
+)
+
+func TestExportCertificatesShortSerial(t *testing.T) {
+	t.Parallel()
+
+	request := testRequest("leaf.example.com", false)
+	request.Serial = tls.SerialNumber{Strategy: tls.SerialStrategyExplicit, Value: "7"}
+	certificate, err := tls.GenerateCertificate(request, nil)
+	if err != nil {
+		t.Fatalf("Error generating certificate: %s", err.Error())
+	}
+
+	exported, err := certbox.ExportCertificates(certbox.ExportCertificatesParameters{
+		Certificates: []tls.Certificate{*certificate},
+		Format:       certbox.FormatPEM,
+	})
+	if err != nil {
+		t.Fatalf("Error exporting certificates: %s", err.Error())
+	}
+	if len(exported) != 2 || exported[0].Name != "leaf.example.com_7.crt" || exported[1].Name != "leaf.example.com_7.key" {
+		t.Errorf("Unexpected exported files %+v", exported)
+	}
+}
@@ -17,7 +17,17 @@ type ExportedCSR struct {
 
 // ExportCSR will generate appropriate files for the given certificates
 func ExportCSR(parameters ExportCSRParameters) ([]ExportedCSR, error) {
-	csrData, keyData, err := tls.ExportCSR(&parameters.Request)
+	return exportCSR(parameters, tls.ExportCSR)
+}
+
+// ExportCSRDeterministic is ExportCSR with the key and signature derived from the given entropy. THIS IS ONLY FOR
+// TESTS.
+func ExportCSRDeterministic(parameters ExportCSRParameters, entropy *tls.DeterministicEntropy) ([]ExportedCSR, error) {
+	return exportCSR(parameters, entropy.ExportCSR)
+}
+
+func exportCSR(parameters ExportCSRParameters, export func(*tls.CertificateRequest) ([]byte, []byte, error)) ([]ExportedCSR, error) {
+	csrData, keyData, err := export(&parameters.Request)
 	if err != nil {
 		return nil, err
 	}
@@ -1,6 +1,9 @@
 package certbox
 
 import (
+	"fmt"
+	"math/big"
+
 	"github.com/tls-inspector/certbox/tls"
 )
 
@@ -10,38 +13,192 @@ type GenerateCertificatesParameters struct {
 	ImportedRoot *tls.Certificate
 }
 
-// GenerateCertificates will generate associated keys for the given certificate requests
+// GenerateCertificates will generate associated keys for the given certificate requests.
+//
+// Requests may name their issuer by label using IssuerLabel, in which case they are signed by the certificate
+// generated for that request. Requests are generated in dependency order, so issuers may appear anywhere in the list.
+// Requests without an IssuerLabel keep the default behaviour: certificate authorities are self-signed (or ignored if
+// an imported root is provided) and all other certificates are signed by the root. A labeled certificate authority
+// without an IssuerLabel can not be combined with an imported root, as it would not be generated.
+//
+// Requests using tls.SerialStrategySequential are assigned consecutive serial numbers per issuer, in the order they are
+// generated, starting at the Value of the first such request.
+//
+// Certificates that would violate the name constraints or path length of any of their issuers are refused.
 func GenerateCertificates(parameters GenerateCertificatesParameters) ([]tls.Certificate, error) {
+	return generateCertificates(parameters, tls.GenerateCertificate)
+}
+
+// GenerateCertificatesDeterministic is GenerateCertificates with the keys, serial numbers and signatures derived from
+// the given entropy, producing byte-identical certificates for the same parameters. THIS IS ONLY FOR TESTS.
+func GenerateCertificatesDeterministic(parameters GenerateCertificatesParameters, entropy *tls.DeterministicEntropy) ([]tls.Certificate, error) {
+	return generateCertificates(parameters, entropy.GenerateCertificate)
+}
+
+func generateCertificates(parameters GenerateCertificatesParameters, generate func(tls.CertificateRequest, *tls.Certificate) (*tls.Certificate, error)) ([]tls.Certificate, error) {
+	order, err := sortRequestsByIssuer(parameters.Requests)
+	if err != nil {
+		return nil, err
+	}
+
 	var certificates = []tls.Certificate{}
-	var root tls.Certificate
+	var root *tls.Certificate
+	labeled := map[string]*tls.Certificate{}
+	// issuers of each labeled certificate, from the immediate issuer to the root
+	chains := map[string][]*tls.Certificate{}
+	// next sequential serial number of each issuer
+	sequences := map[*tls.Certificate]*big.Int{}
+
+	isSelfSigned := func(request tls.CertificateRequest) bool {
+		return request.IsCertificateAuthority && request.IssuerLabel == ""
+	}
+
 	if parameters.ImportedRoot != nil {
-		root = *parameters.ImportedRoot
+		for i, request := range parameters.Requests {
+			if isSelfSigned(request) && request.Label != "" {
+				return nil, fmt.Errorf("request %d: self-signed certificate authority '%s' can not be labeled when a root is imported", i, request.Label)
+			}
+		}
+		root = parameters.ImportedRoot
 	} else {
 		for _, request := range parameters.Requests {
-			if !request.IsCertificateAuthority {
+			if !isSelfSigned(request) {
 				continue
 			}
 
-			cert, err := tls.GenerateCertificate(request, nil)
+			cert, err := generate(request, nil)
 			if err != nil {
 				return nil, err
 			}
-			root = *cert
+			root = cert
+			if request.Label != "" {
+				labeled[request.Label] = cert
+			}
 			certificates = append(certificates, *cert)
 		}
 	}
 
-	for _, request := range parameters.Requests {
-		if request.IsCertificateAuthority {
+	for _, i := range order {
+		request := parameters.Requests[i]
+		if isSelfSigned(request) {
 			continue
 		}
 
-		cert, err := tls.GenerateCertificate(request, &root)
+		issuer := root
+		var chain []*tls.Certificate
+		if request.IssuerLabel != "" {
+			issuer = labeled[request.IssuerLabel]
+			if issuer == nil {
+				return nil, fmt.Errorf("issuer '%s' for request %d was not generated", request.IssuerLabel, i)
+			}
+			chain = chains[request.IssuerLabel]
+		}
+		if issuer == nil {
+			return nil, fmt.Errorf("no root certificate available to sign request %d", i)
+		}
+		chain = append([]*tls.Certificate{issuer}, chain...)
+
+		if request.Serial.Strategy == tls.SerialStrategySequential {
+			request.Serial.Value, err = nextSequentialSerial(sequences, issuer, request.Serial.Value)
+			if err != nil {
+				return nil, fmt.Errorf("request %d: invalid serial number: %s", i, err.Error())
+			}
+		}
+
+		cert, err := generate(request, issuer)
 		if err != nil {
 			return nil, err
 		}
+		if err := tls.CheckConstraints(cert, chain); err != nil {
+			return nil, fmt.Errorf("request %d: %s", i, err.Error())
+		}
+		if request.Label != "" {
+			labeled[request.Label] = cert
+			chains[request.Label] = chain
+		}
 		certificates = append(certificates, *cert)
 	}
 
 	return certificates, nil
 }
+
+// nextSequentialSerial returns the next serial number in the sequence of the given issuer, which begins at start or 1
+func nextSequentialSerial(sequences map[*tls.Certificate]*big.Int, issuer *tls.Certificate, start string) (string, error) {
+	serial, ok := sequences[issuer]
+	if !ok {
+		serial = big.NewInt(1)
+		if start != "" {
+			var err error
+			serial, err = tls.ParseSerial(start)
+			if err != nil {
+				return "", err
+			}
+		}
+	}
+	sequences[issuer] = new(big.Int).Add(serial, big.NewInt(1))
+	return serial.String(), nil
+}
+
+// sortRequestsByIssuer returns the indexes of the given requests ordered such that every request comes after the
+// request named by its IssuerLabel.
+func sortRequestsByIssuer(requests []tls.CertificateRequest) ([]int, error) {
+	labels := map[string]int{}
+	for i, request := range requests {
+		if request.Label == "" {
+			continue
+		}
+		if _, exists := labels[request.Label]; exists {
+			return nil, fmt.Errorf("duplicate request label '%s'", request.Label)
+		}
+		labels[request.Label] = i
+	}
+
+	for i, request := range requests {
+		if request.IssuerLabel == "" {
+			continue
+		}
+		parent, exists := labels[request.IssuerLabel]
+		if !exists {
+			return nil, fmt.Errorf("request %d names unknown issuer '%s'", i, request.IssuerLabel)
+		}
+		if !requests[parent].IsCertificateAuthority {
+			return nil, fmt.Errorf("issuer '%s' for request %d is not a certificate authority", request.IssuerLabel, i)
+		}
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make([]int, len(requests))
+	order := make([]int, 0, len(requests))
+
+	var visit func(i int) error
+	visit = func(i int) error {
+		switch state[i] {
+		case visited:
+			return nil
+		case visiting:
+			return fmt.Errorf("issuer cycle detected at request '%s'", requests[i].Label)
+		}
+
+		state[i] = visiting
+		if requests[i].IssuerLabel != "" {
+			if err := visit(labels[requests[i].IssuerLabel]); err != nil {
+				return err
+			}
+		}
+		state[i] = visited
+		order = append(order, i)
+		return nil
+	}
+
+	for i := range requests {
+		if err := visit(i); err != nil {
+			return nil, err
+		}
+	}
+
+	return order, nil
+}
@@ -0,0 +1,262 @@
+package certbox_test
+
+import (
+	"crypto/x509"
+	"testing"
+	"time"
+
+	"github.com/tls-inspector/certbox"
+	"github.com/tls-inspector/certbox/tls"
+)
+
+func testRequest(commonName string, isCA bool) tls.CertificateRequest {
+	request := tls.CertificateRequest{
+		KeyType:            tls.KeyTypeECDSA_256,
+		SignatureAlgorithm: tls.SignatureAlgorithmSHA256,
+		Subject: tls.Name{
+			Organization: "example.com",
+			Country:      "CA",
+			CommonName:   commonName,
+		},
+		Validity: tls.DateRange{
+			NotBefore: "2001-01-01",
+			NotAfter:  "2002-01-01",
+		},
+		IsCertificateAuthority: isCA,
+	}
+	if isCA {
+		request.Usage = tls.KeyUsage{DigitalSignature: true, CertSign: true}
+	} else {
+		request.Usage = tls.KeyUsage{DigitalSignature: true, ServerAuth: true}
+		request.AlternateNames = []tls.AlternateName{{Type: tls.AlternateNameTypeDNS, Value: commonName}}
+	}
+	return request
+}
+
+func TestGenerateCertificatesChain(t *testing.T) {
+	t.Parallel()
+
+	root := testRequest("Root", true)
+	root.Label = "root"
+	intermediate := testRequest("Intermediate", true)
+	intermediate.Label = "intermediate"
+	intermediate.IssuerLabel = "root"
+	leaf := testRequest("leaf.example.com", false)
+	leaf.IssuerLabel = "intermediate"
+
+	// Issuers intentionally listed after the requests they sign
+	certificates, err := certbox.GenerateCertificates(certbox.GenerateCertificatesParameters{
+		Requests: []tls.CertificateRequest{leaf, intermediate, root},
+	})
+	if err != nil {
+		t.Fatalf("Error generating certificates: %s", err.Error())
+	}
+	if len(certificates) != 3 {
+		t.Fatalf("Unexpected number of certificates. Expected 3 got %d", len(certificates))
+	}
+
+	rootCert := certificates[0].X509()
+	intermediateCert := certificates[1].X509()
+	leafCert := certificates[2].X509()
+
+	if !intermediateCert.IsCA || !certificates[1].CertificateAuthority {
+		t.Errorf("Intermediate certificate is not a certificate authority")
+	}
+
+	roots := x509.NewCertPool()
+	roots.AddCert(rootCert)
+	intermediates := x509.NewCertPool()
+	intermediates.AddCert(intermediateCert)
+	if _, err := leafCert.Verify(x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediates,
+		DNSName:       "leaf.example.com",
+		CurrentTime:   time.Date(2001, 6, 1, 0, 0, 0, 0, time.UTC),
+	}); err != nil {
+		t.Errorf("Error verifying generated chain: %s", err.Error())
+	}
+}
+
+func TestGenerateCertificatesInvalidIssuers(t *testing.T) {
+	t.Parallel()
+
+	a := testRequest("A", true)
+	a.Label = "a"
+	a.IssuerLabel = "b"
+	b := testRequest("B", true)
+	b.Label = "b"
+	b.IssuerLabel = "a"
+	if _, err := certbox.GenerateCertificates(certbox.GenerateCertificatesParameters{
+		Requests: []tls.CertificateRequest{a, b},
+	}); err == nil {
+		t.Errorf("No error seen when one expected for issuer cycle")
+	}
+
+	leaf := testRequest("leaf.example.com", false)
+	leaf.IssuerLabel = "missing"
+	if _, err := certbox.GenerateCertificates(certbox.GenerateCertificatesParameters{
+		Requests: []tls.CertificateRequest{testRequest("Root", true), leaf},
+	}); err == nil {
+		t.Errorf("No error seen when one expected for missing issuer")
+	}
+
+	notCA := testRequest("notca.example.com", false)
+	notCA.Label = "notca"
+	leaf.IssuerLabel = "notca"
+	if _, err := certbox.GenerateCertificates(certbox.GenerateCertificatesParameters{
+		Requests: []tls.CertificateRequest{testRequest("Root", true), notCA, leaf},
+	}); err == nil {
+		t.Errorf("No error seen when one expected for issuer that is not a certificate authority")
+	}
+
+	duplicate := testRequest("Duplicate", true)
+	duplicate.Label = "a"
+	if _, err := certbox.GenerateCertificates(certbox.GenerateCertificatesParameters{
+		Requests: []tls.CertificateRequest{duplicate, duplicate},
+	}); err == nil {
+		t.Errorf("No error seen when one expected for duplicate labels")
+	}
+
+	importedRoot, err := tls.GenerateCertificate(testRequest("Imported Root", true), nil)
+	if err != nil {
+		t.Fatalf("Error generating certificate: %s", err.Error())
+	}
+	labeledRoot := testRequest("Root", true)
+	labeledRoot.Label = "root"
+	if _, err := certbox.GenerateCertificates(certbox.GenerateCertificatesParameters{
+		Requests:     []tls.CertificateRequest{labeledRoot, testRequest("leaf.example.com", false)},
+		ImportedRoot: importedRoot,
+	}); err == nil {
+		t.Errorf("No error seen when one expected for labeled root with an imported root")
+	}
+}
+
+func TestGenerateCertificatesConstraints(t *testing.T) {
+	t.Parallel()
+
+	root := testRequest("Root", true)
+	root.Label = "root"
+	root.MaxPathLen = 1
+	root.NameConstraints = tls.NameConstraints{
+		Critical:            true,
+		PermittedDNSDomains: []string{"example.com"},
+	}
+	intermediate := testRequest("Intermediate", true)
+	intermediate.Label = "intermediate"
+	intermediate.IssuerLabel = "root"
+	leaf := testRequest("leaf.example.com", false)
+	leaf.IssuerLabel = "intermediate"
+
+	if _, err := certbox.GenerateCertificates(certbox.GenerateCertificatesParameters{
+		Requests: []tls.CertificateRequest{root, intermediate, leaf},
+	}); err != nil {
+		t.Fatalf("Error generating certificates: %s", err.Error())
+	}
+
+	// The root constraints apply to all certificates below it, not just those it signs directly
+	otherLeaf := testRequest("leaf.example.net", false)
+	otherLeaf.IssuerLabel = "intermediate"
+	if _, err := certbox.GenerateCertificates(certbox.GenerateCertificatesParameters{
+		Requests: []tls.CertificateRequest{root, intermediate, otherLeaf},
+	}); err == nil {
+		t.Errorf("No error seen when one expected for leaf outside of root name constraints")
+	}
+
+	subIntermediate := testRequest("Sub Intermediate", true)
+	subIntermediate.IssuerLabel = "intermediate"
+	if _, err := certbox.GenerateCertificates(certbox.GenerateCertificatesParameters{
+		Requests: []tls.CertificateRequest{root, intermediate, subIntermediate},
+	}); err == nil {
+		t.Errorf("No error seen when one expected for intermediate exceeding root path length")
+	}
+}
+
+func TestGenerateCertificatesDeterministic(t *testing.T) {
+	t.Parallel()
+
+	root := testRequest("Root", true)
+	root.Label = "root"
+	leaf := testRequest("leaf.example.com", false)
+	leaf.IssuerLabel = "root"
+	parameters := certbox.GenerateCertificatesParameters{
+		Requests: []tls.CertificateRequest{root, leaf},
+	}
+
+	// Each run needs its own entropy, starting from the seed
+	deterministic := func() *tls.DeterministicEntropy {
+		entropy, err := tls.NewDeterministicEntropy("golden")
+		if err != nil {
+			t.Fatalf("Error creating deterministic entropy: %s", err.Error())
+		}
+		return entropy
+	}
+
+	first, err := certbox.GenerateCertificatesDeterministic(parameters, deterministic())
+	if err != nil {
+		t.Fatalf("Error generating certificates: %s", err.Error())
+	}
+	second, err := certbox.GenerateCertificatesDeterministic(parameters, deterministic())
+	if err != nil {
+		t.Fatalf("Error generating certificates: %s", err.Error())
+	}
+	for i := range first {
+		if first[i].CertificateData != second[i].CertificateData || first[i].KeyData != second[i].KeyData {
+			t.Errorf("Certificate %d is not reproducible", i)
+		}
+	}
+
+	exportParameters := certbox.ExportCertificatesParameters{
+		Certificates: first,
+		Format:       certbox.FormatP12,
+		Password:     "password",
+	}
+	exported, err := certbox.ExportCertificatesDeterministic(exportParameters, deterministic())
+	if err != nil {
+		t.Fatalf("Error exporting certificates: %s", err.Error())
+	}
+	exportedAgain, err := certbox.ExportCertificatesDeterministic(exportParameters, deterministic())
+	if err != nil {
+		t.Fatalf("Error exporting certificates: %s", err.Error())
+	}
+	for i := range exported {
+		if string(exported[i].Data) != string(exportedAgain[i].Data) {
+			t.Errorf("Exported file %s is not reproducible", exported[i].Name)
+		}
+	}
+}
+
+func TestGenerateCertificatesSequentialSerials(t *testing.T) {
+	t.Parallel()
+
+	sequential := tls.SerialNumber{Strategy: tls.SerialStrategySequential}
+	root := testRequest("Root", true)
+	root.Label = "root"
+	other := testRequest("Other Root", true)
+	other.Label = "other"
+	requests := []tls.CertificateRequest{root, other}
+	for i := 0; i < 3; i++ {
+		leaf := testRequest("leaf.example.com", false)
+		leaf.IssuerLabel = "root"
+		leaf.Serial = sequential
+		if i == 0 {
+			leaf.Serial.Value = "0x1000"
+		}
+		requests = append(requests, leaf)
+	}
+	otherLeaf := testRequest("other.example.com", false)
+	otherLeaf.IssuerLabel = "other"
+	otherLeaf.Serial = sequential
+	requests = append(requests, otherLeaf)
+
+	certificates, err := certbox.GenerateCertificates(certbox.GenerateCertificatesParameters{Requests: requests})
+	if err != nil {
+		t.Fatalf("Error generating certificates: %s", err.Error())
+	}
+
+	expected := []string{"1000", "1001", "1002", "01"}
+	for i, serial := range expected {
+		if certificates[i+2].SerialHex != serial {
+			t.Errorf("Unexpected serial number for certificate %d. Expected '%s' got '%s'", i+2, serial, certificates[i+2].SerialHex)
+		}
+	}
+}
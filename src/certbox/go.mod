@@ -2,6 +2,7 @@ module github.com/tls-inspector/certbox
 
 go 1.24.3
 
-require software.sslmate.com/src/go-pkcs12 v0.5.0
-
-require golang.org/x/crypto v0.38.0 // indirect
+require (
+	golang.org/x/crypto v0.38.0
+	software.sslmate.com/src/go-pkcs12 v0.5.0
+)
@@ -16,7 +16,7 @@ type ImportRootCertificateParameters struct {
 func ImportRootCertificate(parameters ImportRootCertificateParameters) (*tls.Certificate, error) {
 	certificate, err := tls.ImportP12(parameters.Data, parameters.Password)
 	if err != nil {
-		return nil, fmt.Errorf("error importing P12: " + err.Error())
+		return nil, fmt.Errorf("error importing P12: %s", err.Error())
 	}
 
 	return certificate, nil
@@ -31,9 +31,59 @@ type CloneCertificateParameters struct {
 func CloneCertificate(parameters CloneCertificateParameters) (*tls.CertificateRequest, error) {
 	certificate, err := tls.ImportPEMCertificate(parameters.Data)
 	if err != nil {
-		return nil, fmt.Errorf("error importing pem cert: " + err.Error())
+		return nil, fmt.Errorf("error importing pem cert: %s", err.Error())
 	}
 
-	request := certificate.Clone()
+	request, err := certificate.Clone()
+	if err != nil {
+		return nil, fmt.Errorf("error cloning certificate: %s", err.Error())
+	}
 	return &request, nil
 }
+
+// FaithfulCloneCertificateParameters parameters for faithfully cloning a certificate
+type FaithfulCloneCertificateParameters struct {
+	// Data is the PEM encoded certificate to clone
+	Data []byte
+	// Issuer signs the clone. If nil, the clone is signed by a fake issuer unless the certificate is self-signed.
+	Issuer  *tls.Certificate
+	Options tls.FaithfulCloneOptions
+}
+
+// FaithfulCloneCertificate will return a new certificate that is identical to the given PEM encoded certificate except
+// for its key and signature. The clone is followed by any fake issuers that were generated to sign it.
+func FaithfulCloneCertificate(parameters FaithfulCloneCertificateParameters) ([]*tls.Certificate, error) {
+	certificate, err := tls.ImportPEMCertificate(parameters.Data)
+	if err != nil {
+		return nil, fmt.Errorf("error importing pem cert: %s", err.Error())
+	}
+
+	clone, chain, err := tls.FaithfulClone(*certificate, parameters.Issuer, parameters.Options)
+	if err != nil {
+		return nil, err
+	}
+	return append([]*tls.Certificate{clone}, chain...), nil
+}
+
+// CloneCertificateChainParameters parameters for cloning a certificate chain
+type CloneCertificateChainParameters struct {
+	// Data is the PEM encoded certificate chain, ordered from the leaf to the root
+	Data    []byte
+	Options tls.FaithfulCloneOptions
+}
+
+// CloneCertificateChain will return a freshly keyed faithful clone of every certificate in the given PEM encoded chain,
+// where each clone is signed by the clone of its issuer. If the chain does not end with a self-signed root, the fake
+// root that signed the last clone is appended.
+func CloneCertificateChain(parameters CloneCertificateChainParameters) ([]*tls.Certificate, error) {
+	certificates, err := tls.ImportPEMCertificates(parameters.Data)
+	if err != nil {
+		return nil, fmt.Errorf("error importing pem certs: %s", err.Error())
+	}
+
+	chain := []tls.Certificate{}
+	for _, certificate := range certificates {
+		chain = append(chain, *certificate)
+	}
+	return tls.FaithfulCloneChain(chain, parameters.Options)
+}
@@ -0,0 +1,63 @@
+package certbox
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/tls-inspector/certbox/tls"
+)
+
+// InspectCertificateParameters parameters for inspecting a certificate
+type InspectCertificateParameters struct {
+	// Data is a PEM or DER encoded certificate. Ignored if Certificate is set.
+	Data        []byte
+	Certificate *tls.Certificate
+}
+
+// InspectCertificate will return a detailed breakdown of the given certificate
+func InspectCertificate(parameters InspectCertificateParameters) (*tls.CertificateDetails, error) {
+	certificate := parameters.Certificate
+	if certificate == nil {
+		var err error
+		certificate, err = importCertificate(parameters.Data)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return tls.InspectCertificate(*certificate)
+}
+
+// importCertificate imports the given PEM or DER encoded certificate
+func importCertificate(data []byte) (*tls.Certificate, error) {
+	if bytes.Contains(data, []byte("-----BEGIN")) {
+		certificate, err := tls.ImportPEMCertificate(data)
+		if err != nil {
+			return nil, fmt.Errorf("error importing pem cert: %s", err.Error())
+		}
+		return certificate, nil
+	}
+
+	certificate, err := tls.ImportDERCertificate(data)
+	if err != nil {
+		return nil, fmt.Errorf("error importing der cert: %s", err.Error())
+	}
+	return certificate, nil
+}
+
+// InspectTextParameters parameters for describing certificates, certificate requests and revocation lists as text
+type InspectTextParameters struct {
+	// Data contains PEM or DER encoded certificates, certificate requests or revocation lists. Ignored if Certificate
+	// is set.
+	Data        []byte
+	Certificate *tls.Certificate
+}
+
+// InspectText will return a human readable description of the given data, laid out like the text output of OpenSSL
+func InspectText(parameters InspectTextParameters) (string, error) {
+	if parameters.Certificate != nil {
+		return tls.CertificateText(*parameters.Certificate)
+	}
+
+	return tls.Text(parameters.Data)
+}
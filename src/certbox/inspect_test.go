@@ -0,0 +1,70 @@
+package certbox_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/tls-inspector/certbox"
+	"github.com/tls-inspector/certbox/tls"
+)
+
+func TestInspectCertificate(t *testing.T) {
+	t.Parallel()
+
+	certificate, err := tls.GenerateCertificate(testRequest("leaf.example.com", false), nil)
+	if err != nil {
+		t.Fatalf("Error generating certificate: %s", err.Error())
+	}
+	pemData, _, err := tls.ExportPEM(certificate)
+	if err != nil {
+		t.Fatalf("Error exporting certificate: %s", err.Error())
+	}
+	derData, _, err := tls.ExportDER(certificate)
+	if err != nil {
+		t.Fatalf("Error exporting certificate: %s", err.Error())
+	}
+
+	parameters := map[string]certbox.InspectCertificateParameters{
+		"pem":         {Data: pemData},
+		"der":         {Data: derData},
+		"certificate": {Certificate: certificate},
+	}
+	for name, parameter := range parameters {
+		details, err := certbox.InspectCertificate(parameter)
+		if err != nil {
+			t.Fatalf("Error inspecting %s certificate: %s", name, err.Error())
+		}
+		if details.SerialHex != certificate.SerialHex {
+			t.Errorf("Unexpected serial number for %s certificate '%s'", name, details.SerialHex)
+		}
+	}
+
+	if _, err := certbox.InspectCertificate(certbox.InspectCertificateParameters{Data: []byte("not a certificate")}); err == nil {
+		t.Errorf("No error seen when one expected for invalid certificate data")
+	}
+}
+
+func TestInspectText(t *testing.T) {
+	t.Parallel()
+
+	certificate, err := tls.GenerateCertificate(testRequest("leaf.example.com", false), nil)
+	if err != nil {
+		t.Fatalf("Error generating certificate: %s", err.Error())
+	}
+	pemData, _, err := tls.ExportPEM(certificate)
+	if err != nil {
+		t.Fatalf("Error exporting certificate: %s", err.Error())
+	}
+
+	pemText, err := certbox.InspectText(certbox.InspectTextParameters{Data: pemData})
+	if err != nil {
+		t.Fatalf("Error inspecting certificate: %s", err.Error())
+	}
+	certificateText, err := certbox.InspectText(certbox.InspectTextParameters{Certificate: certificate})
+	if err != nil {
+		t.Fatalf("Error inspecting certificate: %s", err.Error())
+	}
+	if pemText != certificateText || !strings.Contains(pemText, "Subject: C = CA, O = example.com, CN = leaf.example.com") {
+		t.Errorf("Unexpected certificate text:\n%s", pemText)
+	}
+}
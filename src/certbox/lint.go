@@ -0,0 +1,36 @@
+package certbox
+
+import (
+	"github.com/tls-inspector/certbox/lint"
+	"github.com/tls-inspector/certbox/tls"
+)
+
+// LintParameters parameters for linting a certificate or certificate request
+type LintParameters struct {
+	// Request is linted before generation if set, as if it were signed by Issuer. Issuer may be nil for a self-signed
+	// certificate.
+	Request *tls.CertificateRequest
+	Issuer  *tls.Certificate
+	// Data is a PEM or DER encoded certificate. Ignored if Request or Certificate is set.
+	Data        []byte
+	Certificate *tls.Certificate
+}
+
+// Lint will check the given certificate or certificate request against the CA/Browser Forum Baseline Requirements
+// and RFC 5280, returning every finding
+func Lint(parameters LintParameters) ([]lint.Finding, error) {
+	if parameters.Request != nil {
+		return lint.Request(*parameters.Request, parameters.Issuer)
+	}
+
+	certificate := parameters.Certificate
+	if certificate == nil {
+		var err error
+		certificate, err = importCertificate(parameters.Data)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return lint.Certificate(*certificate)
+}
@@ -0,0 +1,88 @@
+// Package lint checks certificates and certificate requests against the CA/Browser Forum Baseline Requirements and
+// RFC 5280
+package lint
+
+import (
+	"crypto/x509"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/tls-inspector/certbox/tls"
+)
+
+// Severity levels
+const (
+	// SeverityError is a violation of a requirement. The certificate would not be trusted or accepted.
+	SeverityError = "error"
+	// SeverityWarning is a violation of a recommendation, or a requirement of only some root programs
+	SeverityWarning = "warning"
+	// SeverityNotice is informational, such as a requirement that will take effect in the future
+	SeverityNotice = "notice"
+)
+
+// Rule sources
+const (
+	SourceCABF    = "CA/Browser Forum Baseline Requirements"
+	SourceRFC5280 = "RFC 5280"
+)
+
+// Rule describes a single lint rule
+type Rule struct {
+	ID          string
+	Severity    string
+	Source      string
+	Description string
+	// check returns a message for every way the certificate does not comply with the rule
+	check func(x *x509.Certificate) []string
+}
+
+// Finding describes a certificate not complying with a rule
+type Finding struct {
+	RuleID   string
+	Severity string
+	Source   string
+	Message  string
+}
+
+// Rules returns every lint rule
+func Rules() []Rule {
+	return append([]Rule{}, rules...)
+}
+
+// Certificate will lint the given certificate and return every finding, in the order of Rules
+func Certificate(certificate tls.Certificate) ([]Finding, error) {
+	data, err := hex.DecodeString(certificate.CertificateData)
+	if err != nil {
+		return nil, fmt.Errorf("invalid certificate: %s", err.Error())
+	}
+	x, err := x509.ParseCertificate(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid certificate: %s", err.Error())
+	}
+	return lint(x), nil
+}
+
+// Request will lint the certificate that would be generated for the given request and issuer, before generating it.
+// Issuer may be nil for a self-signed certificate.
+func Request(request tls.CertificateRequest, issuer *tls.Certificate) ([]Finding, error) {
+	x, err := tls.PreviewCertificate(request, issuer)
+	if err != nil {
+		return nil, err
+	}
+	return lint(x), nil
+}
+
+func lint(x *x509.Certificate) []Finding {
+	findings := []Finding{}
+	for _, rule := range rules {
+		for _, message := range rule.check(x) {
+			findings = append(findings, Finding{
+				RuleID:   rule.ID,
+				Severity: rule.Severity,
+				Source:   rule.Source,
+				Message:  message,
+			})
+		}
+	}
+	return findings
+}
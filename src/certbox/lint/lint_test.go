@@ -0,0 +1,375 @@
+package lint_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/tls-inspector/certbox/lint"
+	"github.com/tls-inspector/certbox/tls"
+)
+
+func ruleIDs(findings []lint.Finding) map[string]lint.Finding {
+	ids := map[string]lint.Finding{}
+	for _, finding := range findings {
+		ids[finding.RuleID] = finding
+	}
+	return ids
+}
+
+func TestRules(t *testing.T) {
+	t.Parallel()
+
+	seen := map[string]bool{}
+	for _, rule := range lint.Rules() {
+		if seen[rule.ID] {
+			t.Errorf("Duplicate rule ID %s", rule.ID)
+		}
+		seen[rule.ID] = true
+
+		if rule.Severity != lint.SeverityError && rule.Severity != lint.SeverityWarning && rule.Severity != lint.SeverityNotice {
+			t.Errorf("Unexpected severity for rule %s: '%s'", rule.ID, rule.Severity)
+		}
+		if rule.Source != lint.SourceCABF && rule.Source != lint.SourceRFC5280 {
+			t.Errorf("Unexpected source for rule %s: '%s'", rule.ID, rule.Source)
+		}
+		if rule.Description == "" {
+			t.Errorf("No description for rule %s", rule.ID)
+		}
+	}
+}
+
+func TestRequestCompliant(t *testing.T) {
+	t.Parallel()
+
+	rootRequest := tls.CertificateRequest{
+		KeyType:            tls.KeyTypeECDSA_256,
+		SignatureAlgorithm: tls.SignatureAlgorithmSHA256,
+		Subject:            tls.Name{Organization: "example.com", CommonName: "example.com Root"},
+		Validity: tls.DateRange{
+			NotBefore: "2026-01-01",
+			NotAfter:  "2036-01-01",
+		},
+		Usage:                  tls.KeyUsage{DigitalSignature: true, CertSign: true, CRLSign: true},
+		IsCertificateAuthority: true,
+	}
+	leafRequest := tls.CertificateRequest{
+		KeyType:            tls.KeyTypeECDSA_256,
+		SignatureAlgorithm: tls.SignatureAlgorithmSHA256,
+		Subject:            tls.Name{Organization: "example.com", CommonName: "foo.example.com"},
+		AlternateNames:     []tls.AlternateName{{Type: tls.AlternateNameTypeDNS, Value: "foo.example.com"}},
+		Validity: tls.DateRange{
+			NotBefore: "2026-06-01",
+			NotAfter:  "2026-08-01",
+		},
+		Usage:           tls.KeyUsage{DigitalSignature: true, ServerAuth: true},
+		StatusProviders: tls.StatusProviders{CRL: []string{"http://crl.example.com/root.crl"}},
+	}
+
+	root, err := tls.GenerateCertificate(rootRequest, nil)
+	if err != nil {
+		t.Fatalf("Error generating certificate: %s", err.Error())
+	}
+
+	findings, err := lint.Request(rootRequest, nil)
+	if err != nil {
+		t.Fatalf("Error linting request: %s", err.Error())
+	}
+	if len(findings) > 0 {
+		t.Errorf("Unexpected findings for root request: %+v", findings)
+	}
+
+	findings, err = lint.Request(leafRequest, root)
+	if err != nil {
+		t.Fatalf("Error linting request: %s", err.Error())
+	}
+	if len(findings) > 0 {
+		t.Errorf("Unexpected findings for leaf request: %+v", findings)
+	}
+
+	leaf, err := tls.GenerateCertificate(leafRequest, root)
+	if err != nil {
+		t.Fatalf("Error generating certificate: %s", err.Error())
+	}
+	for _, certificate := range []*tls.Certificate{root, leaf} {
+		findings, err := lint.Certificate(*certificate)
+		if err != nil {
+			t.Fatalf("Error linting certificate: %s", err.Error())
+		}
+		if len(findings) > 0 {
+			t.Errorf("Unexpected findings for certificate %s: %+v", certificate.Subject.CommonName, findings)
+		}
+	}
+}
+
+func TestRequestFindings(t *testing.T) {
+	t.Parallel()
+
+	root, err := tls.GenerateCertificate(tls.CertificateRequest{
+		KeyType:            tls.KeyTypeECDSA_256,
+		SignatureAlgorithm: tls.SignatureAlgorithmSHA256,
+		Subject:            tls.Name{Organization: "example.com", CommonName: "example.com Root"},
+		Validity: tls.DateRange{
+			NotBefore: "2026-01-01",
+			NotAfter:  "2036-01-01",
+		},
+		Usage:                  tls.KeyUsage{DigitalSignature: true, CertSign: true, CRLSign: true},
+		IsCertificateAuthority: true,
+	}, nil)
+	if err != nil {
+		t.Fatalf("Error generating certificate: %s", err.Error())
+	}
+
+	request := tls.CertificateRequest{
+		KeyType:            tls.KeyTypeECDSA_256,
+		SignatureAlgorithm: tls.SignatureAlgorithmSHA256,
+		Subject:            tls.Name{Organization: "example.com", CommonName: "foo.example.com"},
+		Validity: tls.DateRange{
+			NotBefore: "2026-06-01",
+			NotAfter:  "2027-06-01",
+		},
+		Usage:  tls.KeyUsage{DigitalSignature: true, KeyEncipherment: true},
+		Serial: tls.SerialNumber{Strategy: tls.SerialStrategyExplicit, Value: "1"},
+	}
+
+	findings, err := lint.Request(request, root)
+	if err != nil {
+		t.Fatalf("Error linting request: %s", err.Error())
+	}
+	ids := ruleIDs(findings)
+	expected := map[string]string{
+		"cabf_validity_too_long":             lint.SeverityError,
+		"cabf_subscriber_missing_san":        lint.SeverityError,
+		"cabf_cn_not_in_san":                 lint.SeverityError,
+		"cabf_serial_too_short":              lint.SeverityError,
+		"rfc5280_key_usage_invalid_for_key":  lint.SeverityError,
+		"cabf_subscriber_missing_eku":        lint.SeverityError,
+		"cabf_subscriber_missing_revocation": lint.SeverityWarning,
+	}
+	for id, severity := range expected {
+		finding, ok := ids[id]
+		if !ok {
+			t.Errorf("No %s finding seen. Findings: %+v", id, findings)
+			continue
+		}
+		if finding.Severity != severity {
+			t.Errorf("Unexpected severity for %s. Expected '%s' got '%s'", id, severity, finding.Severity)
+		}
+	}
+	if len(ids) != len(expected) {
+		t.Errorf("Unexpected findings: %+v", findings)
+	}
+	if message := ids["cabf_validity_too_long"].Message; message != "validity period of 365 days exceeds the maximum of 200 days for certificates issued from 2026-03-15" {
+		t.Errorf("Unexpected validity message '%s'", message)
+	}
+}
+
+func TestRequestUpcomingValidityLimit(t *testing.T) {
+	t.Parallel()
+
+	root, err := tls.GenerateCertificate(tls.CertificateRequest{
+		KeyType:            tls.KeyTypeECDSA_256,
+		SignatureAlgorithm: tls.SignatureAlgorithmSHA256,
+		Subject:            tls.Name{Organization: "example.com", CommonName: "example.com Root"},
+		Validity: tls.DateRange{
+			NotBefore: "2026-01-01",
+			NotAfter:  "2036-01-01",
+		},
+		Usage:                  tls.KeyUsage{DigitalSignature: true, CertSign: true, CRLSign: true},
+		IsCertificateAuthority: true,
+	}, nil)
+	if err != nil {
+		t.Fatalf("Error generating certificate: %s", err.Error())
+	}
+
+	request := tls.CertificateRequest{
+		KeyType:            tls.KeyTypeECDSA_256,
+		SignatureAlgorithm: tls.SignatureAlgorithmSHA256,
+		Subject:            tls.Name{Organization: "example.com", CommonName: "foo.example.com"},
+		AlternateNames:     []tls.AlternateName{{Type: tls.AlternateNameTypeDNS, Value: "foo.example.com"}},
+		Validity: tls.DateRange{
+			NotBefore: "2026-01-01",
+			NotAfter:  "2026-09-01",
+		},
+		Usage:           tls.KeyUsage{DigitalSignature: true, ServerAuth: true},
+		StatusProviders: tls.StatusProviders{CRL: []string{"http://crl.example.com/root.crl"}},
+	}
+	findings, err := lint.Request(request, root)
+	if err != nil {
+		t.Fatalf("Error linting request: %s", err.Error())
+	}
+	if len(findings) != 1 || findings[0].RuleID != "cabf_validity_upcoming_limit" || findings[0].Severity != lint.SeverityNotice {
+		t.Errorf("Unexpected findings: %+v", findings)
+	}
+}
+
+func TestRequestSerialLength(t *testing.T) {
+	t.Parallel()
+
+	root, err := tls.GenerateCertificate(tls.CertificateRequest{
+		KeyType:            tls.KeyTypeECDSA_256,
+		SignatureAlgorithm: tls.SignatureAlgorithmSHA256,
+		Subject:            tls.Name{Organization: "example.com", CommonName: "example.com Root"},
+		Validity: tls.DateRange{
+			NotBefore: "2026-01-01",
+			NotAfter:  "2036-01-01",
+		},
+		Usage:                  tls.KeyUsage{DigitalSignature: true, CertSign: true, CRLSign: true},
+		IsCertificateAuthority: true,
+	}, nil)
+	if err != nil {
+		t.Fatalf("Error generating certificate: %s", err.Error())
+	}
+
+	// A 64 bit random serial number with a leading zero bit is still 8 octets long
+	serials := map[string]bool{
+		"0x7fffffffffffffff": false,
+		"0x0100000000000000": false,
+		"0x007fffffffffffff": true,
+	}
+	for serial, tooShort := range serials {
+		request := tls.CertificateRequest{
+			KeyType:            tls.KeyTypeECDSA_256,
+			SignatureAlgorithm: tls.SignatureAlgorithmSHA256,
+			Subject:            tls.Name{Organization: "example.com", CommonName: "foo.example.com"},
+			AlternateNames:     []tls.AlternateName{{Type: tls.AlternateNameTypeDNS, Value: "foo.example.com"}},
+			Validity: tls.DateRange{
+				NotBefore: "2026-06-01",
+				NotAfter:  "2026-08-01",
+			},
+			Usage:           tls.KeyUsage{DigitalSignature: true, ServerAuth: true},
+			StatusProviders: tls.StatusProviders{CRL: []string{"http://crl.example.com/root.crl"}},
+			Serial:          tls.SerialNumber{Strategy: tls.SerialStrategyExplicit, Value: serial},
+		}
+		findings, err := lint.Request(request, root)
+		if err != nil {
+			t.Fatalf("Error linting request: %s", err.Error())
+		}
+		if _, ok := ruleIDs(findings)["cabf_serial_too_short"]; ok != tooShort {
+			t.Errorf("Unexpected cabf_serial_too_short finding for serial number %s: %+v", serial, findings)
+		}
+	}
+}
+
+func TestRequestCertificateAuthority(t *testing.T) {
+	t.Parallel()
+
+	request := tls.CertificateRequest{
+		KeyType:            tls.KeyTypeEd25519,
+		SignatureAlgorithm: tls.SignatureAlgorithmSHA256,
+		Subject:            tls.Name{Organization: "example.com", CommonName: "example.com Root"},
+		Validity: tls.DateRange{
+			NotBefore: "2026-01-01",
+			NotAfter:  "2036-01-01",
+		},
+		Usage:                  tls.KeyUsage{DigitalSignature: true},
+		IsCertificateAuthority: true,
+		NameConstraints:        tls.NameConstraints{PermittedDNSDomains: []string{"example.com"}},
+	}
+
+	findings, err := lint.Request(request, nil)
+	if err != nil {
+		t.Fatalf("Error linting request: %s", err.Error())
+	}
+	ids := ruleIDs(findings)
+	for _, id := range []string{"rfc5280_ca_missing_cert_sign", "rfc5280_name_constraints_not_critical", "cabf_key_algorithm", "cabf_signature_algorithm"} {
+		if _, ok := ids[id]; !ok {
+			t.Errorf("No %s finding seen. Findings: %+v", id, findings)
+		}
+	}
+	if severity := ids["rfc5280_name_constraints_not_critical"].Severity; severity != lint.SeverityError {
+		t.Errorf("Unexpected severity for rfc5280_name_constraints_not_critical '%s'", severity)
+	}
+	if _, ok := ids["cabf_validity_too_long"]; ok {
+		t.Errorf("Validity should not be checked for certificate authorities")
+	}
+}
+
+func TestCertificateMissingAuthorityKeyID(t *testing.T) {
+	t.Parallel()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Error generating key: %s", err.Error())
+	}
+	// The issuer has no subject key identifier, so the certificate has no authority key identifier
+	issuer := &x509.Certificate{
+		Subject:   pkix.Name{CommonName: "example.com Root"},
+		PublicKey: key.Public(),
+	}
+	template := &x509.Certificate{
+		SerialNumber: new(big.Int).Lsh(big.NewInt(1), 100),
+		Subject:      pkix.Name{CommonName: "foo.example.com"},
+		DNSNames:     []string{"foo.example.com", "bad_name.example.com", "*.example.com", "foo.*.example.com"},
+		NotBefore:    time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC),
+		NotAfter:     time.Date(2026, 8, 1, 0, 0, 0, 0, time.UTC),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		OCSPServer:   []string{"http://ocsp.example.com"},
+	}
+	data, err := x509.CreateCertificate(rand.Reader, template, issuer, key.Public(), key)
+	if err != nil {
+		t.Fatalf("Error generating certificate: %s", err.Error())
+	}
+	certificate, err := tls.ImportDERCertificate(data)
+	if err != nil {
+		t.Fatalf("Error importing certificate: %s", err.Error())
+	}
+
+	findings, err := lint.Certificate(*certificate)
+	if err != nil {
+		t.Fatalf("Error linting certificate: %s", err.Error())
+	}
+	ids := ruleIDs(findings)
+	if _, ok := ids["rfc5280_missing_aki"]; !ok {
+		t.Errorf("No rfc5280_missing_aki finding seen. Findings: %+v", findings)
+	}
+	dnsFindings := []string{}
+	for _, finding := range findings {
+		if finding.RuleID == "cabf_dns_name_invalid" {
+			dnsFindings = append(dnsFindings, finding.Message)
+		}
+	}
+	if len(dnsFindings) != 2 || dnsFindings[0] != "dns name bad_name.example.com contains an underscore" || dnsFindings[1] != "dns name foo.*.example.com has an invalid wildcard" {
+		t.Errorf("Unexpected dns name findings: %+v", dnsFindings)
+	}
+}
+
+func TestLintInvalid(t *testing.T) {
+	t.Parallel()
+
+	if _, err := lint.Certificate(tls.Certificate{CertificateData: "invalid"}); err == nil {
+		t.Errorf("No error seen when one expected for invalid certificate")
+	}
+
+	request := tls.CertificateRequest{
+		KeyType:            "invalid",
+		SignatureAlgorithm: tls.SignatureAlgorithmSHA256,
+		Subject:            tls.Name{CommonName: "foo.example.com"},
+		Validity: tls.DateRange{
+			NotBefore: "2026-06-01",
+			NotAfter:  "2026-08-01",
+		},
+	}
+	if _, err := lint.Request(request, nil); err == nil {
+		t.Errorf("No error seen when one expected for invalid key type")
+	}
+
+	request = tls.CertificateRequest{
+		KeyType:            tls.KeyTypeECDSA_256,
+		SignatureAlgorithm: tls.SignatureAlgorithmSHA256,
+		Subject:            tls.Name{CommonName: "foo.example.com"},
+		Validity: tls.DateRange{
+			NotBefore: "2026-08-01",
+			NotAfter:  "2026-06-01",
+		},
+	}
+	if _, err := lint.Request(request, nil); err == nil {
+		t.Errorf("No error seen when one expected for invalid validity")
+	}
+}
@@ -0,0 +1,517 @@
+package lint
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/asn1"
+	"fmt"
+	"net"
+	"slices"
+	"strings"
+	"time"
+)
+
+var rules = []Rule{
+	{
+		ID:          "rfc5280_serial_not_positive",
+		Severity:    SeverityError,
+		Source:      SourceRFC5280,
+		Description: "The serial number must be a positive integer",
+		check: func(x *x509.Certificate) []string {
+			if x.SerialNumber.Sign() <= 0 {
+				return []string{fmt.Sprintf("serial number %s is not positive", x.SerialNumber.String())}
+			}
+			return nil
+		},
+	},
+	{
+		ID:          "rfc5280_serial_too_long",
+		Severity:    SeverityError,
+		Source:      SourceRFC5280,
+		Description: "The serial number must not be longer than 20 octets",
+		check: func(x *x509.Certificate) []string {
+			if length := serialLength(x); length > 20 {
+				return []string{fmt.Sprintf("serial number is %d octets long", length)}
+			}
+			return nil
+		},
+	},
+	{
+		ID:          "cabf_serial_too_short",
+		Severity:    SeverityError,
+		Source:      SourceCABF,
+		Description: "The serial number must contain at least 64 bits of output from a CSPRNG",
+		check: func(x *x509.Certificate) []string {
+			// 64 random bits may have leading zeros, so only the encoded length can be checked
+			if length := serialLength(x); x.SerialNumber.Sign() > 0 && length < 8 {
+				return []string{fmt.Sprintf("serial number is only %d octets long", length)}
+			}
+			return nil
+		},
+	},
+	{
+		ID:          "rfc5280_extensions_require_v3",
+		Severity:    SeverityError,
+		Source:      SourceRFC5280,
+		Description: "Certificates with extensions must be version 3",
+		check: func(x *x509.Certificate) []string {
+			if x.Version != 3 && len(x.Extensions) > 0 {
+				return []string{fmt.Sprintf("version %d certificate has extensions", x.Version)}
+			}
+			return nil
+		},
+	},
+	{
+		ID:          "cabf_validity_too_long",
+		Severity:    SeverityError,
+		Source:      SourceCABF,
+		Description: "The validity period of subscriber certificates must not exceed the maximum for their issue date",
+		check: func(x *x509.Certificate) []string {
+			if !isSubscriberServer(x) {
+				return nil
+			}
+			limit, _ := validityLimits(x.NotBefore)
+			if limit == nil || validityDays(x) <= float64(limit.days) {
+				return nil
+			}
+			return []string{fmt.Sprintf("validity period of %s days exceeds the maximum of %d days for certificates issued from %s", formatDays(validityDays(x)), limit.days, limit.from.Format(time.DateOnly))}
+		},
+	},
+	{
+		ID:          "cabf_validity_upcoming_limit",
+		Severity:    SeverityNotice,
+		Source:      SourceCABF,
+		Description: "The validity period of subscriber certificates exceeds the next scheduled reduction of the maximum",
+		check: func(x *x509.Certificate) []string {
+			if !isSubscriberServer(x) {
+				return nil
+			}
+			limit, next := validityLimits(x.NotBefore)
+			if next == nil || validityDays(x) <= float64(next.days) || (limit != nil && validityDays(x) > float64(limit.days)) {
+				return nil
+			}
+			return []string{fmt.Sprintf("validity period of %s days exceeds the maximum of %d days for certificates issued from %s", formatDays(validityDays(x)), next.days, next.from.Format(time.DateOnly))}
+		},
+	},
+	{
+		ID:          "cabf_subscriber_missing_san",
+		Severity:    SeverityError,
+		Source:      SourceCABF,
+		Description: "Subscriber certificates must include a DNS name or IP address in the subject alternative names",
+		check: func(x *x509.Certificate) []string {
+			if isSubscriberServer(x) && len(x.DNSNames) == 0 && len(x.IPAddresses) == 0 {
+				return []string{"certificate has no dns name or ip address alternate names"}
+			}
+			return nil
+		},
+	},
+	{
+		ID:          "cabf_cn_not_in_san",
+		Severity:    SeverityError,
+		Source:      SourceCABF,
+		Description: "The common name of subscriber certificates must be one of the subject alternative names",
+		check: func(x *x509.Certificate) []string {
+			if !isSubscriberServer(x) {
+				return nil
+			}
+			messages := []string{}
+			for _, attribute := range x.Subject.Names {
+				value, ok := attribute.Value.(string)
+				if !attribute.Type.Equal(oidCommonName) || !ok {
+					continue
+				}
+				if !hasAlternateName(x, value) {
+					messages = append(messages, fmt.Sprintf("common name %s is not an alternate name", value))
+				}
+			}
+			return messages
+		},
+	},
+	{
+		ID:          "cabf_dns_name_invalid",
+		Severity:    SeverityError,
+		Source:      SourceCABF,
+		Description: "DNS names must be valid host names, optionally with a wildcard as the first label",
+		check: func(x *x509.Certificate) []string {
+			messages := []string{}
+			for _, name := range x.DNSNames {
+				if problem := dnsNameProblem(name); problem != "" {
+					messages = append(messages, fmt.Sprintf("dns name %s %s", name, problem))
+				}
+			}
+			return messages
+		},
+	},
+	{
+		ID:          "rfc5280_empty_subject",
+		Severity:    SeverityError,
+		Source:      SourceRFC5280,
+		Description: "Certificates with an empty subject must include a critical subject alternative names extension",
+		check: func(x *x509.Certificate) []string {
+			if !bytes.Equal(x.RawSubject, emptySequence) && len(x.RawSubject) > 0 {
+				return nil
+			}
+			for _, extension := range x.Extensions {
+				if extension.Id.Equal(oidExtensionSubjectAltName) {
+					if !extension.Critical {
+						return []string{"subject is empty and the subject alternative names extension is not critical"}
+					}
+					return nil
+				}
+			}
+			if !hasAnyAlternateName(x) {
+				return []string{"subject is empty and there are no subject alternative names"}
+			}
+			return nil
+		},
+	},
+	{
+		ID:          "rfc5280_missing_aki",
+		Severity:    SeverityError,
+		Source:      SourceRFC5280,
+		Description: "Certificates that are not self-issued must include an authority key identifier",
+		check: func(x *x509.Certificate) []string {
+			if !bytes.Equal(x.RawIssuer, x.RawSubject) && len(x.AuthorityKeyId) == 0 {
+				return []string{"certificate has no authority key identifier"}
+			}
+			return nil
+		},
+	},
+	{
+		ID:          "rfc5280_ca_missing_ski",
+		Severity:    SeverityError,
+		Source:      SourceRFC5280,
+		Description: "Certificate authorities must include a subject key identifier",
+		check: func(x *x509.Certificate) []string {
+			if x.IsCA && len(x.SubjectKeyId) == 0 {
+				return []string{"certificate authority has no subject key identifier"}
+			}
+			return nil
+		},
+	},
+	{
+		ID:          "rfc5280_ca_missing_cert_sign",
+		Severity:    SeverityError,
+		Source:      SourceRFC5280,
+		Description: "Certificate authorities must have the certificate signing key usage",
+		check: func(x *x509.Certificate) []string {
+			if x.IsCA && x.KeyUsage&x509.KeyUsageCertSign == 0 {
+				return []string{"certificate authority does not have the certificate signing key usage"}
+			}
+			return nil
+		},
+	},
+	{
+		ID:          "rfc5280_cert_sign_without_ca",
+		Severity:    SeverityError,
+		Source:      SourceRFC5280,
+		Description: "Only certificate authorities may have the certificate signing key usage",
+		check: func(x *x509.Certificate) []string {
+			if !x.IsCA && x.KeyUsage&x509.KeyUsageCertSign != 0 {
+				return []string{"certificate signing key usage on a certificate that is not a certificate authority"}
+			}
+			return nil
+		},
+	},
+	{
+		ID:          "rfc5280_key_usage_invalid_for_key",
+		Severity:    SeverityError,
+		Source:      SourceRFC5280,
+		Description: "Key usages must be compatible with the type of the public key",
+		check: func(x *x509.Certificate) []string {
+			var permitted x509.KeyUsage
+			switch x.PublicKey.(type) {
+			case *rsa.PublicKey:
+				permitted = x509.KeyUsageDigitalSignature | x509.KeyUsageContentCommitment | x509.KeyUsageKeyEncipherment | x509.KeyUsageDataEncipherment | x509.KeyUsageCertSign | x509.KeyUsageCRLSign
+			case *ecdsa.PublicKey:
+				permitted = x509.KeyUsageDigitalSignature | x509.KeyUsageContentCommitment | x509.KeyUsageKeyAgreement | x509.KeyUsageCertSign | x509.KeyUsageCRLSign | x509.KeyUsageEncipherOnly | x509.KeyUsageDecipherOnly
+			case ed25519.PublicKey:
+				permitted = x509.KeyUsageDigitalSignature | x509.KeyUsageContentCommitment | x509.KeyUsageCertSign | x509.KeyUsageCRLSign
+			default:
+				return nil
+			}
+
+			messages := []string{}
+			for _, usage := range keyUsageNames {
+				if x.KeyUsage&usage.usage != 0 && permitted&usage.usage == 0 {
+					messages = append(messages, fmt.Sprintf("key usage %s is not permitted for %s keys", usage.name, x.PublicKeyAlgorithm.String()))
+				}
+			}
+			return messages
+		},
+	},
+	{
+		ID:          "rfc5280_encipher_decipher_only_without_key_agreement",
+		Severity:    SeverityError,
+		Source:      SourceRFC5280,
+		Description: "The encipher only and decipher only key usages require the key agreement key usage",
+		check: func(x *x509.Certificate) []string {
+			if x.KeyUsage&(x509.KeyUsageEncipherOnly|x509.KeyUsageDecipherOnly) != 0 && x.KeyUsage&x509.KeyUsageKeyAgreement == 0 {
+				return []string{"encipher only or decipher only key usage without key agreement"}
+			}
+			return nil
+		},
+	},
+	{
+		ID:          "cabf_subscriber_missing_eku",
+		Severity:    SeverityError,
+		Source:      SourceCABF,
+		Description: "Subscriber certificates must include the extended key usage extension",
+		check: func(x *x509.Certificate) []string {
+			if isSubscriberServer(x) && len(x.ExtKeyUsage) == 0 && len(x.UnknownExtKeyUsage) == 0 {
+				return []string{"certificate has no extended key usages"}
+			}
+			return nil
+		},
+	},
+	{
+		ID:          "cabf_subscriber_any_eku",
+		Severity:    SeverityError,
+		Source:      SourceCABF,
+		Description: "Subscriber certificates must not include the any extended key usage",
+		check: func(x *x509.Certificate) []string {
+			if !x.IsCA && slices.Contains(x.ExtKeyUsage, x509.ExtKeyUsageAny) {
+				return []string{"certificate has the any extended key usage"}
+			}
+			return nil
+		},
+	},
+	{
+		ID:          "cabf_subscriber_missing_revocation",
+		Severity:    SeverityWarning,
+		Source:      SourceCABF,
+		Description: "Subscriber certificates should include a CRL distribution point or OCSP responder",
+		check: func(x *x509.Certificate) []string {
+			if isSubscriberServer(x) && len(x.CRLDistributionPoints) == 0 && len(x.OCSPServer) == 0 {
+				return []string{"certificate has no crl distribution point or ocsp responder"}
+			}
+			return nil
+		},
+	},
+	{
+		ID:          "rfc5280_name_constraints_not_critical",
+		Severity:    SeverityError,
+		Source:      SourceRFC5280,
+		Description: "The name constraints extension must be critical",
+		check: func(x *x509.Certificate) []string {
+			if hasNameConstraints(x) && !x.PermittedDNSDomainsCritical {
+				return []string{"name constraints extension is not critical"}
+			}
+			return nil
+		},
+	},
+	{
+		ID:          "cabf_key_algorithm",
+		Severity:    SeverityError,
+		Source:      SourceCABF,
+		Description: "The public key must be an RSA or ECDSA key",
+		check: func(x *x509.Certificate) []string {
+			switch x.PublicKey.(type) {
+			case *rsa.PublicKey, *ecdsa.PublicKey:
+				return nil
+			}
+			return []string{fmt.Sprintf("%s public keys are not permitted", x.PublicKeyAlgorithm.String())}
+		},
+	},
+	{
+		ID:          "cabf_rsa_key_size",
+		Severity:    SeverityError,
+		Source:      SourceCABF,
+		Description: "RSA moduli must be at least 2048 bits and a multiple of 8 bits",
+		check: func(x *x509.Certificate) []string {
+			pub, ok := x.PublicKey.(*rsa.PublicKey)
+			if !ok {
+				return nil
+			}
+			if bits := pub.N.BitLen(); bits < 2048 || bits%8 != 0 {
+				return []string{fmt.Sprintf("rsa modulus is %d bits", bits)}
+			}
+			return nil
+		},
+	},
+	{
+		ID:          "cabf_ecdsa_curve",
+		Severity:    SeverityError,
+		Source:      SourceCABF,
+		Description: "ECDSA keys must use the P-256, P-384 or P-521 curves",
+		check: func(x *x509.Certificate) []string {
+			pub, ok := x.PublicKey.(*ecdsa.PublicKey)
+			if !ok {
+				return nil
+			}
+			switch pub.Curve {
+			case elliptic.P256(), elliptic.P384(), elliptic.P521():
+				return nil
+			}
+			return []string{fmt.Sprintf("ecdsa curve %s is not permitted", pub.Curve.Params().Name)}
+		},
+	},
+	{
+		ID:          "cabf_ecdsa_p521",
+		Severity:    SeverityWarning,
+		Source:      SourceCABF,
+		Description: "ECDSA keys using the P-521 curve are not accepted by all root programs",
+		check: func(x *x509.Certificate) []string {
+			if pub, ok := x.PublicKey.(*ecdsa.PublicKey); ok && pub.Curve == elliptic.P521() {
+				return []string{"ecdsa curve P-521 is not accepted by all root programs"}
+			}
+			return nil
+		},
+	},
+	{
+		ID:          "cabf_signature_algorithm",
+		Severity:    SeverityError,
+		Source:      SourceCABF,
+		Description: "The signature algorithm must be RSA or ECDSA with SHA-256, SHA-384 or SHA-512",
+		check: func(x *x509.Certificate) []string {
+			switch x.SignatureAlgorithm {
+			case x509.SHA256WithRSA, x509.SHA384WithRSA, x509.SHA512WithRSA, x509.SHA256WithRSAPSS, x509.SHA384WithRSAPSS, x509.SHA512WithRSAPSS, x509.ECDSAWithSHA256, x509.ECDSAWithSHA384, x509.ECDSAWithSHA512:
+				return nil
+			}
+			return []string{fmt.Sprintf("signature algorithm %s is not permitted", x.SignatureAlgorithm.String())}
+		},
+	},
+}
+
+var (
+	oidCommonName              = asn1.ObjectIdentifier{2, 5, 4, 3}
+	oidExtensionSubjectAltName = asn1.ObjectIdentifier{2, 5, 29, 17}
+	emptySequence              = []byte{0x30, 0x00}
+)
+
+var keyUsageNames = []struct {
+	usage x509.KeyUsage
+	name  string
+}{
+	{x509.KeyUsageDigitalSignature, "digital signature"},
+	{x509.KeyUsageContentCommitment, "content commitment"},
+	{x509.KeyUsageKeyEncipherment, "key encipherment"},
+	{x509.KeyUsageDataEncipherment, "data encipherment"},
+	{x509.KeyUsageKeyAgreement, "key agreement"},
+	{x509.KeyUsageCertSign, "certificate signing"},
+	{x509.KeyUsageCRLSign, "crl signing"},
+	{x509.KeyUsageEncipherOnly, "encipher only"},
+	{x509.KeyUsageDecipherOnly, "decipher only"},
+}
+
+type validityLimit struct {
+	from time.Time
+	days int
+}
+
+// maximumValidity is the schedule of the maximum validity period of subscriber certificates, by issue date.
+// Certificates issued before the first date are not checked.
+var maximumValidity = []validityLimit{
+	{time.Date(2018, 3, 1, 0, 0, 0, 0, time.UTC), 825},
+	{time.Date(2020, 9, 1, 0, 0, 0, 0, time.UTC), 398},
+	{time.Date(2026, 3, 15, 0, 0, 0, 0, time.UTC), 200},
+	{time.Date(2027, 3, 15, 0, 0, 0, 0, time.UTC), 100},
+	{time.Date(2029, 3, 15, 0, 0, 0, 0, time.UTC), 47},
+}
+
+// validityLimits returns the maximum validity for certificates issued at notBefore, and the next scheduled maximum.
+// Either may be nil.
+func validityLimits(notBefore time.Time) (*validityLimit, *validityLimit) {
+	var limit *validityLimit
+	for i := range maximumValidity {
+		if notBefore.Before(maximumValidity[i].from) {
+			return limit, &maximumValidity[i]
+		}
+		limit = &maximumValidity[i]
+	}
+	return limit, nil
+}
+
+// validityDays returns the validity period of the certificate in days. The validity period includes both the
+// NotBefore and NotAfter seconds.
+func validityDays(x *x509.Certificate) float64 {
+	return (x.NotAfter.Sub(x.NotBefore) + time.Second).Hours() / 24
+}
+
+func formatDays(days float64) string {
+	return strings.TrimSuffix(strings.TrimRight(fmt.Sprintf("%.2f", days), "0"), ".")
+}
+
+// isSubscriberServer returns true if x is not a certificate authority and may be used for TLS servers, and is
+// therefore subject to the requirements for subscriber certificates
+func isSubscriberServer(x *x509.Certificate) bool {
+	if x.IsCA {
+		return false
+	}
+	if len(x.ExtKeyUsage) == 0 && len(x.UnknownExtKeyUsage) == 0 {
+		return true
+	}
+	return slices.Contains(x.ExtKeyUsage, x509.ExtKeyUsageServerAuth) || slices.Contains(x.ExtKeyUsage, x509.ExtKeyUsageAny)
+}
+
+// serialLength returns the length in octets of the DER encoded serial number
+func serialLength(x *x509.Certificate) int {
+	data, err := asn1.Marshal(x.SerialNumber)
+	if err != nil {
+		return 0
+	}
+	return len(data) - 2
+}
+
+// hasAlternateName returns true if value is one of the DNS name or IP address alternate names of x
+func hasAlternateName(x *x509.Certificate, value string) bool {
+	if ip := net.ParseIP(value); ip != nil {
+		return slices.ContainsFunc(x.IPAddresses, ip.Equal)
+	}
+	return slices.ContainsFunc(x.DNSNames, func(name string) bool {
+		return strings.EqualFold(name, value)
+	})
+}
+
+func hasAnyAlternateName(x *x509.Certificate) bool {
+	return len(x.DNSNames) > 0 || len(x.EmailAddresses) > 0 || len(x.IPAddresses) > 0 || len(x.URIs) > 0
+}
+
+func hasNameConstraints(x *x509.Certificate) bool {
+	return len(x.PermittedDNSDomains) > 0 || len(x.ExcludedDNSDomains) > 0 ||
+		len(x.PermittedEmailAddresses) > 0 || len(x.ExcludedEmailAddresses) > 0 ||
+		len(x.PermittedIPRanges) > 0 || len(x.ExcludedIPRanges) > 0 ||
+		len(x.PermittedURIDomains) > 0 || len(x.ExcludedURIDomains) > 0
+}
+
+// dnsNameProblem describes why name is not a valid DNS name, or returns an empty string if it is valid
+func dnsNameProblem(name string) string {
+	if net.ParseIP(name) != nil {
+		return "is an ip address"
+	}
+	if len(name) > 253 {
+		return "is longer than 253 characters"
+	}
+
+	labels := strings.Split(name, ".")
+	for i, label := range labels {
+		if label == "" {
+			return "has an empty label"
+		}
+		if label == "*" && i == 0 && len(labels) > 2 {
+			continue
+		}
+		if strings.Contains(label, "*") {
+			return "has an invalid wildcard"
+		}
+		if len(label) > 63 {
+			return "has a label longer than 63 characters"
+		}
+		if strings.HasPrefix(label, "-") || strings.HasSuffix(label, "-") {
+			return "has a label that begins or ends with a hyphen"
+		}
+		for _, c := range label {
+			if c == '_' {
+				return "contains an underscore"
+			}
+			if !(c >= 'a' && c <= 'z') && !(c >= 'A' && c <= 'Z') && !(c >= '0' && c <= '9') && c != '-' {
+				return fmt.Sprintf("contains the invalid character %q", c)
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,295 @@
+// Package ocsp provides an OCSP responder backed by a certbox certificate authority
+package ocsp
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tls-inspector/certbox/tls"
+	"golang.org/x/crypto/ocsp"
+)
+
+// Certificate status values
+const (
+	StatusGood    = "good"
+	StatusRevoked = "revoked"
+	StatusUnknown = "unknown"
+)
+
+// Status describes the status of a single certificate
+type Status struct {
+	// Serial is the decimal serial number of the certificate, or hexadecimal if prefixed with 0x
+	Serial string
+	Status string
+	// RevocationDate and ReasonCode are only used for revoked certificates. RevocationDate can be in any of the formats
+	// supported by tls.DateRange.
+	RevocationDate string
+	ReasonCode     int
+}
+
+// Database describes a certificate status database keyed by serial number. It is safe for concurrent use.
+type Database struct {
+	lock     sync.RWMutex
+	statuses map[string]Status
+}
+
+// NewDatabase will create a new status database with the given statuses
+func NewDatabase(statuses []Status) (*Database, error) {
+	database := &Database{
+		statuses: map[string]Status{},
+	}
+	for _, status := range statuses {
+		if err := database.Set(status); err != nil {
+			return nil, err
+		}
+	}
+	return database, nil
+}
+
+// Set will add or replace the status for a certificate
+func (d *Database) Set(status Status) error {
+	serial, err := tls.ParseSerial(status.Serial)
+	if err != nil {
+		return err
+	}
+
+	switch status.Status {
+	case StatusGood, StatusUnknown:
+		break
+	case StatusRevoked:
+		// Relative dates are resolved now, so the revocation date doesn't change between responses
+		revocationDate, err := tls.ParseDate(status.RevocationDate)
+		if err != nil {
+			return fmt.Errorf("invalid revocation date for serial %s: %s", status.Serial, err.Error())
+		}
+		status.RevocationDate = revocationDate.Format(time.RFC3339)
+	default:
+		return fmt.Errorf("invalid status '%s' for serial %s", status.Status, status.Serial)
+	}
+
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	d.statuses[serial.String()] = status
+	return nil
+}
+
+// Get will return the status for the given serial number. Serials not in the database are unknown.
+func (d *Database) Get(serial *big.Int) Status {
+	d.lock.RLock()
+	defer d.lock.RUnlock()
+	status, ok := d.statuses[serial.String()]
+	if !ok {
+		return Status{Serial: serial.String(), Status: StatusUnknown}
+	}
+	return status
+}
+
+// Options describes the options for an OCSP responder
+type Options struct {
+	// Issuer is the certificate authority that issued the certificates being checked. Its private key is required
+	// unless a delegated responder is provided.
+	Issuer tls.Certificate
+	// Responder is an optional delegated responder certificate issued by Issuer with the OCSPSigning extended key
+	// usage. When provided, responses are signed with its key instead of the issuers.
+	Responder *tls.Certificate
+	Statuses  []Status
+	// Validity is the duration that responses are valid for, such as "24h". Defaults to 24 hours.
+	Validity string
+}
+
+// Responder describes an OCSP responder. Responder implements http.Handler and supports both GET and POST requests
+// as described in RFC 6960 appendix A. The handler can be mounted under a path prefix, such as
+// http.Handle("/ocsp/", responder).
+type Responder struct {
+	Database *Database
+
+	issuer        *x509.Certificate
+	responderCert *x509.Certificate
+	signer        crypto.Signer
+	validity      time.Duration
+}
+
+// NewResponder will create a new OCSP responder with the given options
+func NewResponder(options Options) (*Responder, error) {
+	database, err := NewDatabase(options.Statuses)
+	if err != nil {
+		return nil, err
+	}
+
+	issuer := options.Issuer.X509()
+	if !issuer.IsCA {
+		return nil, fmt.Errorf("issuer is not a certificate authority")
+	}
+
+	responder := &Responder{
+		Database:      database,
+		issuer:        issuer,
+		responderCert: issuer,
+		validity:      24 * time.Hour,
+	}
+
+	if options.Validity != "" {
+		validity, err := time.ParseDuration(options.Validity)
+		if err != nil {
+			return nil, fmt.Errorf("invalid validity: %s", err.Error())
+		}
+		if validity <= 0 {
+			return nil, fmt.Errorf("invalid validity: must be positive")
+		}
+		responder.validity = validity
+	}
+
+	if options.Responder != nil {
+		responderCert := options.Responder.X509()
+		if err := responderCert.CheckSignatureFrom(issuer); err != nil {
+			return nil, fmt.Errorf("responder certificate was not issued by the issuer: %s", err.Error())
+		}
+		hasOCSPSigning := false
+		for _, usage := range responderCert.ExtKeyUsage {
+			if usage == x509.ExtKeyUsageOCSPSigning {
+				hasOCSPSigning = true
+			}
+		}
+		if !hasOCSPSigning {
+			return nil, fmt.Errorf("responder certificate does not have the OCSP signing extended key usage")
+		}
+		if options.Responder.KeyData == "" {
+			return nil, fmt.Errorf("responder private key required")
+		}
+		responder.responderCert = responderCert
+		responder.signer = options.Responder.PKey().(crypto.Signer)
+	} else {
+		if options.Issuer.KeyData == "" {
+			return nil, fmt.Errorf("issuer private key required")
+		}
+		responder.signer = options.Issuer.PKey().(crypto.Signer)
+	}
+
+	return responder, nil
+}
+
+// Respond will return a signed DER encoded OCSP response for the given DER encoded OCSP request. Malformed requests
+// and requests for certificates from other issuers are answered with the appropriate error response.
+func (r *Responder) Respond(requestData []byte) ([]byte, error) {
+	request, err := ocsp.ParseRequest(requestData)
+	if err != nil {
+		return ocsp.MalformedRequestErrorResponse, nil
+	}
+
+	if !r.isIssuer(request) {
+		return ocsp.UnauthorizedErrorResponse, nil
+	}
+
+	status := r.Database.Get(request.SerialNumber)
+	now := time.Now().UTC().Truncate(time.Minute)
+	template := ocsp.Response{
+		SerialNumber: request.SerialNumber,
+		ThisUpdate:   now,
+		NextUpdate:   now.Add(r.validity),
+		IssuerHash:   request.HashAlgorithm,
+	}
+	switch status.Status {
+	case StatusGood:
+		template.Status = ocsp.Good
+	case StatusRevoked:
+		template.Status = ocsp.Revoked
+		// The revocation date was validated when the status was set
+		template.RevokedAt, _ = time.Parse(time.RFC3339, status.RevocationDate)
+		template.RevocationReason = status.ReasonCode
+	default:
+		template.Status = ocsp.Unknown
+	}
+	if r.responderCert != r.issuer {
+		template.Certificate = r.responderCert
+	}
+
+	return ocsp.CreateResponse(r.issuer, r.responderCert, template, r.signer)
+}
+
+func (r *Responder) isIssuer(request *ocsp.Request) bool {
+	if !request.HashAlgorithm.Available() {
+		return false
+	}
+
+	var publicKeyInfo struct {
+		Algorithm asn1.RawValue
+		PublicKey asn1.BitString
+	}
+	if _, err := asn1.Unmarshal(r.issuer.RawSubjectPublicKeyInfo, &publicKeyInfo); err != nil {
+		return false
+	}
+
+	h := request.HashAlgorithm.New()
+	h.Write(r.issuer.RawSubject)
+	nameHash := h.Sum(nil)
+
+	h.Reset()
+	h.Write(publicKeyInfo.PublicKey.RightAlign())
+	keyHash := h.Sum(nil)
+
+	return bytes.Equal(nameHash, request.IssuerNameHash) && bytes.Equal(keyHash, request.IssuerKeyHash)
+}
+
+// ServeHTTP will respond to the given OCSP HTTP request
+func (r *Responder) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	var requestData []byte
+	var err error
+
+	switch req.Method {
+	case http.MethodGet:
+		requestData, err = requestFromPath(req.URL.EscapedPath())
+		if err != nil {
+			http.Error(w, "invalid request", http.StatusBadRequest)
+			return
+		}
+	case http.MethodPost:
+		requestData, err = io.ReadAll(io.LimitReader(req.Body, 10240))
+		if err != nil {
+			http.Error(w, "invalid request", http.StatusBadRequest)
+			return
+		}
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	response, err := r.Respond(requestData)
+	if err != nil {
+		response = ocsp.InternalErrorErrorResponse
+	}
+
+	w.Header().Set("Content-Type", "application/ocsp-response")
+	w.Write(response)
+}
+
+// requestFromPath returns the OCSP request encoded at the end of the given escaped URL path. The responder may be
+// mounted under a prefix, and clients don't always escape the '/' characters of the base64 encoded request, so each
+// suffix of the path is tried until one decodes to an OCSP request.
+func requestFromPath(escapedPath string) ([]byte, error) {
+	segments := strings.Split(strings.TrimPrefix(escapedPath, "/"), "/")
+	for i := range segments {
+		encoded, err := url.PathUnescape(strings.Join(segments[i:], "/"))
+		if err != nil {
+			continue
+		}
+		data, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			continue
+		}
+		if _, err := ocsp.ParseRequest(data); err == nil {
+			return data, nil
+		}
+	}
+	return nil, fmt.Errorf("no ocsp request in path")
+}
@@ -0,0 +1,345 @@
+package ocsp_test
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	certboxocsp "github.com/tls-inspector/certbox/ocsp"
+	"github.com/tls-inspector/certbox/tls"
+	"golang.org/x/crypto/ocsp"
+)
+
+func query(t *testing.T, baseURL string, leaf *tls.Certificate, root *tls.Certificate, method string) *ocsp.Response {
+	request, err := ocsp.CreateRequest(leaf.X509(), root.X509(), nil)
+	if err != nil {
+		t.Fatalf("Error creating OCSP request: %s", err.Error())
+	}
+
+	var response *http.Response
+	if method == http.MethodGet {
+		response, err = http.Get(baseURL + "/" + url.PathEscape(base64.StdEncoding.EncodeToString(request)))
+	} else {
+		response, err = http.Post(baseURL, "application/ocsp-request", bytes.NewReader(request))
+	}
+	if err != nil {
+		t.Fatalf("Error making OCSP request: %s", err.Error())
+	}
+	defer response.Body.Close()
+	data, err := io.ReadAll(response.Body)
+	if err != nil {
+		t.Fatalf("Error reading OCSP response: %s", err.Error())
+	}
+
+	parsed, err := ocsp.ParseResponseForCert(data, leaf.X509(), root.X509())
+	if err != nil {
+		t.Fatalf("Error parsing OCSP response: %s", err.Error())
+	}
+	return parsed
+}
+
+func TestResponder(t *testing.T) {
+	t.Parallel()
+
+	root, err := tls.GenerateCertificate(tls.CertificateRequest{
+		KeyType:            tls.KeyTypeECDSA_256,
+		SignatureAlgorithm: tls.SignatureAlgorithmSHA256,
+		Subject:            tls.Name{CommonName: "Root"},
+		Validity: tls.DateRange{
+			NotBefore: "2001-01-01",
+			NotAfter:  "2099-01-01",
+		},
+		IsCertificateAuthority: true,
+	}, nil)
+	if err != nil {
+		t.Fatalf("Error generating certificate: %s", err.Error())
+	}
+
+	good, err := tls.GenerateCertificate(tls.CertificateRequest{
+		KeyType:            tls.KeyTypeECDSA_256,
+		SignatureAlgorithm: tls.SignatureAlgorithmSHA256,
+		Subject:            tls.Name{CommonName: "good.example.com"},
+		Validity: tls.DateRange{
+			NotBefore: "2001-01-01",
+			NotAfter:  "2099-01-01",
+		},
+	}, root)
+	if err != nil {
+		t.Fatalf("Error generating certificate: %s", err.Error())
+	}
+
+	revoked, err := tls.GenerateCertificate(tls.CertificateRequest{
+		KeyType:            tls.KeyTypeECDSA_256,
+		SignatureAlgorithm: tls.SignatureAlgorithmSHA256,
+		Subject:            tls.Name{CommonName: "revoked.example.com"},
+		Validity: tls.DateRange{
+			NotBefore: "2001-01-01",
+			NotAfter:  "2099-01-01",
+		},
+	}, root)
+	if err != nil {
+		t.Fatalf("Error generating certificate: %s", err.Error())
+	}
+
+	unknown, err := tls.GenerateCertificate(tls.CertificateRequest{
+		KeyType:            tls.KeyTypeECDSA_256,
+		SignatureAlgorithm: tls.SignatureAlgorithmSHA256,
+		Subject:            tls.Name{CommonName: "unknown.example.com"},
+		Validity: tls.DateRange{
+			NotBefore: "2001-01-01",
+			NotAfter:  "2099-01-01",
+		},
+	}, root)
+	if err != nil {
+		t.Fatalf("Error generating certificate: %s", err.Error())
+	}
+	revocationDate := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	responder, err := certboxocsp.NewResponder(certboxocsp.Options{
+		Issuer: *root,
+		Statuses: []certboxocsp.Status{
+			{Serial: good.Serial, Status: certboxocsp.StatusGood},
+			{Serial: revoked.Serial, Status: certboxocsp.StatusRevoked, RevocationDate: "2020-01-01", ReasonCode: tls.RevocationReasonKeyCompromise},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Error creating responder: %s", err.Error())
+	}
+	server := httptest.NewServer(responder)
+	defer server.Close()
+
+	if response := query(t, server.URL, good, root, http.MethodPost); response.Status != ocsp.Good {
+		t.Errorf("Unexpected status for good certificate: %d", response.Status)
+	}
+	if response := query(t, server.URL, good, root, http.MethodGet); response.Status != ocsp.Good {
+		t.Errorf("Unexpected status for good certificate using GET: %d", response.Status)
+	}
+
+	response := query(t, server.URL, revoked, root, http.MethodPost)
+	if response.Status != ocsp.Revoked {
+		t.Errorf("Unexpected status for revoked certificate: %d", response.Status)
+	}
+	if !response.RevokedAt.Equal(revocationDate) {
+		t.Errorf("Unexpected revocation date. Expected '%s' got '%s'", revocationDate, response.RevokedAt)
+	}
+	if response.RevocationReason != tls.RevocationReasonKeyCompromise {
+		t.Errorf("Unexpected revocation reason: %d", response.RevocationReason)
+	}
+
+	if response := query(t, server.URL, unknown, root, http.MethodPost); response.Status != ocsp.Unknown {
+		t.Errorf("Unexpected status for unknown certificate: %d", response.Status)
+	}
+}
+
+func TestResponderPrefix(t *testing.T) {
+	t.Parallel()
+
+	root, err := tls.GenerateCertificate(tls.CertificateRequest{
+		KeyType:            tls.KeyTypeECDSA_256,
+		SignatureAlgorithm: tls.SignatureAlgorithmSHA256,
+		Subject:            tls.Name{CommonName: "Root"},
+		Validity: tls.DateRange{
+			NotBefore: "2001-01-01",
+			NotAfter:  "2099-01-01",
+		},
+		IsCertificateAuthority: true,
+	}, nil)
+	if err != nil {
+		t.Fatalf("Error generating certificate: %s", err.Error())
+	}
+
+	leaf, err := tls.GenerateCertificate(tls.CertificateRequest{
+		KeyType:            tls.KeyTypeECDSA_256,
+		SignatureAlgorithm: tls.SignatureAlgorithmSHA256,
+		Subject:            tls.Name{CommonName: "leaf.example.com"},
+		Validity: tls.DateRange{
+			NotBefore: "2001-01-01",
+			NotAfter:  "2099-01-01",
+		},
+	}, root)
+	if err != nil {
+		t.Fatalf("Error generating certificate: %s", err.Error())
+	}
+
+	responder, err := certboxocsp.NewResponder(certboxocsp.Options{
+		Issuer: *root,
+		Statuses: []certboxocsp.Status{
+			{Serial: leaf.Serial, Status: certboxocsp.StatusGood},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Error creating responder: %s", err.Error())
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/ocsp/", responder)
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	if response := query(t, server.URL+"/ocsp", leaf, root, http.MethodGet); response.Status != ocsp.Good {
+		t.Errorf("Unexpected status for good certificate using GET: %d", response.Status)
+	}
+}
+
+func TestResponderDelegated(t *testing.T) {
+	t.Parallel()
+
+	root, err := tls.GenerateCertificate(tls.CertificateRequest{
+		KeyType:            tls.KeyTypeECDSA_256,
+		SignatureAlgorithm: tls.SignatureAlgorithmSHA256,
+		Subject:            tls.Name{CommonName: "Root"},
+		Validity: tls.DateRange{
+			NotBefore: "2001-01-01",
+			NotAfter:  "2099-01-01",
+		},
+		IsCertificateAuthority: true,
+	}, nil)
+	if err != nil {
+		t.Fatalf("Error generating certificate: %s", err.Error())
+	}
+
+	leaf, err := tls.GenerateCertificate(tls.CertificateRequest{
+		KeyType:            tls.KeyTypeECDSA_256,
+		SignatureAlgorithm: tls.SignatureAlgorithmSHA256,
+		Subject:            tls.Name{CommonName: "leaf.example.com"},
+		Validity: tls.DateRange{
+			NotBefore: "2001-01-01",
+			NotAfter:  "2099-01-01",
+		},
+	}, root)
+	if err != nil {
+		t.Fatalf("Error generating certificate: %s", err.Error())
+	}
+
+	delegate, err := tls.GenerateCertificate(tls.CertificateRequest{
+		KeyType:            tls.KeyTypeECDSA_256,
+		SignatureAlgorithm: tls.SignatureAlgorithmSHA256,
+		Subject:            tls.Name{CommonName: "OCSP Responder"},
+		Validity: tls.DateRange{
+			NotBefore: "2001-01-01",
+			NotAfter:  "2099-01-01",
+		},
+		Usage: tls.KeyUsage{DigitalSignature: true, OCSPSigning: true},
+	}, root)
+	if err != nil {
+		t.Fatalf("Error generating certificate: %s", err.Error())
+	}
+
+	// The issuer key is not required when using a delegated responder
+	issuer := *root
+	issuer.KeyData = ""
+
+	responder, err := certboxocsp.NewResponder(certboxocsp.Options{
+		Issuer:    issuer,
+		Responder: delegate,
+		Statuses: []certboxocsp.Status{
+			{Serial: leaf.Serial, Status: certboxocsp.StatusGood},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Error creating responder: %s", err.Error())
+	}
+	server := httptest.NewServer(responder)
+	defer server.Close()
+
+	response := query(t, server.URL, leaf, root, http.MethodPost)
+	if response.Status != ocsp.Good {
+		t.Errorf("Unexpected status for good certificate: %d", response.Status)
+	}
+	if response.Certificate == nil || !bytes.Equal(response.Certificate.Raw, delegate.X509().Raw) {
+		t.Errorf("Response does not include delegated responder certificate")
+	}
+
+	if _, err := certboxocsp.NewResponder(certboxocsp.Options{
+		Issuer:    *root,
+		Responder: leaf,
+	}); err == nil {
+		t.Errorf("No error seen when one expected for responder without OCSP signing usage")
+	}
+}
+
+func TestResponderOtherIssuer(t *testing.T) {
+	t.Parallel()
+
+	root, err := tls.GenerateCertificate(tls.CertificateRequest{
+		KeyType:            tls.KeyTypeECDSA_256,
+		SignatureAlgorithm: tls.SignatureAlgorithmSHA256,
+		Subject:            tls.Name{CommonName: "Root"},
+		Validity: tls.DateRange{
+			NotBefore: "2001-01-01",
+			NotAfter:  "2099-01-01",
+		},
+		IsCertificateAuthority: true,
+	}, nil)
+	if err != nil {
+		t.Fatalf("Error generating certificate: %s", err.Error())
+	}
+
+	otherRoot, err := tls.GenerateCertificate(tls.CertificateRequest{
+		KeyType:            tls.KeyTypeECDSA_256,
+		SignatureAlgorithm: tls.SignatureAlgorithmSHA256,
+		Subject:            tls.Name{CommonName: "Other Root"},
+		Validity: tls.DateRange{
+			NotBefore: "2001-01-01",
+			NotAfter:  "2099-01-01",
+		},
+		IsCertificateAuthority: true,
+	}, nil)
+	if err != nil {
+		t.Fatalf("Error generating certificate: %s", err.Error())
+	}
+
+	leaf, err := tls.GenerateCertificate(tls.CertificateRequest{
+		KeyType:            tls.KeyTypeECDSA_256,
+		SignatureAlgorithm: tls.SignatureAlgorithmSHA256,
+		Subject:            tls.Name{CommonName: "leaf.example.com"},
+		Validity: tls.DateRange{
+			NotBefore: "2001-01-01",
+			NotAfter:  "2099-01-01",
+		},
+	}, otherRoot)
+	if err != nil {
+		t.Fatalf("Error generating certificate: %s", err.Error())
+	}
+
+	responder, err := certboxocsp.NewResponder(certboxocsp.Options{Issuer: *root})
+	if err != nil {
+		t.Fatalf("Error creating responder: %s", err.Error())
+	}
+
+	request, err := ocsp.CreateRequest(leaf.X509(), otherRoot.X509(), nil)
+	if err != nil {
+		t.Fatalf("Error creating OCSP request: %s", err.Error())
+	}
+	response, err := responder.Respond(request)
+	if err != nil {
+		t.Fatalf("Error responding to OCSP request: %s", err.Error())
+	}
+	if !bytes.Equal(response, ocsp.UnauthorizedErrorResponse) {
+		t.Errorf("Expected unauthorized response for certificate from other issuer")
+	}
+}
+
+func TestDatabaseInvalid(t *testing.T) {
+	t.Parallel()
+
+	if _, err := certboxocsp.NewDatabase([]certboxocsp.Status{{Serial: "1", Status: "invalid"}}); err == nil {
+		t.Errorf("No error seen when one expected for invalid status")
+	}
+	if _, err := certboxocsp.NewDatabase([]certboxocsp.Status{{Serial: "1", Status: certboxocsp.StatusRevoked}}); err == nil {
+		t.Errorf("No error seen when one expected for missing revocation date")
+	}
+
+	database, err := certboxocsp.NewDatabase([]certboxocsp.Status{{Serial: "1", Status: certboxocsp.StatusRevoked, RevocationDate: "2020-01-01"}})
+	if err != nil {
+		t.Fatalf("Error creating database: %s", err.Error())
+	}
+	if status := database.Get(big.NewInt(1)); status.RevocationDate != "2020-01-01T00:00:00Z" {
+		t.Errorf("Unexpected revocation date '%s'", status.RevocationDate)
+	}
+}
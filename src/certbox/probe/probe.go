@@ -0,0 +1,120 @@
+// Package probe retrieves the certificate chain presented by a live TLS server
+package probe
+
+import (
+	gotls "crypto/tls"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/tls-inspector/certbox/tls"
+)
+
+// STARTTLS protocols
+const (
+	StartTLSSMTP     = "smtp"
+	StartTLSIMAP     = "imap"
+	StartTLSPOP3     = "pop3"
+	StartTLSLDAP     = "ldap"
+	StartTLSPostgres = "postgres"
+)
+
+const defaultTimeout = 10 * time.Second
+
+// Options describes the options for probing a TLS server
+type Options struct {
+	// Address is the host and port of the server, such as example.com:443
+	Address string
+	// ServerName is sent as the server name indication. Defaults to the host of Address, unless it is an IP address.
+	ServerName string
+	// StartTLS is the protocol used to upgrade the connection to TLS, if any
+	StartTLS string
+	// Timeout is the duration allowed for connecting and completing the handshake, such as "5s". Defaults to 10
+	// seconds.
+	Timeout string
+}
+
+// Result describes the result of probing a TLS server
+type Result struct {
+	// Certificates is the chain presented by the server, in the order it was presented
+	Certificates []*tls.Certificate
+	// Requests contains a certificate request that would clone each certificate in Certificates. A request is nil if
+	// the certificate can't be cloned.
+	Requests    []*tls.CertificateRequest
+	Version     string
+	CipherSuite string
+}
+
+// Probe will connect to the server described by options, complete a TLS handshake and return the certificates it
+// presented. The certificates are not verified.
+func Probe(options Options) (*Result, error) {
+	host, _, err := net.SplitHostPort(options.Address)
+	if err != nil {
+		return nil, fmt.Errorf("invalid address: %s", err.Error())
+	}
+
+	serverName := options.ServerName
+	if serverName == "" && net.ParseIP(host) == nil {
+		serverName = host
+	}
+
+	timeout := defaultTimeout
+	if options.Timeout != "" {
+		timeout, err = time.ParseDuration(options.Timeout)
+		if err != nil || timeout <= 0 {
+			return nil, fmt.Errorf("invalid timeout '%s'", options.Timeout)
+		}
+	}
+
+	upgrade, err := startTLSFunc(options.StartTLS)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := net.DialTimeout("tcp", options.Address, timeout)
+	if err != nil {
+		return nil, fmt.Errorf("error connecting to %s: %s", options.Address, err.Error())
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(timeout))
+
+	if upgrade != nil {
+		if err := upgrade(conn); err != nil {
+			return nil, fmt.Errorf("error starting %s tls: %s", options.StartTLS, err.Error())
+		}
+	}
+
+	client := gotls.Client(conn, &gotls.Config{
+		ServerName:         serverName,
+		InsecureSkipVerify: true,
+	})
+	if err := client.Handshake(); err != nil {
+		return nil, fmt.Errorf("tls handshake error: %s", err.Error())
+	}
+	state := client.ConnectionState()
+
+	result := &Result{
+		Certificates: []*tls.Certificate{},
+		Requests:     []*tls.CertificateRequest{},
+		Version:      gotls.VersionName(state.Version),
+		CipherSuite:  gotls.CipherSuiteName(state.CipherSuite),
+	}
+	for _, x := range state.PeerCertificates {
+		certificate, err := tls.ImportDERCertificate(x.Raw)
+		if err != nil {
+			return nil, err
+		}
+		result.Certificates = append(result.Certificates, certificate)
+		result.Requests = append(result.Requests, cloneRequest(certificate))
+	}
+	return result, nil
+}
+
+// cloneRequest returns the clone request for certificate, or nil if it can't be cloned
+func cloneRequest(certificate *tls.Certificate) *tls.CertificateRequest {
+	clone, err := certificate.Clone()
+	if err != nil {
+		return nil
+	}
+	return &clone
+}
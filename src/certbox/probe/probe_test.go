@@ -0,0 +1,228 @@
+package probe_test
+
+import (
+	"bufio"
+	"bytes"
+	gotls "crypto/tls"
+	"encoding/binary"
+	"io"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/tls-inspector/certbox/probe"
+	"github.com/tls-inspector/certbox/tls"
+)
+
+// serverConfig returns a TLS server configuration presenting a leaf and root certificate. The server name indicated by
+// each client is sent to serverNames.
+func serverConfig(t *testing.T, serverNames chan string) (*gotls.Config, []*tls.Certificate) {
+	root, err := tls.GenerateCertificate(tls.CertificateRequest{
+		KeyType:                tls.KeyTypeECDSA_256,
+		SignatureAlgorithm:     tls.SignatureAlgorithmSHA256,
+		Subject:                tls.Name{Organization: "example.com", CommonName: "example.com Root"},
+		Validity:               tls.DateRange{NotBefore: "-1d", NotAfter: "+1d"},
+		Usage:                  tls.KeyUsage{DigitalSignature: true, CertSign: true},
+		IsCertificateAuthority: true,
+	}, nil)
+	if err != nil {
+		t.Fatalf("Error generating certificate: %s", err.Error())
+	}
+
+	leaf, err := tls.GenerateCertificate(tls.CertificateRequest{
+		KeyType:            tls.KeyTypeECDSA_256,
+		SignatureAlgorithm: tls.SignatureAlgorithmSHA256,
+		Subject:            tls.Name{Organization: "example.com", CommonName: "foo.example.com"},
+		AlternateNames:     []tls.AlternateName{{Type: tls.AlternateNameTypeDNS, Value: "foo.example.com"}},
+		Validity:           tls.DateRange{NotBefore: "-1d", NotAfter: "+1d"},
+		Usage:              tls.KeyUsage{DigitalSignature: true, ServerAuth: true},
+	}, root)
+	if err != nil {
+		t.Fatalf("Error generating certificate: %s", err.Error())
+	}
+
+	leafPEM, keyPEM, err := tls.ExportPEM(leaf)
+	if err != nil {
+		t.Fatalf("Error exporting certificate: %s", err.Error())
+	}
+	rootPEM, _, err := tls.ExportPEM(root)
+	if err != nil {
+		t.Fatalf("Error exporting certificate: %s", err.Error())
+	}
+	keyPair, err := gotls.X509KeyPair(append(leafPEM, rootPEM...), keyPEM)
+	if err != nil {
+		t.Fatalf("Error loading key pair: %s", err.Error())
+	}
+
+	config := &gotls.Config{
+		Certificates: []gotls.Certificate{keyPair},
+		GetConfigForClient: func(hello *gotls.ClientHelloInfo) (*gotls.Config, error) {
+			serverNames <- hello.ServerName
+			return nil, nil
+		},
+	}
+	return config, []*tls.Certificate{leaf, root}
+}
+
+// startServer starts a TLS server that performs the server side of the given STARTTLS protocol before the handshake
+// and returns its address
+func startServer(t *testing.T, config *gotls.Config, startTLS func(conn net.Conn, reader *bufio.Reader) bool) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Error listening: %s", err.Error())
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				if startTLS != nil && !startTLS(conn, bufio.NewReader(conn)) {
+					return
+				}
+				server := gotls.Server(conn, config)
+				if err := server.Handshake(); err != nil {
+					return
+				}
+				io.Copy(io.Discard, server)
+			}()
+		}
+	}()
+
+	return listener.Addr().String()
+}
+
+func expectLine(reader *bufio.Reader, expected string) bool {
+	line, err := reader.ReadString('\n')
+	return err == nil && strings.TrimSpace(line) == expected
+}
+
+var startTLSServers = map[string]func(conn net.Conn, reader *bufio.Reader) bool{
+	probe.StartTLSSMTP: func(conn net.Conn, reader *bufio.Reader) bool {
+		io.WriteString(conn, "220-mail.example.com ESMTP\r\n220 ready\r\n")
+		if !expectLine(reader, "EHLO certbox") {
+			return false
+		}
+		io.WriteString(conn, "250-mail.example.com\r\n250-PIPELINING\r\n250 STARTTLS\r\n")
+		if !expectLine(reader, "STARTTLS") {
+			return false
+		}
+		io.WriteString(conn, "220 go ahead\r\n")
+		return true
+	},
+	probe.StartTLSIMAP: func(conn net.Conn, reader *bufio.Reader) bool {
+		io.WriteString(conn, "* OK [CAPABILITY IMAP4rev1 STARTTLS] ready\r\n")
+		if !expectLine(reader, "a1 STARTTLS") {
+			return false
+		}
+		io.WriteString(conn, "a1 OK begin tls negotiation now\r\n")
+		return true
+	},
+	probe.StartTLSPOP3: func(conn net.Conn, reader *bufio.Reader) bool {
+		io.WriteString(conn, "+OK POP3 ready\r\n")
+		if !expectLine(reader, "STLS") {
+			return false
+		}
+		io.WriteString(conn, "+OK begin tls\r\n")
+		return true
+	},
+	probe.StartTLSLDAP: func(conn net.Conn, reader *bufio.Reader) bool {
+		request := make([]byte, 31)
+		if _, err := io.ReadFull(reader, request); err != nil || !bytes.Contains(request, []byte("1.3.6.1.4.1.1466.20037")) {
+			return false
+		}
+		// ExtendedResponse with a success result code, an empty matched DN and diagnostic message
+		conn.Write([]byte{0x30, 0x0c, 0x02, 0x01, 0x01, 0x78, 0x07, 0x0a, 0x01, 0x00, 0x04, 0x00, 0x04, 0x00})
+		return true
+	},
+	probe.StartTLSPostgres: func(conn net.Conn, reader *bufio.Reader) bool {
+		request := make([]byte, 8)
+		if _, err := io.ReadFull(reader, request); err != nil || binary.BigEndian.Uint32(request[4:]) != 80877103 {
+			return false
+		}
+		conn.Write([]byte{'S'})
+		return true
+	},
+}
+
+func assertPresentedChain(t *testing.T, result *probe.Result, chain []*tls.Certificate) {
+	if len(result.Certificates) != len(chain) || len(result.Requests) != len(chain) {
+		t.Fatalf("Expected %d certificates, got %d", len(chain), len(result.Certificates))
+	}
+	for i, certificate := range chain {
+		if result.Certificates[i].CertificateData != certificate.CertificateData {
+			t.Errorf("Presented certificate %d does not match", i)
+		}
+		if result.Requests[i] == nil || result.Requests[i].Subject.CommonName != certificate.Subject.CommonName {
+			t.Errorf("Unexpected clone request for certificate %d", i)
+		}
+	}
+}
+
+func TestProbe(t *testing.T) {
+	t.Parallel()
+
+	serverNames := make(chan string, 1)
+	config, chain := serverConfig(t, serverNames)
+	address := startServer(t, config, nil)
+
+	result, err := probe.Probe(probe.Options{Address: address, ServerName: "foo.example.com"})
+	if err != nil {
+		t.Fatalf("Error probing server: %s", err.Error())
+	}
+	assertPresentedChain(t, result, chain)
+	if serverName := <-serverNames; serverName != "foo.example.com" {
+		t.Errorf("Unexpected server name '%s'", serverName)
+	}
+	if result.Version == "" || result.CipherSuite == "" {
+		t.Errorf("Missing negotiated version or cipher suite")
+	}
+
+	// No server name is sent for an IP address
+	if _, err := probe.Probe(probe.Options{Address: address}); err != nil {
+		t.Fatalf("Error probing server: %s", err.Error())
+	}
+	if serverName := <-serverNames; serverName != "" {
+		t.Errorf("Unexpected server name '%s'", serverName)
+	}
+}
+
+func TestProbeStartTLS(t *testing.T) {
+	t.Parallel()
+
+	serverNames := make(chan string, len(startTLSServers))
+	config, chain := serverConfig(t, serverNames)
+
+	for protocol, startTLS := range startTLSServers {
+		address := startServer(t, config, startTLS)
+		result, err := probe.Probe(probe.Options{Address: address, StartTLS: protocol, Timeout: "5s"})
+		if err != nil {
+			t.Fatalf("Error probing %s server: %s", protocol, err.Error())
+		}
+		assertPresentedChain(t, result, chain)
+	}
+}
+
+func TestProbeInvalid(t *testing.T) {
+	t.Parallel()
+
+	serverNames := make(chan string, 1)
+	config, _ := serverConfig(t, serverNames)
+	address := startServer(t, config, nil)
+
+	invalid := map[string]probe.Options{
+		"missing port":           {Address: "127.0.0.1"},
+		"unknown protocol":       {Address: address, StartTLS: "gopher"},
+		"invalid timeout":        {Address: address, Timeout: "soon"},
+		"starttls without reply": {Address: address, StartTLS: probe.StartTLSSMTP, Timeout: "500ms"},
+	}
+	for name, options := range invalid {
+		if _, err := probe.Probe(options); err == nil {
+			t.Errorf("No error seen when one expected for %s", name)
+		}
+	}
+}
@@ -0,0 +1,202 @@
+package probe
+
+import (
+	"bufio"
+	"encoding/asn1"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+)
+
+// startTLSFunc returns the function that upgrades a connection for the given STARTTLS protocol, or nil if the
+// connection begins with TLS
+func startTLSFunc(protocol string) (func(conn net.Conn) error, error) {
+	switch protocol {
+	case "":
+		return nil, nil
+	case StartTLSSMTP:
+		return startTLSSMTP, nil
+	case StartTLSIMAP:
+		return startTLSIMAP, nil
+	case StartTLSPOP3:
+		return startTLSPOP3, nil
+	case StartTLSLDAP:
+		return startTLSLDAP, nil
+	case StartTLSPostgres:
+		return startTLSPostgres, nil
+	}
+	return nil, fmt.Errorf("unknown starttls protocol '%s'", protocol)
+}
+
+// readSMTPReply reads a possibly multi-line SMTP reply and returns an error if its code is not the expected code
+func readSMTPReply(reader *bufio.Reader, code string) error {
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return err
+		}
+		if !strings.HasPrefix(line, code) {
+			return fmt.Errorf("unexpected reply '%s'", strings.TrimSpace(line))
+		}
+		if len(line) < 4 || line[3] != '-' {
+			return nil
+		}
+	}
+}
+
+func startTLSSMTP(conn net.Conn) error {
+	reader := bufio.NewReader(conn)
+	if err := readSMTPReply(reader, "220"); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(conn, "EHLO certbox\r\n"); err != nil {
+		return err
+	}
+	if err := readSMTPReply(reader, "250"); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(conn, "STARTTLS\r\n"); err != nil {
+		return err
+	}
+	return readSMTPReply(reader, "220")
+}
+
+func startTLSIMAP(conn net.Conn) error {
+	reader := bufio.NewReader(conn)
+	greeting, err := reader.ReadString('\n')
+	if err != nil {
+		return err
+	}
+	if !strings.HasPrefix(greeting, "* OK") {
+		return fmt.Errorf("unexpected greeting '%s'", strings.TrimSpace(greeting))
+	}
+	if _, err := io.WriteString(conn, "a1 STARTTLS\r\n"); err != nil {
+		return err
+	}
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return err
+		}
+		if strings.HasPrefix(line, "* ") {
+			continue
+		}
+		if !strings.HasPrefix(line, "a1 OK") {
+			return fmt.Errorf("unexpected reply '%s'", strings.TrimSpace(line))
+		}
+		return nil
+	}
+}
+
+func startTLSPOP3(conn net.Conn) error {
+	reader := bufio.NewReader(conn)
+	greeting, err := reader.ReadString('\n')
+	if err != nil {
+		return err
+	}
+	if !strings.HasPrefix(greeting, "+OK") {
+		return fmt.Errorf("unexpected greeting '%s'", strings.TrimSpace(greeting))
+	}
+	if _, err := io.WriteString(conn, "STLS\r\n"); err != nil {
+		return err
+	}
+	reply, err := reader.ReadString('\n')
+	if err != nil {
+		return err
+	}
+	if !strings.HasPrefix(reply, "+OK") {
+		return fmt.Errorf("unexpected reply '%s'", strings.TrimSpace(reply))
+	}
+	return nil
+}
+
+// ldapStartTLSRequest is an LDAP extended request for the StartTLS operation, as defined in RFC 4511 section 4.14.1
+var ldapStartTLSRequest = []byte{
+	0x30, 0x1d, // LDAPMessage
+	0x02, 0x01, 0x01, // messageID
+	0x77, 0x18, // ExtendedRequest
+	0x80, 0x16, // requestName
+	'1', '.', '3', '.', '6', '.', '1', '.', '4', '.', '1', '.', '1', '4', '6', '6', '.', '2', '0', '0', '3', '7',
+}
+
+func startTLSLDAP(conn net.Conn) error {
+	if _, err := conn.Write(ldapStartTLSRequest); err != nil {
+		return err
+	}
+
+	message, err := readDERElement(conn)
+	if err != nil {
+		return err
+	}
+	var response struct {
+		MessageID int
+		Response  asn1.RawValue
+	}
+	if _, err := asn1.Unmarshal(message, &response); err != nil {
+		return fmt.Errorf("invalid ldap response: %s", err.Error())
+	}
+	if response.Response.Class != asn1.ClassApplication || response.Response.Tag != 24 {
+		return fmt.Errorf("unexpected ldap response")
+	}
+	var resultCode asn1.Enumerated
+	if _, err := asn1.Unmarshal(response.Response.Bytes, &resultCode); err != nil {
+		return fmt.Errorf("invalid ldap response: %s", err.Error())
+	}
+	if resultCode != 0 {
+		return fmt.Errorf("ldap server returned result code %d", resultCode)
+	}
+	return nil
+}
+
+// readDERElement reads a single DER encoded element from reader
+func readDERElement(reader io.Reader) ([]byte, error) {
+	header := make([]byte, 2)
+	if _, err := io.ReadFull(reader, header); err != nil {
+		return nil, err
+	}
+
+	length := int(header[1])
+	if length&0x80 != 0 {
+		lengthBytes := make([]byte, length&0x7f)
+		if len(lengthBytes) == 0 || len(lengthBytes) > 3 {
+			return nil, fmt.Errorf("unsupported ldap message length")
+		}
+		if _, err := io.ReadFull(reader, lengthBytes); err != nil {
+			return nil, err
+		}
+		header = append(header, lengthBytes...)
+		length = 0
+		for _, b := range lengthBytes {
+			length = length<<8 | int(b)
+		}
+	}
+
+	body := make([]byte, length)
+	if _, err := io.ReadFull(reader, body); err != nil {
+		return nil, err
+	}
+	return append(header, body...), nil
+}
+
+// postgresSSLRequestCode is the request code of a PostgreSQL SSLRequest message
+const postgresSSLRequestCode = 80877103
+
+func startTLSPostgres(conn net.Conn) error {
+	request := make([]byte, 8)
+	binary.BigEndian.PutUint32(request[0:4], 8)
+	binary.BigEndian.PutUint32(request[4:8], postgresSSLRequestCode)
+	if _, err := conn.Write(request); err != nil {
+		return err
+	}
+
+	reply := make([]byte, 1)
+	if _, err := io.ReadFull(conn, reply); err != nil {
+		return err
+	}
+	if reply[0] != 'S' {
+		return fmt.Errorf("server does not support ssl")
+	}
+	return nil
+}
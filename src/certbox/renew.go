@@ -0,0 +1,20 @@
+package certbox
+
+import (
+	"github.com/tls-inspector/certbox/tls"
+)
+
+// RenewCertificateParameters describes the parameters for renewing a certificate
+type RenewCertificateParameters struct {
+	Certificate tls.Certificate
+	// Issuer is the certificate authority that will sign the renewed certificate. If nil the certificate is
+	// self-signed.
+	Issuer  *tls.Certificate
+	Options tls.RenewOptions
+}
+
+// RenewCertificate will issue a replacement for the given certificate that preserves its subject, alternate names,
+// usage and extensions
+func RenewCertificate(parameters RenewCertificateParameters) (*tls.Certificate, error) {
+	return tls.RenewCertificate(parameters.Certificate, parameters.Issuer, parameters.Options)
+}
@@ -0,0 +1,19 @@
+package certbox
+
+import (
+	"github.com/tls-inspector/certbox/tls"
+)
+
+// SignCSRParameters describes the parameters for signing a certificate signing request
+type SignCSRParameters struct {
+	// Data is the PEM encoded certificate signing request
+	Data    []byte
+	Issuer  tls.Certificate
+	Request tls.CertificateRequest
+}
+
+// SignCSR will issue a certificate for the given certificate signing request. The properties in Request are applied
+// as policy overrides to the certificate. The returned certificate does not include a private key.
+func SignCSR(parameters SignCSRParameters) (*tls.Certificate, error) {
+	return tls.SignCSR(parameters.Data, &parameters.Issuer, parameters.Request)
+}
@@ -0,0 +1,319 @@
+package tls
+
+import (
+	"bytes"
+	"encoding/asn1"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+)
+
+// ASN.1 value types
+const (
+	ASN1TypeBoolean         = "boolean"
+	ASN1TypeInteger         = "integer"
+	ASN1TypeEnumerated      = "enumerated"
+	ASN1TypeBitString       = "bitstring"
+	ASN1TypeOctetString     = "octetstring"
+	ASN1TypeNull            = "null"
+	ASN1TypeOID             = "oid"
+	ASN1TypeUTF8String      = "utf8string"
+	ASN1TypePrintableString = "printablestring"
+	ASN1TypeIA5String       = "ia5string"
+	ASN1TypeNumericString   = "numericstring"
+	ASN1TypeVisibleString   = "visiblestring"
+	ASN1TypeUTCTime         = "utctime"
+	ASN1TypeGeneralizedTime = "generalizedtime"
+	ASN1TypeSequence        = "sequence"
+	ASN1TypeSet             = "set"
+	// ASN1TypeRaw is any other DER encoded value, including its tag and length
+	ASN1TypeRaw = "raw"
+)
+
+// ASN.1 tagging modes
+const (
+	ASN1TaggingImplicit = "implicit"
+	ASN1TaggingExplicit = "explicit"
+)
+
+// ASN1Value describes a typed ASN.1 value. Values of primitive types are described as a string:
+//
+//   - boolean: true or false
+//   - integer and enumerated: a decimal number, or hexadecimal if prefixed with 0x
+//   - bitstring: a string of binary digits, such as 1011
+//   - octetstring and raw: hexadecimal
+//   - oid: a dotted object identifier, such as 1.2.3.4
+//   - utctime and generalizedtime: a RFC 3339 timestamp
+//   - null: empty
+//
+// Sequences and sets are described by their Children and strings are used as-is.
+type ASN1Value struct {
+	Type     string
+	Value    string
+	Children []ASN1Value
+	// Tagging, if set, is either ASN1TaggingImplicit to replace the tag of this value with the context-specific Tag,
+	// or ASN1TaggingExplicit to wrap this value with the context-specific Tag.
+	Tagging string
+	Tag     int
+}
+
+var asn1StringTags = map[string]int{
+	ASN1TypeUTF8String:      asn1.TagUTF8String,
+	ASN1TypePrintableString: asn1.TagPrintableString,
+	ASN1TypeIA5String:       asn1.TagIA5String,
+	ASN1TypeNumericString:   asn1.TagNumericString,
+	ASN1TypeVisibleString:   26,
+}
+
+// marshal returns the DER encoding of this value
+func (v ASN1Value) marshal() ([]byte, error) {
+	data, err := v.marshalUntagged()
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s value: %s", v.Type, err.Error())
+	}
+
+	switch v.Tagging {
+	case "":
+		return data, nil
+	case ASN1TaggingImplicit:
+		var raw asn1.RawValue
+		if _, err := asn1.Unmarshal(data, &raw); err != nil {
+			return nil, err
+		}
+		return asn1.Marshal(asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: v.Tag, IsCompound: raw.IsCompound, Bytes: raw.Bytes})
+	case ASN1TaggingExplicit:
+		return asn1.Marshal(asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: v.Tag, IsCompound: true, Bytes: data})
+	default:
+		return nil, fmt.Errorf("invalid asn1 tagging '%s'", v.Tagging)
+	}
+}
+
+func (v ASN1Value) marshalUntagged() ([]byte, error) {
+	if tag, isString := asn1StringTags[v.Type]; isString {
+		switch v.Type {
+		case ASN1TypePrintableString:
+			return asn1.MarshalWithParams(v.Value, "printable")
+		case ASN1TypeIA5String:
+			return asn1.MarshalWithParams(v.Value, "ia5")
+		case ASN1TypeNumericString:
+			return asn1.MarshalWithParams(v.Value, "numeric")
+		}
+		return asn1.Marshal(asn1.RawValue{Tag: tag, Bytes: []byte(v.Value)})
+	}
+
+	switch v.Type {
+	case ASN1TypeBoolean:
+		switch v.Value {
+		case "true":
+			return asn1.Marshal(true)
+		case "false":
+			return asn1.Marshal(false)
+		}
+		return nil, fmt.Errorf("expected true or false")
+	case ASN1TypeInteger, ASN1TypeEnumerated:
+		n, ok := new(big.Int).SetString(v.Value, 0)
+		if !ok {
+			return nil, fmt.Errorf("invalid number '%s'", v.Value)
+		}
+		data, err := asn1.Marshal(n)
+		if err != nil {
+			return nil, err
+		}
+		if v.Type == ASN1TypeEnumerated {
+			data[0] = asn1.TagEnum
+		}
+		return data, nil
+	case ASN1TypeBitString:
+		bits := asn1.BitString{BitLength: len(v.Value), Bytes: make([]byte, (len(v.Value)+7)/8)}
+		for i, c := range v.Value {
+			switch c {
+			case '1':
+				bits.Bytes[i/8] |= 0x80 >> uint(i%8)
+			case '0':
+			default:
+				return nil, fmt.Errorf("expected binary digits")
+			}
+		}
+		return asn1.Marshal(bits)
+	case ASN1TypeOctetString:
+		data, err := hex.DecodeString(v.Value)
+		if err != nil {
+			return nil, err
+		}
+		return asn1.Marshal(data)
+	case ASN1TypeNull:
+		return asn1.NullBytes, nil
+	case ASN1TypeOID:
+		oid, err := parseOid(v.Value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid oid '%s'", v.Value)
+		}
+		return asn1.Marshal(oid)
+	case ASN1TypeUTCTime, ASN1TypeGeneralizedTime:
+		t, err := time.Parse(time.RFC3339, v.Value)
+		if err != nil {
+			return nil, err
+		}
+		if v.Type == ASN1TypeUTCTime {
+			return asn1.MarshalWithParams(t.UTC(), "utc")
+		}
+		return asn1.MarshalWithParams(t.UTC(), "generalized")
+	case ASN1TypeSequence, ASN1TypeSet:
+		children := []byte{}
+		for _, child := range v.Children {
+			data, err := child.marshal()
+			if err != nil {
+				return nil, err
+			}
+			children = append(children, data...)
+		}
+		tag := asn1.TagSequence
+		if v.Type == ASN1TypeSet {
+			tag = asn1.TagSet
+		}
+		return asn1.Marshal(asn1.RawValue{Tag: tag, IsCompound: true, Bytes: children})
+	case ASN1TypeRaw:
+		data, err := hex.DecodeString(v.Value)
+		if err != nil {
+			return nil, err
+		}
+		var raw asn1.RawValue
+		if rest, err := asn1.Unmarshal(data, &raw); err != nil || len(rest) > 0 {
+			return nil, fmt.Errorf("value is not a single DER encoded value")
+		}
+		return data, nil
+	default:
+		return nil, fmt.Errorf("unknown asn1 type")
+	}
+}
+
+// asn1ValueFromDER returns the typed value for the given DER encoded value. Values that can't be described by a type
+// are described as raw values, so marshalling the result always produces the same encoding.
+func asn1ValueFromDER(data []byte) (ASN1Value, error) {
+	var raw asn1.RawValue
+	rest, err := asn1.Unmarshal(data, &raw)
+	if err != nil {
+		return ASN1Value{}, err
+	}
+	if len(rest) > 0 {
+		return ASN1Value{}, fmt.Errorf("trailing data after asn1 value")
+	}
+
+	return asn1ValueFromRaw(raw), nil
+}
+
+// asn1ValueFromRaw returns the typed value for raw, or a raw value if the typed value would not be encoded the same
+func asn1ValueFromRaw(raw asn1.RawValue) ASN1Value {
+	value := asn1TypedValueFromRaw(raw)
+	if encoded, err := value.marshal(); err != nil || !bytes.Equal(encoded, raw.FullBytes) {
+		return ASN1Value{Type: ASN1TypeRaw, Value: hex.EncodeToString(raw.FullBytes)}
+	}
+	return value
+}
+
+func asn1TypedValueFromRaw(raw asn1.RawValue) ASN1Value {
+	rawValue := ASN1Value{Type: ASN1TypeRaw, Value: hex.EncodeToString(raw.FullBytes)}
+
+	if raw.Class == asn1.ClassContextSpecific {
+		if !raw.IsCompound {
+			return ASN1Value{Type: ASN1TypeOctetString, Value: hex.EncodeToString(raw.Bytes), Tagging: ASN1TaggingImplicit, Tag: raw.Tag}
+		}
+		children, err := asn1ChildrenFromDER(raw.Bytes)
+		if err != nil {
+			return rawValue
+		}
+		if len(children) == 1 {
+			child := children[0]
+			if child.Tagging == "" {
+				child.Tagging = ASN1TaggingExplicit
+				child.Tag = raw.Tag
+				return child
+			}
+		}
+		return ASN1Value{Type: ASN1TypeSequence, Children: children, Tagging: ASN1TaggingImplicit, Tag: raw.Tag}
+	}
+	if raw.Class != asn1.ClassUniversal {
+		return rawValue
+	}
+
+	if raw.IsCompound {
+		if raw.Tag != asn1.TagSequence && raw.Tag != asn1.TagSet {
+			return rawValue
+		}
+		children, err := asn1ChildrenFromDER(raw.Bytes)
+		if err != nil {
+			return rawValue
+		}
+		if raw.Tag == asn1.TagSet {
+			return ASN1Value{Type: ASN1TypeSet, Children: children}
+		}
+		return ASN1Value{Type: ASN1TypeSequence, Children: children}
+	}
+
+	for valueType, tag := range asn1StringTags {
+		if raw.Tag == tag {
+			return ASN1Value{Type: valueType, Value: string(raw.Bytes)}
+		}
+	}
+
+	switch raw.Tag {
+	case asn1.TagBoolean:
+		var b bool
+		if _, err := asn1.Unmarshal(raw.FullBytes, &b); err == nil {
+			return ASN1Value{Type: ASN1TypeBoolean, Value: fmt.Sprintf("%t", b)}
+		}
+	case asn1.TagInteger, asn1.TagEnum:
+		data := append([]byte{asn1.TagInteger}, raw.FullBytes[1:]...)
+		n := new(big.Int)
+		if _, err := asn1.Unmarshal(data, &n); err == nil {
+			if raw.Tag == asn1.TagEnum {
+				return ASN1Value{Type: ASN1TypeEnumerated, Value: n.String()}
+			}
+			return ASN1Value{Type: ASN1TypeInteger, Value: n.String()}
+		}
+	case asn1.TagBitString:
+		var bits asn1.BitString
+		if _, err := asn1.Unmarshal(raw.FullBytes, &bits); err == nil {
+			digits := strings.Builder{}
+			for i := 0; i < bits.BitLength; i++ {
+				digits.WriteByte('0' + byte(bits.At(i)))
+			}
+			return ASN1Value{Type: ASN1TypeBitString, Value: digits.String()}
+		}
+	case asn1.TagOctetString:
+		return ASN1Value{Type: ASN1TypeOctetString, Value: hex.EncodeToString(raw.Bytes)}
+	case asn1.TagNull:
+		return ASN1Value{Type: ASN1TypeNull}
+	case asn1.TagOID:
+		var oid asn1.ObjectIdentifier
+		if _, err := asn1.Unmarshal(raw.FullBytes, &oid); err == nil {
+			return ASN1Value{Type: ASN1TypeOID, Value: oid.String()}
+		}
+	case asn1.TagUTCTime, asn1.TagGeneralizedTime:
+		var t time.Time
+		if _, err := asn1.Unmarshal(raw.FullBytes, &t); err == nil {
+			if raw.Tag == asn1.TagUTCTime {
+				return ASN1Value{Type: ASN1TypeUTCTime, Value: t.UTC().Format(time.RFC3339)}
+			}
+			return ASN1Value{Type: ASN1TypeGeneralizedTime, Value: t.UTC().Format(time.RFC3339)}
+		}
+	}
+
+	return rawValue
+}
+
+func asn1ChildrenFromDER(data []byte) ([]ASN1Value, error) {
+	children := []ASN1Value{}
+	for len(data) > 0 {
+		var raw asn1.RawValue
+		var err error
+		data, err = asn1.Unmarshal(data, &raw)
+		if err != nil {
+			return nil, err
+		}
+		children = append(children, asn1ValueFromRaw(raw))
+	}
+	return children, nil
+}
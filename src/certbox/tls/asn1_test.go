@@ -0,0 +1,147 @@
+package tls_test
+
+import (
+	"bytes"
+	"encoding/hex"
+	"reflect"
+	"testing"
+
+	"github.com/tls-inspector/certbox/tls"
+)
+
+func extensionRequest(extensions []tls.Extension) tls.CertificateRequest {
+	return tls.CertificateRequest{
+		KeyType:            tls.KeyTypeECDSA_256,
+		SignatureAlgorithm: tls.SignatureAlgorithmSHA256,
+		Subject: tls.Name{
+			CommonName: "example.com",
+		},
+		Validity: tls.DateRange{
+			NotBefore: "2001-01-01",
+			NotAfter:  "2002-01-01",
+		},
+		Extensions: extensions,
+	}
+}
+
+func TestCustomExtensions(t *testing.T) {
+	t.Parallel()
+
+	tree := tls.ASN1Value{
+		Type: tls.ASN1TypeSequence,
+		Children: []tls.ASN1Value{
+			{Type: tls.ASN1TypeOID, Value: "1.2.3.4"},
+			{Type: tls.ASN1TypeBoolean, Value: "true"},
+			{Type: tls.ASN1TypeOctetString, Value: "cafe"},
+			{Type: tls.ASN1TypeInteger, Value: "-1337"},
+			{Type: tls.ASN1TypeIA5String, Value: "https://example.com", Tagging: tls.ASN1TaggingImplicit, Tag: 6},
+			{Type: tls.ASN1TypeUTF8String, Value: "Ünïcödé", Tagging: tls.ASN1TaggingExplicit, Tag: 1},
+			{Type: tls.ASN1TypeBitString, Value: "101"},
+			{Type: tls.ASN1TypeNull},
+			{Type: tls.ASN1TypeSet, Children: []tls.ASN1Value{{Type: tls.ASN1TypeGeneralizedTime, Value: "2050-01-01T00:00:00Z"}}},
+		},
+	}
+	extensions := []tls.Extension{
+		{OID: "1.2.3.4.5.6", Critical: true, Raw: "0403010203"},
+		{OID: "1.2.3.4.5.7", ASN1: &tree},
+		{OID: "1.2.3.4.5.8", Value: float64(42)},
+	}
+
+	certificate, err := tls.GenerateCertificate(extensionRequest(extensions), nil)
+	if err != nil {
+		t.Fatalf("Error generating certificate: %s", err.Error())
+	}
+
+	expected := map[string]string{
+		"1.2.3.4.5.6": "0403010203",
+		"1.2.3.4.5.7": "304d06032a03040101ff0402cafe0202fac7861368747470733a2f2f6578616d706c652e636f6d" +
+			"a10d0c0bc39c6ec3af63c3b664c3a9030205a005003111180f32303530303130313030303030305a",
+		"1.2.3.4.5.8": "02012a",
+	}
+	found := 0
+	for _, extension := range certificate.X509().Extensions {
+		value, ok := expected[extension.Id.String()]
+		if !ok {
+			continue
+		}
+		found++
+		if hex.EncodeToString(extension.Value) != value {
+			t.Errorf("Unexpected value for extension %s. Expected %s got %x", extension.Id, value, extension.Value)
+		}
+		if extension.Critical != (extension.Id.String() == "1.2.3.4.5.6") {
+			t.Errorf("Unexpected criticality for extension %s", extension.Id)
+		}
+	}
+	if found != len(expected) {
+		t.Fatalf("Expected %d custom extensions, found %d", len(expected), found)
+	}
+
+	clone, err := certificate.Clone()
+	if err != nil {
+		t.Fatalf("Error cloning certificate: %s", err.Error())
+	}
+	if len(clone.Extensions) != 3 {
+		t.Fatalf("Unexpected number of cloned extensions %d", len(clone.Extensions))
+	}
+	if clone.Extensions[0].Raw != "" || clone.Extensions[0].ASN1 == nil || clone.Extensions[0].ASN1.Type != tls.ASN1TypeOctetString || !clone.Extensions[0].Critical {
+		t.Errorf("Unexpected cloned raw extension %+v", clone.Extensions[0])
+	}
+	// The type of an implicitly tagged primitive value is lost, so it's cloned as an octet string with the same encoding
+	tree.Children[4] = tls.ASN1Value{Type: tls.ASN1TypeOctetString, Value: hex.EncodeToString([]byte("https://example.com")), Tagging: tls.ASN1TaggingImplicit, Tag: 6}
+	if clone.Extensions[1].ASN1 == nil || !reflect.DeepEqual(*clone.Extensions[1].ASN1, tree) {
+		t.Errorf("Cloned extension does not match.\nExpected: %+v\nGot:      %+v", tree, clone.Extensions[1].ASN1)
+	}
+	if clone.Extensions[2].Value != int64(42) {
+		t.Errorf("Unexpected cloned extension value %v", clone.Extensions[2].Value)
+	}
+
+	recreated, err := tls.GenerateCertificate(clone, nil)
+	if err != nil {
+		t.Fatalf("Error generating certificate from clone: %s", err.Error())
+	}
+	for i, extension := range certificate.X509().Extensions {
+		if !bytes.Equal(extension.Value, recreated.X509().Extensions[i].Value) && extension.Id.String() != "2.5.29.14" {
+			t.Errorf("Extension %s was not cloned exactly", extension.Id)
+		}
+	}
+}
+
+func TestCustomExtensionsUnstructured(t *testing.T) {
+	t.Parallel()
+
+	// An application class value can't be described by a type, so it must be cloned as raw hex
+	certificate, err := tls.GenerateCertificate(extensionRequest([]tls.Extension{{OID: "1.2.3.4.5.6", Raw: "4103010203"}}), nil)
+	if err != nil {
+		t.Fatalf("Error generating certificate: %s", err.Error())
+	}
+	clone, err := certificate.Clone()
+	if err != nil {
+		t.Fatalf("Error cloning certificate: %s", err.Error())
+	}
+	if len(clone.Extensions) != 1 || clone.Extensions[0].Raw != "4103010203" {
+		t.Errorf("Unexpected cloned extensions %+v", clone.Extensions)
+	}
+}
+
+func TestCustomExtensionsInvalid(t *testing.T) {
+	t.Parallel()
+
+	invalid := map[string]tls.Extension{
+		"invalid raw hex":        {OID: "1.2.3.4", Raw: "zz"},
+		"invalid boolean":        {OID: "1.2.3.4", ASN1: &tls.ASN1Value{Type: tls.ASN1TypeBoolean, Value: "yes"}},
+		"invalid integer":        {OID: "1.2.3.4", ASN1: &tls.ASN1Value{Type: tls.ASN1TypeInteger, Value: "one"}},
+		"invalid oid":            {OID: "1.2.3.4", ASN1: &tls.ASN1Value{Type: tls.ASN1TypeOID, Value: "1.x"}},
+		"invalid printable":      {OID: "1.2.3.4", ASN1: &tls.ASN1Value{Type: tls.ASN1TypePrintableString, Value: "a@b"}},
+		"invalid tagging":        {OID: "1.2.3.4", ASN1: &tls.ASN1Value{Type: tls.ASN1TypeNull, Tagging: "sideways"}},
+		"unknown type":           {OID: "1.2.3.4", ASN1: &tls.ASN1Value{Type: "float"}},
+		"invalid nested value":   {OID: "1.2.3.4", ASN1: &tls.ASN1Value{Type: tls.ASN1TypeSequence, Children: []tls.ASN1Value{{Type: tls.ASN1TypeBitString, Value: "12"}}}},
+		"fractional number":      {OID: "1.2.3.4", Value: 1.5},
+		"raw with trailing data": {OID: "1.2.3.4", ASN1: &tls.ASN1Value{Type: tls.ASN1TypeRaw, Value: "05000500"}},
+	}
+
+	for name, extension := range invalid {
+		if _, err := tls.GenerateCertificate(extensionRequest([]tls.Extension{extension}), nil); err == nil {
+			t.Errorf("No error seen when one expected for %s", name)
+		}
+	}
+}
@@ -3,8 +3,8 @@ package tls
 import (
 	"crypto"
 	"crypto/ecdsa"
+	"crypto/ed25519"
 	"crypto/elliptic"
-	"crypto/rand"
 	"crypto/rsa"
 	"crypto/sha1"
 	"crypto/x509"
@@ -12,107 +12,14 @@ import (
 	"encoding/asn1"
 	"encoding/hex"
 	"fmt"
+	"math"
 	"math/big"
 	"net"
 	"net/url"
 	"strconv"
 	"strings"
-	"time"
 )
 
-// Name describes a X.509 name object
-type Name struct {
-	Organization string
-	City         string
-	Province     string
-	Country      string
-	CommonName   string
-}
-
-func (n Name) pkix() pkix.Name {
-	name := pkix.Name{
-		CommonName: n.CommonName,
-	}
-	if n.Country != "" {
-		name.Country = []string{n.Country}
-	}
-	if n.Organization != "" {
-		name.Organization = []string{n.Organization}
-	}
-	if n.City != "" {
-		name.Locality = []string{n.City}
-	}
-	if n.Province != "" {
-		name.Province = []string{n.Province}
-	}
-	return name
-}
-
-func nameFromPkix(p pkix.Name) Name {
-	n := Name{
-		CommonName: p.CommonName,
-	}
-
-	if len(p.Organization) > 0 {
-		n.Organization = p.Organization[0]
-	}
-
-	if len(p.Locality) > 0 {
-		n.City = p.Locality[0]
-	}
-
-	if len(p.Province) > 0 {
-		n.Province = p.Province[0]
-	}
-
-	if len(p.Country) > 0 {
-		n.Country = p.Country[0]
-	}
-
-	return n
-}
-
-// DateRange describes a date range. Values must be in YYYY-mm-DD (2006-01-02) format and the time is always fixed to
-// 00:00:00UTC. Dates can be in the future or past, but the NotBefore date must always be before the NotAfter.
-type DateRange struct {
-	NotBefore string
-	NotAfter  string
-}
-
-func (d DateRange) dates() (*time.Time, *time.Time, error) {
-	notBefore, err := time.ParseInLocation(time.DateOnly, strings.Split(d.NotBefore, "T")[0], time.UTC)
-	if err != nil {
-		return nil, nil, fmt.Errorf("invalid notBefore: %s", err.Error())
-	}
-
-	notAfter, err := time.ParseInLocation(time.DateOnly, strings.Split(d.NotAfter, "T")[0], time.UTC)
-	if err != nil {
-		return nil, nil, fmt.Errorf("invalid not After: %s", err.Error())
-	}
-
-	return &notBefore, &notAfter, nil
-}
-
-func (d DateRange) mustDates() (time.Time, time.Time) {
-	nb, na, err := d.dates()
-	if err != nil {
-		panic(err.Error())
-	}
-	return *nb, *na
-}
-
-// IsValid is the values of the date range valid
-func (d DateRange) IsValid() bool {
-	nb, na, err := d.dates()
-	if err != nil {
-		return false
-	}
-	notBefore := *nb
-	notAfter := *na
-
-	return notBefore.Before(notAfter)
-}
-
 const (
 	// AlternateNameTypeDNS enum value for DNS type alternate names
 	AlternateNameTypeDNS = "dns"
@@ -187,6 +94,10 @@ func (u KeyUsage) usage() x509.KeyUsage {
 	return usage
 }
 
+func (u KeyUsage) isEmpty() bool {
+	return u.usage() == 0 && len(u.extendedUsage()) == 0 && len(u.CustomEKUs) == 0
+}
+
 func (u KeyUsage) extendedUsage() []x509.ExtKeyUsage {
 	usage := []x509.ExtKeyUsage{}
 	if u.ServerAuth {
@@ -268,14 +179,20 @@ func x509KeyUsageToInternal(usage x509.KeyUsage, eku []x509.ExtKeyUsage) (u KeyU
 const (
 	// RSA with a 2048-bit key
 	KeyTypeRSA_2048 = "rsa2048"
+	// RSA with a 3072-bit key
+	KeyTypeRSA_3072 = "rsa3072"
 	// RSA with a 4096-bit key
 	KeyTypeRSA_4096 = "rsa4096"
 	// RSA with a 8192-bit key
 	KeyTypeRSA_8192 = "rsa8192"
 	// ECDSA with a 256-bit curve
 	KeyTypeECDSA_256 = "ecc256"
-	// ECDSA with a 394-bit curve
+	// ECDSA with a 384-bit curve
 	KeyTypeECDSA_384 = "ecc384"
+	// ECDSA with a 521-bit curve
+	KeyTypeECDSA_521 = "ecc521"
+	// Ed25519. Ed25519 signatures do not use a separate hash, so the signature algorithm is ignored.
+	KeyTypeEd25519 = "ed25519"
 )
 
 const (
@@ -285,14 +202,51 @@ const (
 	SignatureAlgorithmSHA384 = "sha384"
 	// SHA 512
 	SignatureAlgorithmSHA512 = "sha512"
+	// SHA 256 with RSASSA-PSS. Only valid for RSA keys.
+	SignatureAlgorithmSHA256PSS = "sha256-pss"
+	// SHA 384 with RSASSA-PSS. Only valid for RSA keys.
+	SignatureAlgorithmSHA384PSS = "sha384-pss"
+	// SHA 512 with RSASSA-PSS. Only valid for RSA keys.
+	SignatureAlgorithmSHA512PSS = "sha512-pss"
 )
 
-// Certificate extension
+func isPSSSignatureAlgorithm(algorithm string) bool {
+	return algorithm == SignatureAlgorithmSHA256PSS || algorithm == SignatureAlgorithmSHA384PSS || algorithm == SignatureAlgorithmSHA512PSS
+}
+
+// Certificate extension. The value of the extension is taken from Raw, ASN1 or Value, in that order.
 type Extension struct {
-	OID string
+	OID      string
+	Critical bool
 	// Value must be a type supported by the go asn1 package.
-	// Most primitive types + time are supported.
+	// Most primitive types + time are supported. Whole numbers are encoded as an integer.
 	Value any
+	// Raw is the hex encoded DER value of the extension
+	Raw string
+	// ASN1 is a structured value of the extension
+	ASN1 *ASN1Value
+}
+
+// value returns the DER encoded value of this extension
+func (e Extension) value() ([]byte, error) {
+	if e.Raw != "" {
+		data, err := hex.DecodeString(e.Raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid raw value: %s", err.Error())
+		}
+		return data, nil
+	}
+
+	if e.ASN1 != nil {
+		return e.ASN1.marshal()
+	}
+
+	value := e.Value
+	// Numbers decoded from JSON are always a float64, which can't be encoded
+	if f, isFloat := value.(float64); isFloat && f == math.Trunc(f) && math.Abs(f) < math.MaxInt64 {
+		value = int64(f)
+	}
+	return asn1.Marshal(value)
 }
 
 var (
@@ -302,30 +256,73 @@ var (
 	oidExtensionAuthorityKeyId   = asn1.ObjectIdentifier([]int{2, 5, 29, 35})
 	oidExtensionBasicConstraints = asn1.ObjectIdentifier([]int{2, 5, 29, 19})
 	oidExtensionSubjectAltName   = asn1.ObjectIdentifier([]int{2, 5, 29, 17})
+	oidExtensionCRLDistPoints    = asn1.ObjectIdentifier([]int{2, 5, 29, 31})
+	oidExtensionAuthorityInfo    = asn1.ObjectIdentifier([]int{1, 3, 6, 1, 5, 5, 7, 1, 1})
+	oidExtensionNameConstraints  = asn1.ObjectIdentifier([]int{2, 5, 29, 30})
 )
 
 // CertificateRequest describes a certificate request
 type CertificateRequest struct {
-	KeyType                string
+	// Label optionally identifies this request within a batch of requests
+	Label string
+	// IssuerLabel is the label of another request within the same batch that will sign this certificate.
+	// If empty, certificate authorities are self-signed and all other certificates are signed by the root.
+	IssuerLabel string
+	// KeyType is the type of key to generate. If PrivateKey is set, KeyType may be left empty and is inferred from the key.
+	KeyType string
+	// PrivateKey is an optional PEM encoded private key to use instead of generating a new key. PKCS#1, PKCS#8 and SEC1
+	// keys are supported, as are encrypted PKCS#8 (PBES2) and legacy encrypted PEM keys.
+	PrivateKey string
+	// PrivateKeyPassword is the password for an encrypted PrivateKey
+	PrivateKeyPassword     string
 	SignatureAlgorithm     string
 	Subject                Name
 	Validity               DateRange
 	AlternateNames         []AlternateName
 	Usage                  KeyUsage
 	IsCertificateAuthority bool
-	StatusProviders        StatusProviders
-	Extensions             []Extension
+	// NameConstraints limits the names of certificates issued by this certificate authority
+	NameConstraints NameConstraints
+	// MaxPathLen is the maximum number of intermediate certificates that may follow this certificate authority. Zero
+	// means no limit unless MaxPathLenZero is set.
+	MaxPathLen     int
+	MaxPathLenZero bool
+	// Policies are included in the certificate policies extension
+	Policies []CertificatePolicy
+	// PolicyConstraints limits the policies of certificates issued by this certificate authority
+	PolicyConstraints PolicyConstraints
+	StatusProviders   StatusProviders
+	Extensions        []Extension
+	// Serial describes how the serial number is assigned. Serial numbers are random by default.
+	Serial SerialNumber
 }
 
-// StatusProviders describes providers for certificate status
+// StatusProviders describes providers for certificate status. Each provider is a list of URLs.
 type StatusProviders struct {
-	CRL  *string
-	OCSP *string
+	// CRL distribution point URLs
+	CRL []string
+	// OCSP responder URLs, included in the authority information access extension
+	OCSP []string
+	// CA issuer certificate URLs, included in the authority information access extension
+	CAIssuers []string
+}
+
+func (p StatusProviders) validate() error {
+	for _, urls := range [][]string{p.CRL, p.OCSP, p.CAIssuers} {
+		for _, u := range urls {
+			if _, err := url.ParseRequestURI(u); err != nil {
+				return fmt.Errorf("invalid status provider url %s", u)
+			}
+		}
+	}
+	return nil
 }
 
 // Certificate describes a certificate
 type Certificate struct {
+	// Serial is the decimal serial number of the certificate, and SerialHex the same serial number in hexadecimal
 	Serial               string
+	SerialHex            string
 	Subject              Name
 	CertificateAuthority bool
 	CertificateData      string
@@ -350,7 +347,7 @@ func (c Certificate) keyDataBytes() []byte {
 
 // Description return a script description of the certificate
 func (c Certificate) Description() string {
-	return fmt.Sprintf("%v", nameFromPkix(c.X509().Subject))
+	return fmt.Sprintf("%v", nameFromRaw(c.X509().RawSubject))
 }
 
 // x509 return the x509.Certificate data structure for this certificate (reading from the
@@ -374,75 +371,121 @@ func (c Certificate) PKey() crypto.PrivateKey {
 	return k
 }
 
-func (r *CertificateRequest) generate() (*x509.Certificate, crypto.PrivateKey, error) {
+func (r *CertificateRequest) generate(e *entropy) (*x509.Certificate, crypto.PrivateKey, error) {
 	var pKey crypto.PrivateKey
 	var err error
 
+	if r.PrivateKey != "" {
+		pKey, err = r.existingPrivateKey()
+		if err != nil {
+			return nil, nil, err
+		}
+	} else {
+		pKey, err = r.generatePrivateKey(e)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	pub := pKey.(crypto.Signer).Public()
+
+	tpl, err := r.template(pub, e)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return tpl, pKey, nil
+}
+
+// existingPrivateKey returns the parsed PrivateKey of this request, verifying that it matches KeyType if set. If
+// KeyType is not set, it is set to the type of the key.
+func (r *CertificateRequest) existingPrivateKey() (crypto.PrivateKey, error) {
+	pKey, err := parsePrivateKeyPEM([]byte(r.PrivateKey), r.PrivateKeyPassword)
+	if err != nil {
+		return nil, fmt.Errorf("invalid private key: %s", err.Error())
+	}
+	signer, ok := pKey.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("invalid private key: unsupported key type %T", pKey)
+	}
+	keyType, err := keyTypeForPublicKey(signer.Public())
+	if err != nil {
+		return nil, fmt.Errorf("invalid private key: %s", err.Error())
+	}
+	if r.KeyType != "" && r.KeyType != keyType {
+		return nil, fmt.Errorf("private key type %s does not match requested key type %s", keyType, r.KeyType)
+	}
+	r.KeyType = keyType
+	return pKey, nil
+}
+
+// generatePrivateKey returns a new private key for the KeyType of this request
+func (r *CertificateRequest) generatePrivateKey(e *entropy) (crypto.PrivateKey, error) {
 	switch r.KeyType {
 	case KeyTypeRSA_2048:
-		pKey, err = generateRSAKey(2048)
+		return e.generateRSAKey(2048)
+	case KeyTypeRSA_3072:
+		return e.generateRSAKey(3072)
 	case KeyTypeRSA_4096:
-		pKey, err = generateRSAKey(4096)
+		return e.generateRSAKey(4096)
 	case KeyTypeRSA_8192:
-		pKey, err = generateRSAKey(8192)
+		return e.generateRSAKey(8192)
 	case KeyTypeECDSA_256:
-		pKey, err = generateECDSAKey(elliptic.P256())
+		return e.generateECDSAKey(elliptic.P256())
 	case KeyTypeECDSA_384:
-		pKey, err = generateECDSAKey(elliptic.P384())
+		return e.generateECDSAKey(elliptic.P384())
+	case KeyTypeECDSA_521:
+		return e.generateECDSAKey(elliptic.P521())
+	case KeyTypeEd25519:
+		return e.generateEd25519Key()
 	default:
-		return nil, nil, fmt.Errorf("invalid key type")
-	}
-	if err != nil {
-		return nil, nil, err
+		return nil, fmt.Errorf("invalid key type")
 	}
-	pub := pKey.(crypto.Signer).Public()
-	serial, err := randomSerialNumber()
+}
+
+// template returns a certificate template for this request with the given subject public key. The signature algorithm
+// is not set.
+func (r *CertificateRequest) template(pub crypto.PublicKey, e *entropy) (*x509.Certificate, error) {
+	serial, err := r.Serial.serialNumber(e)
 	if err != nil {
-		return nil, nil, err
+		return nil, fmt.Errorf("invalid serial number: %s", err.Error())
 	}
 
 	publicKeyBytes, err := x509.MarshalPKIXPublicKey(pub)
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 	subjectKeyId := sha1.Sum(publicKeyBytes)
 
-	var signatureAlgorithm x509.SignatureAlgorithm
-	switch r.KeyType {
-	case KeyTypeRSA_2048, KeyTypeRSA_4096, KeyTypeRSA_8192:
-		switch r.SignatureAlgorithm {
-		case SignatureAlgorithmSHA256:
-			signatureAlgorithm = x509.SHA256WithRSA
-		case SignatureAlgorithmSHA384:
-			signatureAlgorithm = x509.SHA384WithRSA
-		case SignatureAlgorithmSHA512:
-			signatureAlgorithm = x509.SHA512WithRSA
-		default:
-			return nil, nil, fmt.Errorf("invalid signature algorithm")
-		}
-	case KeyTypeECDSA_256, KeyTypeECDSA_384:
-		switch r.SignatureAlgorithm {
-		case SignatureAlgorithmSHA256:
-			signatureAlgorithm = x509.ECDSAWithSHA256
-		case SignatureAlgorithmSHA384:
-			signatureAlgorithm = x509.ECDSAWithSHA384
-		case SignatureAlgorithmSHA512:
-			signatureAlgorithm = x509.ECDSAWithSHA512
-		default:
-			return nil, nil, fmt.Errorf("invalid signature algorithm")
-		}
+	customEku, err := r.Usage.customExtendedUsage()
+	if err != nil {
+		return nil, err
 	}
 
-	customEku, err := r.Usage.customExtendedUsage()
+	if err := r.StatusProviders.validate(); err != nil {
+		return nil, err
+	}
+
+	if !r.IsCertificateAuthority && (!r.NameConstraints.isEmpty() || r.MaxPathLen != 0 || r.MaxPathLenZero) {
+		return nil, fmt.Errorf("name constraints and path length require a certificate authority")
+	}
+	if !r.IsCertificateAuthority && !r.PolicyConstraints.isEmpty() {
+		return nil, fmt.Errorf("policy constraints require a certificate authority")
+	}
+	if r.MaxPathLen < 0 {
+		return nil, fmt.Errorf("invalid max path length %d", r.MaxPathLen)
+	}
+
+	subject, rawSubject, err := r.Subject.pkix()
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 
 	notBefore, notAfter := r.Validity.mustDates()
 
 	tpl := &x509.Certificate{
 		SerialNumber:          serial,
-		Subject:               r.Subject.pkix(),
+		Subject:               subject,
+		RawSubject:            rawSubject,
 		NotBefore:             notBefore,
 		NotAfter:              notAfter,
 		KeyUsage:              r.Usage.usage(),
@@ -450,36 +493,64 @@ func (r *CertificateRequest) generate() (*x509.Certificate, crypto.PrivateKey, e
 		SubjectKeyId:          subjectKeyId[:],
 		ExtKeyUsage:           r.Usage.extendedUsage(),
 		UnknownExtKeyUsage:    customEku,
-		SignatureAlgorithm:    signatureAlgorithm,
+		CRLDistributionPoints: r.StatusProviders.CRL,
+		OCSPServer:            r.StatusProviders.OCSP,
+		IssuingCertificateURL: r.StatusProviders.CAIssuers,
+		MaxPathLen:            r.MaxPathLen,
+		MaxPathLenZero:        r.MaxPathLenZero,
 	}
 
+	if err := r.NameConstraints.apply(tpl); err != nil {
+		return nil, err
+	}
+
+	if len(r.Policies) > 0 {
+		policies, err := certificatePoliciesExtension(r.Policies)
+		if err != nil {
+			return nil, err
+		}
+		tpl.ExtraExtensions = append(tpl.ExtraExtensions, policies)
+	}
+	policyConstraints, err := r.PolicyConstraints.extensions()
+	if err != nil {
+		return nil, err
+	}
+	tpl.ExtraExtensions = append(tpl.ExtraExtensions, policyConstraints...)
+
 	for _, extension := range r.Extensions {
 		oid, err := parseOid(extension.OID)
 		if err != nil {
-			return nil, nil, fmt.Errorf("invalid extension oid: %s", extension.OID)
+			return nil, fmt.Errorf("invalid extension oid: %s", extension.OID)
 		}
 
-		value, err := asn1.Marshal(extension.Value)
+		value, err := extension.value()
 		if err != nil {
-			return nil, nil, fmt.Errorf("invalid extension value for %s: %s", extension.OID, err.Error())
+			return nil, fmt.Errorf("invalid extension value for %s: %s", extension.OID, err.Error())
+		}
+
+		// Certificates must not include more than one instance of an extension (RFC 5280 section 4.2)
+		for _, existing := range tpl.ExtraExtensions {
+			if existing.Id.Equal(oid) {
+				return nil, fmt.Errorf("duplicate extension %s", extension.OID)
+			}
 		}
 
 		tpl.ExtraExtensions = append(tpl.ExtraExtensions, pkix.Extension{
 			Id:       oid,
-			Critical: false,
+			Critical: extension.Critical,
 			Value:    value,
 		})
 	}
 
 	for _, name := range r.AlternateNames {
 		if len(name.Value) == 0 {
-			return nil, nil, fmt.Errorf("empty alternate name value")
+			return nil, fmt.Errorf("empty alternate name value")
 		}
 
 		switch name.Type {
 		case AlternateNameTypeDNS:
 			if name.Value == " " {
-				return nil, nil, fmt.Errorf("invalid dns name value")
+				return nil, fmt.Errorf("invalid dns name value")
 			}
 			tpl.DNSNames = append(tpl.DNSNames, name.Value)
 		case AlternateNameTypeEmail:
@@ -487,27 +558,37 @@ func (r *CertificateRequest) generate() (*x509.Certificate, crypto.PrivateKey, e
 		case AlternateNameTypeIP:
 			ip := net.ParseIP(name.Value)
 			if ip == nil {
-				return nil, nil, fmt.Errorf("invalid ip address %s", name.Value)
+				return nil, fmt.Errorf("invalid ip address %s", name.Value)
 			}
 			tpl.IPAddresses = append(tpl.IPAddresses, ip)
 		case AlternateNameTypeURI:
 			u, err := url.Parse(name.Value)
 			if err != nil {
-				return nil, nil, err
+				return nil, err
 			}
 			tpl.URIs = append(tpl.URIs, u)
 		default:
-			return nil, nil, fmt.Errorf("unknown alternate name type")
+			return nil, fmt.Errorf("unknown alternate name type")
 		}
 	}
 
-	return tpl, pKey, nil
+	return tpl, nil
 }
 
 // GenerateCSR will generate a certificate signing request and private key from the given certificate request
 // and return a DER encoded CSR and PKCS8 private key
 func GenerateCSR(request CertificateRequest) ([]byte, []byte, error) {
-	tpl, pKey, err := request.generate()
+	return generateCSR(request, secureEntropy)
+}
+
+func generateCSR(request CertificateRequest, e *entropy) ([]byte, []byte, error) {
+	tpl, pKey, err := request.generate(e)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// CSRs are always signed by their own key
+	tpl.SignatureAlgorithm, err = x509SignatureAlgorithm(pKey.(crypto.Signer).Public(), request.SignatureAlgorithm)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -519,6 +600,7 @@ func GenerateCSR(request CertificateRequest) ([]byte, []byte, error) {
 		PublicKeyAlgorithm: tpl.PublicKeyAlgorithm,
 		PublicKey:          tpl.PublicKey,
 		Subject:            tpl.Subject,
+		RawSubject:         tpl.RawSubject,
 		Extensions:         tpl.Extensions,
 		ExtraExtensions:    tpl.ExtraExtensions,
 		DNSNames:           tpl.DNSNames,
@@ -532,7 +614,7 @@ func GenerateCSR(request CertificateRequest) ([]byte, []byte, error) {
 		return nil, nil, err
 	}
 
-	csr, err := x509.CreateCertificateRequest(rand.Reader, r, pKey)
+	csr, err := x509.CreateCertificateRequest(e.reader, r, e.signer(pKey))
 	if err != nil {
 		return nil, nil, err
 	}
@@ -542,7 +624,11 @@ func GenerateCSR(request CertificateRequest) ([]byte, []byte, error) {
 
 // GenerateCertificate will generate a certificate from the given certificate request
 func GenerateCertificate(request CertificateRequest, issuer *Certificate) (*Certificate, error) {
-	tpl, pKey, err := request.generate()
+	return generateCertificate(request, issuer, secureEntropy)
+}
+
+func generateCertificate(request CertificateRequest, issuer *Certificate, e *entropy) (*Certificate, error) {
+	tpl, pKey, err := request.generate(e)
 	if err != nil {
 		return nil, err
 	}
@@ -553,53 +639,169 @@ func GenerateCertificate(request CertificateRequest, issuer *Certificate) (*Cert
 		return nil, err
 	}
 
+	certificate, err := signCertificate(tpl, pub, pKey, request.SignatureAlgorithm, request.IsCertificateAuthority, issuer, e)
+	if err != nil {
+		return nil, err
+	}
+	certificate.KeyData = hex.EncodeToString(pKeyBytes)
+	return certificate, nil
+}
+
+// finishTemplate sets the fields of the template that depend on how it is signed: the signature algorithm for the
+// signing key, the issuer name and authority key identifier, and whether the certificate is a certificate authority.
+// Issuer is nil for a self-signed certificate, which is always a certificate authority.
+func finishTemplate(tpl *x509.Certificate, signingKey crypto.PublicKey, signatureAlgorithm string, isCertificateAuthority bool, issuer *x509.Certificate) error {
+	var err error
+	tpl.SignatureAlgorithm, err = x509SignatureAlgorithm(signingKey, signatureAlgorithm)
+	if err != nil {
+		return err
+	}
+
 	if issuer != nil {
-		issuerPublicKey := issuer.PKey().(crypto.Signer).Public()
-		issuerPublicKeyBytes, err := x509.MarshalPKIXPublicKey(issuerPublicKey)
+		issuerPublicKeyBytes, err := x509.MarshalPKIXPublicKey(issuer.PublicKey)
 		if err != nil {
-			return nil, err
+			return err
 		}
 		authorityKeyId := sha1.Sum(issuerPublicKeyBytes)
 
-		tpl.Issuer = issuer.X509().Subject
+		tpl.Issuer = issuer.Subject
 		tpl.AuthorityKeyId = authorityKeyId[:]
-	} else {
-		tpl.IsCA = true
+	}
+	tpl.IsCA = issuer == nil || isCertificateAuthority
+	return nil
+}
+
+// signCertificate will sign the given template with the issuer, or with pKey if issuer is nil, and return a
+// certificate without any key data. The signature algorithm is chosen based on the key of the signer, not the subject.
+func signCertificate(tpl *x509.Certificate, pub crypto.PublicKey, pKey crypto.PrivateKey, signatureAlgorithm string, isCertificateAuthority bool, issuer *Certificate, e *entropy) (*Certificate, error) {
+	signer := pKey
+	if issuer != nil {
+		if issuer.KeyData == "" {
+			return nil, fmt.Errorf("issuer has no private key")
+		}
+		signer = issuer.PKey()
+	}
+
+	var issuerX *x509.Certificate
+	if issuer != nil {
+		issuerX = issuer.X509()
+	}
+	if err := finishTemplate(tpl, signer.(crypto.Signer).Public(), signatureAlgorithm, isCertificateAuthority, issuerX); err != nil {
+		return nil, err
+	}
+
+	if issuer != nil {
+		if err := checkConstraints(tpl, tpl.IsCA, []*x509.Certificate{issuer.X509()}); err != nil {
+			return nil, err
+		}
 	}
 
 	certificate := Certificate{
-		Serial:               tpl.SerialNumber.String(),
-		CertificateAuthority: issuer == nil,
-		KeyData:              hex.EncodeToString(pKeyBytes),
-		Subject:              request.Subject,
+		CertificateAuthority: tpl.IsCA,
 	}
+	certificate.setSerial(tpl.SerialNumber)
 
 	var certBytes []byte
+	var err error
 	if issuer == nil {
-		certBytes, err = x509.CreateCertificate(rand.Reader, tpl, tpl, pub, pKey)
+		certBytes, err = x509.CreateCertificate(e.reader, tpl, tpl, pub, e.signer(pKey))
 		if err != nil {
 			return nil, err
 		}
 	} else {
-		certBytes, err = x509.CreateCertificate(rand.Reader, tpl, issuer.X509(), pub, issuer.PKey())
+		certBytes, err = x509.CreateCertificate(e.reader, tpl, issuer.X509(), pub, e.signer(issuer.PKey()))
 		if err != nil {
 			return nil, err
 		}
 	}
 
 	certificate.CertificateData = hex.EncodeToString(certBytes)
+	certificate.Subject = nameFromRaw(certificate.X509().RawSubject)
 	return &certificate, nil
 }
 
-func randomSerialNumber() (*big.Int, error) {
-	serialNumberLimit := new(big.Int).Lsh(big.NewInt(1), 128)
-	return rand.Int(rand.Reader, serialNumberLimit)
+// x509SignatureAlgorithm returns the signature algorithm to use for the given signing public key and hash algorithm
+func x509SignatureAlgorithm(pub crypto.PublicKey, algorithm string) (x509.SignatureAlgorithm, error) {
+	if _, isRSA := pub.(*rsa.PublicKey); isPSSSignatureAlgorithm(algorithm) && !isRSA {
+		return x509.UnknownSignatureAlgorithm, fmt.Errorf("signature algorithm %s requires an RSA signing key, not %s", algorithm, publicKeyAlgorithmName(pub))
+	}
+
+	switch pub.(type) {
+	case *rsa.PublicKey:
+		switch algorithm {
+		case SignatureAlgorithmSHA256:
+			return x509.SHA256WithRSA, nil
+		case SignatureAlgorithmSHA384:
+			return x509.SHA384WithRSA, nil
+		case SignatureAlgorithmSHA512:
+			return x509.SHA512WithRSA, nil
+		case SignatureAlgorithmSHA256PSS:
+			return x509.SHA256WithRSAPSS, nil
+		case SignatureAlgorithmSHA384PSS:
+			return x509.SHA384WithRSAPSS, nil
+		case SignatureAlgorithmSHA512PSS:
+			return x509.SHA512WithRSAPSS, nil
+		}
+	case *ecdsa.PublicKey:
+		switch algorithm {
+		case SignatureAlgorithmSHA256:
+			return x509.ECDSAWithSHA256, nil
+		case SignatureAlgorithmSHA384:
+			return x509.ECDSAWithSHA384, nil
+		case SignatureAlgorithmSHA512:
+			return x509.ECDSAWithSHA512, nil
+		}
+	case ed25519.PublicKey:
+		return x509.PureEd25519, nil
+	default:
+		return x509.UnknownSignatureAlgorithm, fmt.Errorf("unsupported signing key type %s", publicKeyAlgorithmName(pub))
+	}
+	return x509.UnknownSignatureAlgorithm, fmt.Errorf("invalid signature algorithm '%s' for %s signing key", algorithm, publicKeyAlgorithmName(pub))
+}
+
+// publicKeyAlgorithmName returns a human readable name for the algorithm of the given public key
+func publicKeyAlgorithmName(pub crypto.PublicKey) string {
+	switch pub.(type) {
+	case *rsa.PublicKey:
+		return "RSA"
+	case *ecdsa.PublicKey:
+		return "ECDSA"
+	case ed25519.PublicKey:
+		return "Ed25519"
+	}
+	return fmt.Sprintf("%T", pub)
 }
 
-func generateRSAKey(length int) (crypto.PrivateKey, error) {
-	return rsa.GenerateKey(rand.Reader, length)
+// signatureAlgorithmName returns the signature algorithm enum value for the given x509 signature algorithm, or an
+// empty string if not supported
+func signatureAlgorithmName(algorithm x509.SignatureAlgorithm) string {
+	switch algorithm {
+	case x509.SHA256WithRSA, x509.ECDSAWithSHA256:
+		return SignatureAlgorithmSHA256
+	case x509.SHA384WithRSA, x509.ECDSAWithSHA384:
+		return SignatureAlgorithmSHA384
+	case x509.SHA512WithRSA, x509.ECDSAWithSHA512, x509.PureEd25519: // Ed25519 uses SHA-512 internally
+		return SignatureAlgorithmSHA512
+	case x509.SHA256WithRSAPSS:
+		return SignatureAlgorithmSHA256PSS
+	case x509.SHA384WithRSAPSS:
+		return SignatureAlgorithmSHA384PSS
+	case x509.SHA512WithRSAPSS:
+		return SignatureAlgorithmSHA512PSS
+	}
+	return ""
 }
 
-func generateECDSAKey(curve elliptic.Curve) (crypto.PrivateKey, error) {
-	return ecdsa.GenerateKey(curve, rand.Reader)
+// ParseSerial will parse a decimal serial number, or a hexadecimal serial number when prefixed with 0x
+func ParseSerial(value string) (*big.Int, error) {
+	serial, ok := new(big.Int), false
+	if strings.HasPrefix(value, "0x") {
+		serial, ok = serial.SetString(value[2:], 16)
+	} else {
+		serial, ok = serial.SetString(value, 10)
+	}
+	if !ok {
+		return nil, fmt.Errorf("invalid serial number %s", value)
+	}
+	return serial, nil
 }
@@ -603,3 +603,216 @@ func TestExtensions(t *testing.T) {
 		t.Fatalf("Did not find time extension")
 	}
 }
+
+func TestStatusProviders(t *testing.T) {
+	t.Parallel()
+
+	statusProviders := tls.StatusProviders{
+		CRL:       []string{"http://crl1.example.com/root.crl", "http://crl2.example.com/root.crl"},
+		OCSP:      []string{"http://ocsp1.example.com", "http://ocsp2.example.com"},
+		CAIssuers: []string{"http://example.com/root.crt"},
+	}
+
+	cert, err := tls.GenerateCertificate(tls.CertificateRequest{
+		KeyType: tls.KeyTypeECDSA_256,
+		Subject: tls.Name{
+			Organization: "example.com",
+			Country:      "CA",
+			CommonName:   "example.com Example Root",
+		},
+		Validity: tls.DateRange{
+			NotBefore: "2001-01-01",
+			NotAfter:  "2002-01-01",
+		},
+		StatusProviders:        statusProviders,
+		IsCertificateAuthority: true,
+		SignatureAlgorithm:     tls.SignatureAlgorithmSHA256,
+	}, nil)
+	if err != nil {
+		t.Fatalf("Error generating certificate: %s", err.Error())
+	}
+
+	x := cert.X509()
+	if strings.Join(x.CRLDistributionPoints, ",") != strings.Join(statusProviders.CRL, ",") {
+		t.Errorf("Unexpected CRL distribution points. Expected '%s' got '%s'", statusProviders.CRL, x.CRLDistributionPoints)
+	}
+	if strings.Join(x.OCSPServer, ",") != strings.Join(statusProviders.OCSP, ",") {
+		t.Errorf("Unexpected OCSP servers. Expected '%s' got '%s'", statusProviders.OCSP, x.OCSPServer)
+	}
+	if strings.Join(x.IssuingCertificateURL, ",") != strings.Join(statusProviders.CAIssuers, ",") {
+		t.Errorf("Unexpected CA issuers. Expected '%s' got '%s'", statusProviders.CAIssuers, x.IssuingCertificateURL)
+	}
+
+	request, err := cert.Clone()
+	if err != nil {
+		t.Fatalf("Error cloning certificate: %s", err.Error())
+	}
+	if strings.Join(request.StatusProviders.CRL, ",") != strings.Join(statusProviders.CRL, ",") {
+		t.Errorf("Unexpected cloned CRL distribution points. Expected '%s' got '%s'", statusProviders.CRL, request.StatusProviders.CRL)
+	}
+	if strings.Join(request.StatusProviders.OCSP, ",") != strings.Join(statusProviders.OCSP, ",") {
+		t.Errorf("Unexpected cloned OCSP servers. Expected '%s' got '%s'", statusProviders.OCSP, request.StatusProviders.OCSP)
+	}
+	if strings.Join(request.StatusProviders.CAIssuers, ",") != strings.Join(statusProviders.CAIssuers, ",") {
+		t.Errorf("Unexpected cloned CA issuers. Expected '%s' got '%s'", statusProviders.CAIssuers, request.StatusProviders.CAIssuers)
+	}
+
+	_, err = tls.GenerateCertificate(tls.CertificateRequest{
+		KeyType: tls.KeyTypeECDSA_256,
+		Subject: tls.Name{
+			CommonName: "example.com Example Root",
+		},
+		Validity: tls.DateRange{
+			NotBefore: "2001-01-01",
+			NotAfter:  "2002-01-01",
+		},
+		StatusProviders: tls.StatusProviders{
+			OCSP: []string{"not a url"},
+		},
+		IsCertificateAuthority: true,
+		SignatureAlgorithm:     tls.SignatureAlgorithmSHA256,
+	}, nil)
+	if err == nil {
+		t.Errorf("No error seen when one expected for invalid status provider url")
+	}
+}
+
+func TestSignatureAlgorithmPSS(t *testing.T) {
+	t.Parallel()
+
+	algorithms := map[string]x509.SignatureAlgorithm{
+		tls.SignatureAlgorithmSHA256PSS: x509.SHA256WithRSAPSS,
+		tls.SignatureAlgorithmSHA384PSS: x509.SHA384WithRSAPSS,
+		tls.SignatureAlgorithmSHA512PSS: x509.SHA512WithRSAPSS,
+	}
+
+	for algorithm, expected := range algorithms {
+		request := tls.CertificateRequest{
+			KeyType:            tls.KeyTypeRSA_2048,
+			SignatureAlgorithm: algorithm,
+			Subject: tls.Name{
+				CommonName: "example.com Example Root",
+			},
+			Validity: tls.DateRange{
+				NotBefore: "2001-01-01",
+				NotAfter:  "2002-01-01",
+			},
+			IsCertificateAuthority: true,
+		}
+
+		cert, err := tls.GenerateCertificate(request, nil)
+		if err != nil {
+			t.Fatalf("Error generating certificate: %s", err.Error())
+		}
+		if cert.X509().SignatureAlgorithm != expected {
+			t.Errorf("Unexpected signature algorithm. Expected '%s' got '%s'", expected, cert.X509().SignatureAlgorithm)
+		}
+		cloned, err := cert.Clone()
+		if err != nil {
+			t.Fatalf("Error cloning certificate: %s", err.Error())
+		}
+		if cloned.SignatureAlgorithm != algorithm {
+			t.Errorf("Unexpected cloned signature algorithm. Expected '%s' got '%s'", algorithm, cloned.SignatureAlgorithm)
+		}
+
+		csrData, _, err := tls.GenerateCSR(request)
+		if err != nil {
+			t.Fatalf("Error generating CSR: %s", err.Error())
+		}
+		csr, err := x509.ParseCertificateRequest(csrData)
+		if err != nil {
+			t.Fatalf("Error parsing CSR: %s", err.Error())
+		}
+		if csr.SignatureAlgorithm != expected {
+			t.Errorf("Unexpected CSR signature algorithm. Expected '%s' got '%s'", expected, csr.SignatureAlgorithm)
+		}
+	}
+
+	for _, keyType := range []string{tls.KeyTypeECDSA_256, tls.KeyTypeEd25519} {
+		_, err := tls.GenerateCertificate(tls.CertificateRequest{
+			KeyType:            keyType,
+			SignatureAlgorithm: tls.SignatureAlgorithmSHA256PSS,
+			Subject: tls.Name{
+				CommonName: "example.com Example Root",
+			},
+			Validity: tls.DateRange{
+				NotBefore: "2001-01-01",
+				NotAfter:  "2002-01-01",
+			},
+			IsCertificateAuthority: true,
+		}, nil)
+		if err == nil {
+			t.Errorf("No error seen when one expected for PSS with %s key", keyType)
+		}
+	}
+}
+
+func TestMixedKeyTypes(t *testing.T) {
+	t.Parallel()
+
+	keyTypes := []string{
+		tls.KeyTypeRSA_2048,
+		tls.KeyTypeRSA_3072,
+		tls.KeyTypeECDSA_256,
+		tls.KeyTypeECDSA_384,
+		tls.KeyTypeECDSA_521,
+		tls.KeyTypeEd25519,
+	}
+
+	request := func(keyType string, signatureAlgorithm string, isCA bool) tls.CertificateRequest {
+		return tls.CertificateRequest{
+			KeyType:            keyType,
+			SignatureAlgorithm: signatureAlgorithm,
+			Subject: tls.Name{
+				CommonName: keyType,
+			},
+			Validity: tls.DateRange{
+				NotBefore: "2001-01-01",
+				NotAfter:  "2002-01-01",
+			},
+			Usage: tls.KeyUsage{
+				DigitalSignature: true,
+				CertSign:         isCA,
+			},
+			IsCertificateAuthority: isCA,
+		}
+	}
+
+	roots := map[string]*tls.Certificate{}
+	for _, keyType := range keyTypes {
+		root, err := tls.GenerateCertificate(request(keyType, tls.SignatureAlgorithmSHA384, true), nil)
+		if err != nil {
+			t.Fatalf("Error generating %s root: %s", keyType, err.Error())
+		}
+		roots[keyType] = root
+	}
+
+	for _, rootKeyType := range keyTypes {
+		for _, leafKeyType := range keyTypes {
+			root := roots[rootKeyType]
+			leaf, err := tls.GenerateCertificate(request(leafKeyType, tls.SignatureAlgorithmSHA384, false), root)
+			if err != nil {
+				t.Errorf("Error generating %s leaf signed by %s root: %s", leafKeyType, rootKeyType, err.Error())
+				continue
+			}
+			if err := leaf.X509().CheckSignatureFrom(root.X509()); err != nil {
+				t.Errorf("Invalid signature on %s leaf signed by %s root: %s", leafKeyType, rootKeyType, err.Error())
+			}
+		}
+	}
+
+	// PSS depends on the issuer key, not the subject key
+	if _, err := tls.GenerateCertificate(request(tls.KeyTypeECDSA_256, tls.SignatureAlgorithmSHA256PSS, false), roots[tls.KeyTypeRSA_2048]); err != nil {
+		t.Errorf("Error generating ECDSA leaf with PSS signed by RSA root: %s", err.Error())
+	}
+	if _, err := tls.GenerateCertificate(request(tls.KeyTypeRSA_2048, tls.SignatureAlgorithmSHA256PSS, false), roots[tls.KeyTypeECDSA_256]); err == nil {
+		t.Errorf("No error seen when one expected for PSS with ECDSA root")
+	}
+
+	// Issuer without a private key
+	issuer := *roots[tls.KeyTypeECDSA_256]
+	issuer.KeyData = ""
+	if _, err := tls.GenerateCertificate(request(tls.KeyTypeECDSA_256, tls.SignatureAlgorithmSHA256, false), &issuer); err == nil {
+		t.Errorf("No error seen when one expected for issuer without key")
+	}
+}
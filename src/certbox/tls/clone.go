@@ -1,65 +1,33 @@
 package tls
 
 import (
-	"crypto/ecdsa"
-	"crypto/rsa"
-	"crypto/x509"
+	"bytes"
 	"crypto/x509/pkix"
 	"encoding/asn1"
-	"fmt"
+	"encoding/hex"
 	"time"
 )
 
-// Clone return a certificate request that would match this certificate
-func (c Certificate) Clone() CertificateRequest {
+// Clone return a certificate request that would match this certificate. An error is returned if the certificate uses
+// a key type that can't be generated.
+func (c Certificate) Clone() (CertificateRequest, error) {
 	csr := CertificateRequest{}
 
 	x := c.X509()
 
-	algorithm := x.PublicKeyAlgorithm
-	switch algorithm {
-	case x509.RSA:
-		publicKey := x.PublicKey.(*rsa.PublicKey)
-		size := publicKey.Size()
-		switch size {
-		case 256:
-			csr.KeyType = KeyTypeRSA_2048
-		case 512:
-			csr.KeyType = KeyTypeRSA_4096
-		case 1024:
-			csr.KeyType = KeyTypeRSA_8192
-		default:
-			panic(fmt.Sprintf("Unsupported RSA key length: %d", size))
-		}
-	case x509.ECDSA:
-		publicKey := x.PublicKey.(*ecdsa.PublicKey)
-		size := publicKey.Params().BitSize
-		switch size {
-		case 256:
-			csr.KeyType = KeyTypeECDSA_256
-		case 384:
-			csr.KeyType = KeyTypeECDSA_384
-		default:
-			panic(fmt.Sprintf("Unsupported ECC curve size: %d", size))
-		}
-	default:
-		panic(fmt.Sprintf("Unsupported public key algorithm: %d", algorithm))
+	keyType, err := keyTypeForPublicKey(x.PublicKey)
+	if err != nil {
+		return csr, err
 	}
-	csr.Subject = c.Subject
+	csr.KeyType = keyType
+	csr.Subject = nameFromRaw(x.RawSubject)
 	csr.Validity = DateRange{
-		NotBefore: x.NotBefore.UTC().Format(time.DateOnly),
-		NotAfter:  x.NotAfter.UTC().Format(time.DateOnly),
+		NotBefore: formatDate(x.NotBefore),
+		NotAfter:  formatDate(x.NotAfter),
 	}
 	csr.AlternateNames = []AlternateName{}
 
-	switch x.SignatureAlgorithm {
-	case x509.SHA256WithRSA, x509.ECDSAWithSHA256:
-		csr.SignatureAlgorithm = SignatureAlgorithmSHA256
-	case x509.SHA384WithRSA, x509.ECDSAWithSHA384:
-		csr.SignatureAlgorithm = SignatureAlgorithmSHA384
-	case x509.SHA512WithRSA, x509.ECDSAWithSHA512:
-		csr.SignatureAlgorithm = SignatureAlgorithmSHA512
-	}
+	csr.SignatureAlgorithm = signatureAlgorithmName(x.SignatureAlgorithm)
 
 	for _, dns := range x.DNSNames {
 		csr.AlternateNames = append(csr.AlternateNames, AlternateName{
@@ -87,45 +55,75 @@ func (c Certificate) Clone() CertificateRequest {
 	}
 	csr.Usage = x509KeyUsageToInternal(x.KeyUsage, x.ExtKeyUsage)
 	csr.IsCertificateAuthority = x.IsCA
+	csr.NameConstraints = nameConstraintsFromX509(x)
+	if x.MaxPathLen > 0 {
+		csr.MaxPathLen = x.MaxPathLen
+	}
+	csr.MaxPathLenZero = x.MaxPathLen == 0 && x.MaxPathLenZero
 
+	// Policy extensions that can't be described by Policies or PolicyConstraints are cloned as custom extensions
+	unsupported := map[string]bool{}
 	for _, ext := range x.Extensions {
-		if isKnownExtensionOid(ext) {
+		if !ext.Id.Equal(oidExtensionCertificatePolicies) {
 			continue
 		}
-
-		oid := ext.Id.String()
-		var object any
-		if _, err := asn1.Unmarshal(ext.Value, &object); err != nil {
-			panic(fmt.Sprintf("Unsupported extension value for oid %s: %s", oid, err.Error()))
+		policies, err := certificatePoliciesFromExtension(ext.Value)
+		if err != nil {
+			unsupported[ext.Id.String()] = true
+			continue
 		}
+		csr.Policies = policies
+	}
+	policyConstraints, err := policyConstraintsFromExtensions(x.Extensions)
+	if err != nil {
+		unsupported[oidExtensionPolicyMappings.String()] = true
+		unsupported[oidExtensionPolicyConstraints.String()] = true
+		unsupported[oidExtensionInhibitAnyPolicy.String()] = true
+	} else {
+		csr.PolicyConstraints = policyConstraints
+	}
+	csr.StatusProviders = StatusProviders{
+		CRL:       x.CRLDistributionPoints,
+		OCSP:      x.OCSPServer,
+		CAIssuers: x.IssuingCertificateURL,
+	}
 
-		isValidType := false
-		if _, isStr := object.(string); isStr {
-			isValidType = true
-		}
-		if _, isNumber := object.(int64); isNumber {
-			isValidType = true
-		}
-		if _, isNumber := object.(uint64); isNumber {
-			isValidType = true
-		}
-		if _, isNumber := object.(float64); isNumber {
-			isValidType = true
-		}
-		if _, isTime := object.(time.Time); isTime {
-			isValidType = true
-		}
-		if !isValidType {
+	for _, ext := range x.Extensions {
+		if isKnownExtensionOid(ext) && !unsupported[ext.Id.String()] {
 			continue
 		}
+		csr.Extensions = append(csr.Extensions, extensionFromPkix(ext))
+	}
 
-		csr.Extensions = append(csr.Extensions, Extension{
-			OID:   oid,
-			Value: object,
-		})
+	return csr, nil
+}
+
+// extensionFromPkix returns an extension that will encode to the same value as ext. Primitive values are described
+// by Value, structured values by ASN1 and anything else by Raw.
+func extensionFromPkix(ext pkix.Extension) Extension {
+	extension := Extension{
+		OID:      ext.Id.String(),
+		Critical: ext.Critical,
 	}
 
-	return csr
+	var object any
+	if _, err := asn1.Unmarshal(ext.Value, &object); err == nil {
+		switch object.(type) {
+		case string, int64, uint64, float64, time.Time:
+			if encoded, err := asn1.Marshal(object); err == nil && bytes.Equal(encoded, ext.Value) {
+				extension.Value = object
+				return extension
+			}
+		}
+	}
+
+	value, err := asn1ValueFromDER(ext.Value)
+	if err != nil || (value.Type == ASN1TypeRaw && value.Tagging == "") {
+		extension.Raw = hex.EncodeToString(ext.Value)
+		return extension
+	}
+	extension.ASN1 = &value
+	return extension
 }
 
 func isKnownExtensionOid(ext pkix.Extension) bool {
@@ -134,5 +132,12 @@ func isKnownExtensionOid(ext pkix.Extension) bool {
 		ext.Id.Equal(oidExtensionExtendedKeyUsage) ||
 		ext.Id.Equal(oidExtensionAuthorityKeyId) ||
 		ext.Id.Equal(oidExtensionBasicConstraints) ||
-		ext.Id.Equal(oidExtensionSubjectAltName)
+		ext.Id.Equal(oidExtensionSubjectAltName) ||
+		ext.Id.Equal(oidExtensionCRLDistPoints) ||
+		ext.Id.Equal(oidExtensionAuthorityInfo) ||
+		ext.Id.Equal(oidExtensionNameConstraints) ||
+		ext.Id.Equal(oidExtensionCertificatePolicies) ||
+		ext.Id.Equal(oidExtensionPolicyMappings) ||
+		ext.Id.Equal(oidExtensionPolicyConstraints) ||
+		ext.Id.Equal(oidExtensionInhibitAnyPolicy)
 }
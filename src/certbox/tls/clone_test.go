@@ -1,6 +1,11 @@
 package tls_test
 
 import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
 	"testing"
 	"time"
 
@@ -180,7 +185,10 @@ func TestCloneRSA2048(t *testing.T) {
 		t.Fatalf("Error importing key: %s", err.Error())
 	}
 
-	request := certificate.Clone()
+	request, err := certificate.Clone()
+	if err != nil {
+		t.Fatalf("Error cloning certificate: %s", err.Error())
+	}
 	if request.KeyType != tls.KeyTypeRSA_2048 {
 		t.Errorf("Incorrect key type. Expected '%s' got '%s'", tls.KeyTypeRSA_2048, request.KeyType)
 	}
@@ -194,7 +202,10 @@ func TestCloneRSA4096(t *testing.T) {
 		t.Fatalf("Error importing key: %s", err.Error())
 	}
 
-	request := certificate.Clone()
+	request, err := certificate.Clone()
+	if err != nil {
+		t.Fatalf("Error cloning certificate: %s", err.Error())
+	}
 	if request.KeyType != tls.KeyTypeRSA_4096 {
 		t.Errorf("Incorrect key type. Expected '%s' got '%s'", tls.KeyTypeRSA_4096, request.KeyType)
 	}
@@ -208,7 +219,10 @@ func TestCloneRSA8192(t *testing.T) {
 		t.Fatalf("Error importing key: %s", err.Error())
 	}
 
-	request := certificate.Clone()
+	request, err := certificate.Clone()
+	if err != nil {
+		t.Fatalf("Error cloning certificate: %s", err.Error())
+	}
 	if request.KeyType != tls.KeyTypeRSA_8192 {
 		t.Errorf("Incorrect key type. Expected '%s' got '%s'", tls.KeyTypeRSA_8192, request.KeyType)
 	}
@@ -222,7 +236,10 @@ func TestCloneECDSA256(t *testing.T) {
 		t.Fatalf("Error importing key: %s", err.Error())
 	}
 
-	request := certificate.Clone()
+	request, err := certificate.Clone()
+	if err != nil {
+		t.Fatalf("Error cloning certificate: %s", err.Error())
+	}
 	if request.KeyType != tls.KeyTypeECDSA_256 {
 		t.Errorf("Incorrect key type. Expected '%s' got '%s'", tls.KeyTypeECDSA_256, request.KeyType)
 	}
@@ -236,12 +253,42 @@ func TestCloneECDSA384(t *testing.T) {
 		t.Fatalf("Error importing key: %s", err.Error())
 	}
 
-	request := certificate.Clone()
+	request, err := certificate.Clone()
+	if err != nil {
+		t.Fatalf("Error cloning certificate: %s", err.Error())
+	}
 	if request.KeyType != tls.KeyTypeECDSA_384 {
 		t.Errorf("Incorrect key type. Expected '%s' got '%s'", tls.KeyTypeECDSA_384, request.KeyType)
 	}
 }
 
+func TestCloneUnsupportedKeyType(t *testing.T) {
+	t.Parallel()
+
+	pKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatalf("Error generating RSA key: %s", err.Error())
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "example.com"},
+		NotBefore:    time.Date(2001, 1, 1, 0, 0, 0, 0, time.UTC),
+		NotAfter:     time.Date(2002, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+	data, err := x509.CreateCertificate(rand.Reader, template, template, pKey.Public(), pKey)
+	if err != nil {
+		t.Fatalf("Error generating certificate: %s", err.Error())
+	}
+	certificate, err := tls.ImportDERCertificate(data)
+	if err != nil {
+		t.Fatalf("Error importing certificate: %s", err.Error())
+	}
+
+	if _, err := certificate.Clone(); err == nil {
+		t.Errorf("No error seen when one expected for RSA-1024 key")
+	}
+}
+
 func TestCloneCertWithExt(t *testing.T) {
 	t.Parallel()
 
@@ -250,7 +297,10 @@ func TestCloneCertWithExt(t *testing.T) {
 		t.Fatalf("Error importing key: %s", err.Error())
 	}
 
-	request := certificate.Clone()
+	request, err := certificate.Clone()
+	if err != nil {
+		t.Fatalf("Error cloning certificate: %s", err.Error())
+	}
 	foundStringExtension := false
 	foundNumberExtension := false
 	foundTimeExtension := false
@@ -309,3 +359,42 @@ func TestCloneCertWithExt(t *testing.T) {
 		t.Fatalf("Did not find time extension")
 	}
 }
+
+func TestCloneGeneratedKeyTypes(t *testing.T) {
+	t.Parallel()
+
+	keyTypes := []string{
+		tls.KeyTypeRSA_3072,
+		tls.KeyTypeECDSA_521,
+		tls.KeyTypeEd25519,
+	}
+
+	for _, keyType := range keyTypes {
+		certificate, err := tls.GenerateCertificate(tls.CertificateRequest{
+			KeyType:            keyType,
+			SignatureAlgorithm: tls.SignatureAlgorithmSHA256,
+			Subject: tls.Name{
+				CommonName: "example.com Example Root",
+			},
+			Validity: tls.DateRange{
+				NotBefore: "2001-01-01",
+				NotAfter:  "2002-01-01",
+			},
+			IsCertificateAuthority: true,
+		}, nil)
+		if err != nil {
+			t.Fatalf("Error generating %s certificate: %s", keyType, err.Error())
+		}
+
+		request, err := certificate.Clone()
+		if err != nil {
+			t.Fatalf("Error cloning certificate: %s", err.Error())
+		}
+		if request.KeyType != keyType {
+			t.Errorf("Incorrect key type. Expected '%s' got '%s'", keyType, request.KeyType)
+		}
+		if _, err := tls.GenerateCertificate(request, nil); err != nil {
+			t.Errorf("Error generating certificate from %s clone: %s", keyType, err.Error())
+		}
+	}
+}
@@ -0,0 +1,207 @@
+package tls
+
+import (
+	"crypto/x509"
+	"fmt"
+	"net"
+	"strings"
+)
+
+// NameConstraints describes the name constraints of a certificate authority as defined in RFC 5280 section 4.2.1.10.
+//
+// DNS and URI domain constraints match the domain and all of its subdomains, or only subdomains if the constraint
+// begins with a period. Email constraints match an exact mailbox if they contain an @, otherwise all mailboxes on the
+// host, or on any subdomain if the constraint begins with a period. IP ranges are in CIDR notation.
+type NameConstraints struct {
+	// Critical marks the name constraints extension as critical, as recommended by RFC 5280
+	Critical                bool
+	PermittedDNSDomains     []string
+	ExcludedDNSDomains      []string
+	PermittedEmailAddresses []string
+	ExcludedEmailAddresses  []string
+	PermittedIPRanges       []string
+	ExcludedIPRanges        []string
+	PermittedURIDomains     []string
+	ExcludedURIDomains      []string
+}
+
+func (c NameConstraints) isEmpty() bool {
+	return len(c.PermittedDNSDomains) == 0 && len(c.ExcludedDNSDomains) == 0 &&
+		len(c.PermittedEmailAddresses) == 0 && len(c.ExcludedEmailAddresses) == 0 &&
+		len(c.PermittedIPRanges) == 0 && len(c.ExcludedIPRanges) == 0 &&
+		len(c.PermittedURIDomains) == 0 && len(c.ExcludedURIDomains) == 0
+}
+
+// apply sets the name constraints on the given certificate template
+func (c NameConstraints) apply(tpl *x509.Certificate) error {
+	for _, values := range [][]string{c.PermittedDNSDomains, c.ExcludedDNSDomains, c.PermittedEmailAddresses, c.ExcludedEmailAddresses, c.PermittedURIDomains, c.ExcludedURIDomains} {
+		for _, value := range values {
+			if value == "" {
+				return fmt.Errorf("empty name constraint value")
+			}
+		}
+	}
+
+	permittedIPRanges, err := parseIPRanges(c.PermittedIPRanges)
+	if err != nil {
+		return err
+	}
+	excludedIPRanges, err := parseIPRanges(c.ExcludedIPRanges)
+	if err != nil {
+		return err
+	}
+
+	tpl.PermittedDNSDomainsCritical = c.Critical
+	tpl.PermittedDNSDomains = c.PermittedDNSDomains
+	tpl.ExcludedDNSDomains = c.ExcludedDNSDomains
+	tpl.PermittedEmailAddresses = c.PermittedEmailAddresses
+	tpl.ExcludedEmailAddresses = c.ExcludedEmailAddresses
+	tpl.PermittedIPRanges = permittedIPRanges
+	tpl.ExcludedIPRanges = excludedIPRanges
+	tpl.PermittedURIDomains = c.PermittedURIDomains
+	tpl.ExcludedURIDomains = c.ExcludedURIDomains
+	return nil
+}
+
+func nameConstraintsFromX509(x *x509.Certificate) NameConstraints {
+	ipRanges := func(ranges []*net.IPNet) []string {
+		values := []string{}
+		for _, r := range ranges {
+			values = append(values, r.String())
+		}
+		return values
+	}
+
+	return NameConstraints{
+		Critical:                x.PermittedDNSDomainsCritical,
+		PermittedDNSDomains:     x.PermittedDNSDomains,
+		ExcludedDNSDomains:      x.ExcludedDNSDomains,
+		PermittedEmailAddresses: x.PermittedEmailAddresses,
+		ExcludedEmailAddresses:  x.ExcludedEmailAddresses,
+		PermittedIPRanges:       ipRanges(x.PermittedIPRanges),
+		ExcludedIPRanges:        ipRanges(x.ExcludedIPRanges),
+		PermittedURIDomains:     x.PermittedURIDomains,
+		ExcludedURIDomains:      x.ExcludedURIDomains,
+	}
+}
+
+func parseIPRanges(values []string) ([]*net.IPNet, error) {
+	ranges := []*net.IPNet{}
+	for _, value := range values {
+		_, ipRange, err := net.ParseCIDR(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ip range %s", value)
+		}
+		ranges = append(ranges, ipRange)
+	}
+	return ranges, nil
+}
+
+// CheckConstraints returns an error if the given certificate violates the name constraints or path length limits of
+// any of its issuers. Issuers must be ordered from the immediate issuer to the root.
+func CheckConstraints(certificate *Certificate, issuers []*Certificate) error {
+	x509Issuers := []*x509.Certificate{}
+	for _, issuer := range issuers {
+		x509Issuers = append(x509Issuers, issuer.X509())
+	}
+	x := certificate.X509()
+	return checkConstraints(x, x.IsCA, x509Issuers)
+}
+
+// checkConstraints returns an error if a certificate with the names of tpl can not be issued under the given issuers,
+// ordered from the immediate issuer to the root. A certificate authority counts towards the path length of its
+// issuers, as it may go on to issue certificates of its own.
+func checkConstraints(tpl *x509.Certificate, isCertificateAuthority bool, issuers []*x509.Certificate) error {
+	for i, issuer := range issuers {
+		if issuer.MaxPathLen > 0 || issuer.MaxPathLenZero {
+			following := i
+			if isCertificateAuthority {
+				following++
+			}
+			if following > issuer.MaxPathLen {
+				return fmt.Errorf("issuer '%s' does not permit more than %d intermediate certificates", issuer.Subject.CommonName, issuer.MaxPathLen)
+			}
+		}
+
+		for _, dns := range tpl.DNSNames {
+			if !isNamePermitted(dns, issuer.PermittedDNSDomains, issuer.ExcludedDNSDomains, matchDomainConstraint) {
+				return fmt.Errorf("dns name %s is not permitted by the name constraints of issuer '%s'", dns, issuer.Subject.CommonName)
+			}
+		}
+		for _, email := range tpl.EmailAddresses {
+			if !isNamePermitted(email, issuer.PermittedEmailAddresses, issuer.ExcludedEmailAddresses, matchEmailConstraint) {
+				return fmt.Errorf("email address %s is not permitted by the name constraints of issuer '%s'", email, issuer.Subject.CommonName)
+			}
+		}
+		for _, uri := range tpl.URIs {
+			if !isNamePermitted(uri.Hostname(), issuer.PermittedURIDomains, issuer.ExcludedURIDomains, matchDomainConstraint) {
+				return fmt.Errorf("uri %s is not permitted by the name constraints of issuer '%s'", uri, issuer.Subject.CommonName)
+			}
+		}
+		for _, ip := range tpl.IPAddresses {
+			if !isIPPermitted(ip, issuer.PermittedIPRanges, issuer.ExcludedIPRanges) {
+				return fmt.Errorf("ip address %s is not permitted by the name constraints of issuer '%s'", ip, issuer.Subject.CommonName)
+			}
+		}
+	}
+	return nil
+}
+
+func isNamePermitted(name string, permitted, excluded []string, match func(name, constraint string) bool) bool {
+	for _, constraint := range excluded {
+		if match(name, constraint) {
+			return false
+		}
+	}
+	if len(permitted) == 0 {
+		return true
+	}
+	for _, constraint := range permitted {
+		if match(name, constraint) {
+			return true
+		}
+	}
+	return false
+}
+
+func isIPPermitted(ip net.IP, permitted, excluded []*net.IPNet) bool {
+	for _, r := range excluded {
+		if r.Contains(ip) {
+			return false
+		}
+	}
+	if len(permitted) == 0 {
+		return true
+	}
+	for _, r := range permitted {
+		if r.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchDomainConstraint(domain, constraint string) bool {
+	domain = strings.ToLower(domain)
+	constraint = strings.ToLower(constraint)
+	if strings.HasPrefix(constraint, ".") {
+		return strings.HasSuffix(domain, constraint)
+	}
+	return domain == constraint || strings.HasSuffix(domain, "."+constraint)
+}
+
+func matchEmailConstraint(email, constraint string) bool {
+	if strings.Contains(constraint, "@") {
+		return strings.EqualFold(email, constraint)
+	}
+	at := strings.LastIndex(email, "@")
+	if at == -1 {
+		return false
+	}
+	host := strings.ToLower(email[at+1:])
+	constraint = strings.ToLower(constraint)
+	if strings.HasPrefix(constraint, ".") {
+		return strings.HasSuffix(host, constraint)
+	}
+	return host == constraint
+}
@@ -0,0 +1,157 @@
+package tls_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/tls-inspector/certbox/tls"
+)
+
+func TestNameConstraints(t *testing.T) {
+	t.Parallel()
+
+	constraints := tls.NameConstraints{
+		Critical:                true,
+		PermittedDNSDomains:     []string{"example.com"},
+		ExcludedDNSDomains:      []string{"secret.example.com"},
+		PermittedEmailAddresses: []string{"example.com"},
+		ExcludedEmailAddresses:  []string{"admin@example.com"},
+		PermittedIPRanges:       []string{"10.0.0.0/8"},
+		ExcludedIPRanges:        []string{"10.1.0.0/16"},
+		PermittedURIDomains:     []string{".example.com"},
+		ExcludedURIDomains:      []string{"secret.example.com"},
+	}
+	root, err := tls.GenerateCertificate(tls.CertificateRequest{
+		KeyType:            tls.KeyTypeECDSA_256,
+		SignatureAlgorithm: tls.SignatureAlgorithmSHA256,
+		Subject:            tls.Name{CommonName: "example.com Constrained Root"},
+		Validity: tls.DateRange{
+			NotBefore: "2001-01-01",
+			NotAfter:  "2002-01-01",
+		},
+		IsCertificateAuthority: true,
+		NameConstraints:        constraints,
+		MaxPathLen:             2,
+	}, nil)
+	if err != nil {
+		t.Fatalf("Error generating root certificate: %s", err.Error())
+	}
+
+	x := root.X509()
+	if !x.PermittedDNSDomainsCritical || x.MaxPathLen != 2 {
+		t.Errorf("Unexpected constraints on generated certificate")
+	}
+
+	clone, err := root.Clone()
+	if err != nil {
+		t.Fatalf("Error cloning certificate: %s", err.Error())
+	}
+	if !reflect.DeepEqual(clone.NameConstraints, constraints) {
+		t.Errorf("Cloned name constraints do not match.\nExpected: %+v\nGot:      %+v", constraints, clone.NameConstraints)
+	}
+	if clone.MaxPathLen != 2 || clone.MaxPathLenZero {
+		t.Errorf("Unexpected cloned path length %d %v", clone.MaxPathLen, clone.MaxPathLenZero)
+	}
+
+	leafRequest := func(name tls.AlternateName) tls.CertificateRequest {
+		return tls.CertificateRequest{
+			KeyType:            tls.KeyTypeECDSA_256,
+			SignatureAlgorithm: tls.SignatureAlgorithmSHA256,
+			Subject:            tls.Name{CommonName: name.Value},
+			Validity: tls.DateRange{
+				NotBefore: "2001-01-01",
+				NotAfter:  "2002-01-01",
+			},
+			AlternateNames: []tls.AlternateName{name},
+		}
+	}
+
+	permitted := []tls.AlternateName{
+		{Type: tls.AlternateNameTypeDNS, Value: "example.com"},
+		{Type: tls.AlternateNameTypeDNS, Value: "WWW.example.com"},
+		{Type: tls.AlternateNameTypeEmail, Value: "user@example.com"},
+		{Type: tls.AlternateNameTypeIP, Value: "10.2.3.4"},
+		{Type: tls.AlternateNameTypeURI, Value: "https://www.example.com/foo"},
+	}
+	for _, name := range permitted {
+		if _, err := tls.GenerateCertificate(leafRequest(name), root); err != nil {
+			t.Errorf("Error generating certificate for permitted %s name %s: %s", name.Type, name.Value, err.Error())
+		}
+	}
+
+	refused := []tls.AlternateName{
+		{Type: tls.AlternateNameTypeDNS, Value: "example.net"},
+		{Type: tls.AlternateNameTypeDNS, Value: "notexample.com"},
+		{Type: tls.AlternateNameTypeDNS, Value: "a.secret.example.com"},
+		{Type: tls.AlternateNameTypeEmail, Value: "admin@example.com"},
+		{Type: tls.AlternateNameTypeEmail, Value: "user@mail.example.com"},
+		{Type: tls.AlternateNameTypeIP, Value: "10.1.2.3"},
+		{Type: tls.AlternateNameTypeIP, Value: "192.168.1.1"},
+		{Type: tls.AlternateNameTypeURI, Value: "https://example.com"},
+		{Type: tls.AlternateNameTypeURI, Value: "https://secret.example.com"},
+	}
+	for _, name := range refused {
+		if _, err := tls.GenerateCertificate(leafRequest(name), root); err == nil {
+			t.Errorf("No error seen when one expected for %s name %s outside of name constraints", name.Type, name.Value)
+		}
+	}
+}
+
+func TestMaxPathLenZero(t *testing.T) {
+	t.Parallel()
+
+	root, err := tls.GenerateCertificate(tls.CertificateRequest{
+		KeyType:            tls.KeyTypeECDSA_256,
+		SignatureAlgorithm: tls.SignatureAlgorithmSHA256,
+		Subject:            tls.Name{CommonName: "example.com Root"},
+		Validity: tls.DateRange{
+			NotBefore: "2001-01-01",
+			NotAfter:  "2002-01-01",
+		},
+		IsCertificateAuthority: true,
+		MaxPathLenZero:         true,
+	}, nil)
+	if err != nil {
+		t.Fatalf("Error generating root certificate: %s", err.Error())
+	}
+
+	clone, err := root.Clone()
+	if err != nil {
+		t.Fatalf("Error cloning certificate: %s", err.Error())
+	}
+	if clone.MaxPathLen != 0 || !clone.MaxPathLenZero {
+		t.Errorf("Unexpected cloned path length %d %v", clone.MaxPathLen, clone.MaxPathLenZero)
+	}
+
+	intermediate := tls.CertificateRequest{
+		KeyType:            tls.KeyTypeECDSA_256,
+		SignatureAlgorithm: tls.SignatureAlgorithmSHA256,
+		Subject:            tls.Name{CommonName: "example.com Intermediate"},
+		Validity: tls.DateRange{
+			NotBefore: "2001-01-01",
+			NotAfter:  "2002-01-01",
+		},
+		IsCertificateAuthority: true,
+	}
+	if _, err := tls.GenerateCertificate(intermediate, root); err == nil {
+		t.Errorf("No error seen when one expected for intermediate below root with zero path length")
+	}
+
+	leaf := tls.CertificateRequest{
+		KeyType:            tls.KeyTypeECDSA_256,
+		SignatureAlgorithm: tls.SignatureAlgorithmSHA256,
+		Subject:            tls.Name{CommonName: "example.com"},
+		Validity: tls.DateRange{
+			NotBefore: "2001-01-01",
+			NotAfter:  "2002-01-01",
+		},
+	}
+	if _, err := tls.GenerateCertificate(leaf, root); err != nil {
+		t.Errorf("Error generating leaf below root with zero path length: %s", err.Error())
+	}
+
+	leaf.MaxPathLen = 1
+	if _, err := tls.GenerateCertificate(leaf, root); err == nil {
+		t.Errorf("No error seen when one expected for path length on certificate that is not a certificate authority")
+	}
+}
@@ -1,7 +1,6 @@
 package tls
 
 import (
-	"crypto/rand"
 	"crypto/x509"
 	"encoding/pem"
 	"fmt"
@@ -117,5 +116,5 @@ func CreatePKCS12(certBytes []byte, keyBytes []byte, caCertBytes []byte, passwor
 		caCerts = append(caCerts, caCert)
 	}
 
-	return pkcs12.Encode(rand.Reader, pkey, cert, caCerts, password)
+	return pkcs12.Encode(secureEntropy.reader, pkey, cert, caCerts, password)
 }
@@ -0,0 +1,183 @@
+package tls
+
+import (
+	"crypto"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"time"
+)
+
+// Revocation reason codes as defined in RFC 5280 section 5.3.1. RevocationReasonRemoveFromCRL is only valid in a
+// delta CRL.
+const (
+	RevocationReasonUnspecified          = 0
+	RevocationReasonKeyCompromise        = 1
+	RevocationReasonCACompromise         = 2
+	RevocationReasonAffiliationChanged   = 3
+	RevocationReasonSuperseded           = 4
+	RevocationReasonCessationOfOperation = 5
+	RevocationReasonCertificateHold      = 6
+	RevocationReasonRemoveFromCRL        = 8
+	RevocationReasonPrivilegeWithdrawn   = 9
+	RevocationReasonAACompromise         = 10
+)
+
+var oidExtensionDeltaCRLIndicator = asn1.ObjectIdentifier([]int{2, 5, 29, 27})
+
+// RevokedCertificate describes a single revoked certificate in a CRL
+type RevokedCertificate struct {
+	// Serial is the decimal serial number of the revoked certificate, or hexadecimal if prefixed with 0x
+	Serial         string
+	RevocationDate string
+	ReasonCode     int
+}
+
+// CRLRequest describes a certificate revocation list
+type CRLRequest struct {
+	// Number is the decimal CRL number
+	Number string
+	// BaseCRLNumber is the decimal number of the complete CRL that this CRL updates. When set, a delta CRL is
+	// generated.
+	BaseCRLNumber string
+	// ThisUpdate is the issue date of the CRL. Defaults to now if empty.
+	ThisUpdate         string
+	NextUpdate         string
+	SignatureAlgorithm string
+	Revoked            []RevokedCertificate
+}
+
+// GenerateCRL will generate a certificate revocation list signed by the given issuer and return it DER encoded.
+// The issuer must include a private key and have the CRLSign key usage.
+func GenerateCRL(request CRLRequest, issuer *Certificate) ([]byte, error) {
+	if issuer == nil || issuer.KeyData == "" {
+		return nil, fmt.Errorf("issuer with private key required")
+	}
+
+	number, err := ParseSerial(request.Number)
+	if err != nil {
+		return nil, fmt.Errorf("invalid crl number: %s", err.Error())
+	}
+
+	thisUpdate := time.Now().UTC()
+	if request.ThisUpdate != "" {
+		thisUpdate, err = parseDate(request.ThisUpdate)
+		if err != nil {
+			return nil, fmt.Errorf("invalid thisUpdate: %s", err.Error())
+		}
+	}
+	nextUpdate, err := parseDate(request.NextUpdate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid nextUpdate: %s", err.Error())
+	}
+	if !thisUpdate.Before(nextUpdate) {
+		return nil, fmt.Errorf("nextUpdate must be after thisUpdate")
+	}
+
+	tpl := &x509.RevocationList{
+		Number:     number,
+		ThisUpdate: thisUpdate,
+		NextUpdate: nextUpdate,
+	}
+
+	signer := issuer.PKey().(crypto.Signer)
+	if request.SignatureAlgorithm != "" {
+		tpl.SignatureAlgorithm, err = x509SignatureAlgorithm(signer.Public(), request.SignatureAlgorithm)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if request.BaseCRLNumber != "" {
+		baseNumber, err := ParseSerial(request.BaseCRLNumber)
+		if err != nil {
+			return nil, fmt.Errorf("invalid base crl number: %s", err.Error())
+		}
+		if baseNumber.Cmp(number) >= 0 {
+			return nil, fmt.Errorf("base crl number must be less than the crl number")
+		}
+		value, err := asn1.Marshal(baseNumber)
+		if err != nil {
+			return nil, err
+		}
+		tpl.ExtraExtensions = append(tpl.ExtraExtensions, pkix.Extension{
+			Id:       oidExtensionDeltaCRLIndicator,
+			Critical: true,
+			Value:    value,
+		})
+	}
+
+	for i, revoked := range request.Revoked {
+		serial, err := ParseSerial(revoked.Serial)
+		if err != nil {
+			return nil, fmt.Errorf("invalid revoked certificate at index %d: %s", i, err.Error())
+		}
+		revocationDate, err := parseDate(revoked.RevocationDate)
+		if err != nil {
+			return nil, fmt.Errorf("invalid revocation date at index %d: %s", i, err.Error())
+		}
+		if !isValidRevocationReason(revoked.ReasonCode) {
+			return nil, fmt.Errorf("invalid revocation reason at index %d: %d", i, revoked.ReasonCode)
+		}
+		if revoked.ReasonCode == RevocationReasonRemoveFromCRL && request.BaseCRLNumber == "" {
+			return nil, fmt.Errorf("revocation reason at index %d is only valid in a delta CRL: %d", i, revoked.ReasonCode)
+		}
+		tpl.RevokedCertificateEntries = append(tpl.RevokedCertificateEntries, x509.RevocationListEntry{
+			SerialNumber:   serial,
+			RevocationTime: revocationDate,
+			ReasonCode:     revoked.ReasonCode,
+		})
+	}
+
+	return x509.CreateRevocationList(secureEntropy.reader, tpl, issuer.X509(), secureEntropy.signer(signer))
+}
+
+// ParseCRL will parse the given PEM or DER encoded certificate revocation list and return a request that describes it
+func ParseCRL(data []byte) (*CRLRequest, error) {
+	if block, _ := pem.Decode(data); block != nil {
+		data = block.Bytes
+	}
+
+	crl, err := x509.ParseRevocationList(data)
+	if err != nil {
+		return nil, err
+	}
+
+	request := CRLRequest{
+		ThisUpdate:         crl.ThisUpdate.UTC().Format(time.RFC3339),
+		NextUpdate:         crl.NextUpdate.UTC().Format(time.RFC3339),
+		SignatureAlgorithm: signatureAlgorithmName(crl.SignatureAlgorithm),
+		Revoked:            []RevokedCertificate{},
+	}
+	if crl.Number != nil {
+		request.Number = crl.Number.String()
+	}
+
+	for _, ext := range crl.Extensions {
+		if !ext.Id.Equal(oidExtensionDeltaCRLIndicator) {
+			continue
+		}
+		baseNumber := new(big.Int)
+		if _, err := asn1.Unmarshal(ext.Value, &baseNumber); err != nil {
+			return nil, fmt.Errorf("invalid delta crl indicator: %s", err.Error())
+		}
+		request.BaseCRLNumber = baseNumber.String()
+	}
+
+	for _, entry := range crl.RevokedCertificateEntries {
+		request.Revoked = append(request.Revoked, RevokedCertificate{
+			Serial:         entry.SerialNumber.String(),
+			RevocationDate: entry.RevocationTime.UTC().Format(time.RFC3339),
+			ReasonCode:     entry.ReasonCode,
+		})
+	}
+
+	return &request, nil
+}
+
+func isValidRevocationReason(reason int) bool {
+	return reason >= RevocationReasonUnspecified && reason <= RevocationReasonAACompromise && reason != 7
+}
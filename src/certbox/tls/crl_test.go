@@ -0,0 +1,147 @@
+package tls_test
+
+import (
+	"crypto/x509"
+	"testing"
+
+	"github.com/tls-inspector/certbox/tls"
+)
+
+func TestGenerateCRL(t *testing.T) {
+	t.Parallel()
+
+	root, leaf, err := generateCertificateChain()
+	if err != nil {
+		t.Fatalf("Error generating certificate chain: %s", err.Error())
+	}
+
+	request := tls.CRLRequest{
+		Number:     "2",
+		ThisUpdate: "2001-02-01",
+		NextUpdate: "2001-03-01",
+		Revoked: []tls.RevokedCertificate{
+			{
+				Serial:         leaf.Serial,
+				RevocationDate: "2001-01-15",
+				ReasonCode:     tls.RevocationReasonKeyCompromise,
+			},
+		},
+	}
+
+	data, err := tls.GenerateCRL(request, root)
+	if err != nil {
+		t.Fatalf("Error generating CRL: %s", err.Error())
+	}
+
+	crl, err := x509.ParseRevocationList(data)
+	if err != nil {
+		t.Fatalf("Error parsing CRL: %s", err.Error())
+	}
+	if err := crl.CheckSignatureFrom(root.X509()); err != nil {
+		t.Fatalf("Invalid CRL signature: %s", err.Error())
+	}
+
+	parsed, err := tls.ParseCRL(data)
+	if err != nil {
+		t.Fatalf("Error parsing CRL: %s", err.Error())
+	}
+	if parsed.Number != request.Number {
+		t.Errorf("Unexpected CRL number. Expected '%s' got '%s'", request.Number, parsed.Number)
+	}
+	if parsed.BaseCRLNumber != "" {
+		t.Errorf("Unexpected base CRL number on complete CRL: '%s'", parsed.BaseCRLNumber)
+	}
+	if len(parsed.Revoked) != 1 {
+		t.Fatalf("Unexpected number of revoked certificates. Expected 1 got %d", len(parsed.Revoked))
+	}
+	if parsed.Revoked[0].Serial != leaf.Serial {
+		t.Errorf("Unexpected revoked serial. Expected '%s' got '%s'", leaf.Serial, parsed.Revoked[0].Serial)
+	}
+	if parsed.Revoked[0].ReasonCode != tls.RevocationReasonKeyCompromise {
+		t.Errorf("Unexpected revocation reason. Expected %d got %d", tls.RevocationReasonKeyCompromise, parsed.Revoked[0].ReasonCode)
+	}
+	if parsed.Revoked[0].RevocationDate != "2001-01-15T00:00:00Z" {
+		t.Errorf("Unexpected revocation date '%s'", parsed.Revoked[0].RevocationDate)
+	}
+}
+
+func TestGenerateDeltaCRL(t *testing.T) {
+	t.Parallel()
+
+	root, _, err := generateCertificateChain()
+	if err != nil {
+		t.Fatalf("Error generating certificate chain: %s", err.Error())
+	}
+
+	data, err := tls.GenerateCRL(tls.CRLRequest{
+		Number:        "5",
+		BaseCRLNumber: "4",
+		ThisUpdate:    "2001-02-01",
+		NextUpdate:    "2001-02-02",
+		Revoked: []tls.RevokedCertificate{
+			{
+				Serial:         "0x1234",
+				RevocationDate: "2001-02-01",
+				ReasonCode:     tls.RevocationReasonCertificateHold,
+			},
+		},
+	}, root)
+	if err != nil {
+		t.Fatalf("Error generating delta CRL: %s", err.Error())
+	}
+
+	parsed, err := tls.ParseCRL(data)
+	if err != nil {
+		t.Fatalf("Error parsing CRL: %s", err.Error())
+	}
+	if parsed.BaseCRLNumber != "4" {
+		t.Errorf("Unexpected base CRL number. Expected '4' got '%s'", parsed.BaseCRLNumber)
+	}
+	if parsed.Revoked[0].Serial != "4660" {
+		t.Errorf("Unexpected revoked serial. Expected '4660' got '%s'", parsed.Revoked[0].Serial)
+	}
+
+	if _, err := tls.GenerateCRL(tls.CRLRequest{
+		Number:        "5",
+		BaseCRLNumber: "5",
+		NextUpdate:    "2099-01-01",
+	}, root); err == nil {
+		t.Errorf("No error seen when one expected for base CRL number not less than CRL number")
+	}
+	if _, err := tls.GenerateCRL(tls.CRLRequest{
+		Number:     "5",
+		NextUpdate: "2099-01-01",
+		Revoked: []tls.RevokedCertificate{
+			{
+				Serial:         "1",
+				RevocationDate: "2001-02-01",
+				ReasonCode:     7,
+			},
+		},
+	}, root); err == nil {
+		t.Errorf("No error seen when one expected for invalid reason code")
+	}
+
+	removed := []tls.RevokedCertificate{
+		{
+			Serial:         "0x1234",
+			RevocationDate: "2001-02-01",
+			ReasonCode:     tls.RevocationReasonRemoveFromCRL,
+		},
+	}
+	if _, err := tls.GenerateCRL(tls.CRLRequest{
+		Number:        "6",
+		BaseCRLNumber: "5",
+		NextUpdate:    "2099-01-01",
+		Revoked:       removed,
+	}, root); err != nil {
+		t.Errorf("Error generating delta CRL with removeFromCRL reason: %s", err.Error())
+	}
+	if _, err := tls.GenerateCRL(tls.CRLRequest{
+		Number:     "6",
+		NextUpdate: "2099-01-01",
+		Revoked:    removed,
+	}, root); err == nil {
+		t.Errorf("No error seen when one expected for removeFromCRL reason in a complete CRL")
+	}
+}
@@ -0,0 +1,140 @@
+package tls
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// NoWellDefinedExpiration is the NotAfter value for certificates that have no well-defined expiration date, as
+// described in RFC 5280 section 4.1.2.5
+const NoWellDefinedExpiration = "99991231235959Z"
+
+// DateRange describes a date range. Values can be in any of these formats:
+//
+//   - A date in YYYY-mm-DD (2006-01-02) format, where the time is fixed to 00:00:00UTC
+//   - A RFC 3339 timestamp, such as 2006-01-02T15:04:05Z or 2006-01-02T15:04:05-07:00
+//   - A GeneralizedTime timestamp in YYYYmmDDHHMMSSZ format, such as NoWellDefinedExpiration
+//   - "now", or a duration relative to now such as "+90d", "-1h" or "+1d12h". Supported units are s, m, h, d and w.
+//
+// Times are always converted to UTC and truncated to the second. Dates can be in the future or past, but the NotBefore
+// date must always be before the NotAfter.
+//
+// Certificates encode dates before 2050 as a UTCTime and dates from 2050 onwards as a GeneralizedTime, as required by
+// RFC 5280. Dates before 1950 are also encoded as a GeneralizedTime, as UTCTime can not represent them.
+type DateRange struct {
+	NotBefore string
+	NotAfter  string
+}
+
+const generalizedTimeLayout = "20060102150405Z"
+
+var relativeDatePattern = regexp.MustCompile(`^[+-]([0-9]+[smhdw])+$`)
+var relativeDateComponentPattern = regexp.MustCompile(`([0-9]+)([smhdw])`)
+
+// parseDate parses the given date in any of the formats supported by DateRange
+func parseDate(value string) (time.Time, error) {
+	t, err := parseDateValue(value)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if t.Year() < 1 || t.Year() > 9999 {
+		return time.Time{}, fmt.Errorf("date %s is out of range", value)
+	}
+	return t, nil
+}
+
+// ParseDate parses the given date in any of the formats supported by DateRange, for callers outside of this package
+func ParseDate(value string) (time.Time, error) {
+	return parseDate(value)
+}
+
+func parseDateValue(value string) (time.Time, error) {
+	now := time.Now().UTC().Truncate(time.Second)
+
+	if value == "now" {
+		return now, nil
+	}
+
+	if relativeDatePattern.MatchString(value) {
+		var offset time.Duration
+		for _, component := range relativeDateComponentPattern.FindAllStringSubmatch(value, -1) {
+			n, err := strconv.ParseInt(component[1], 10, 64)
+			unit := relativeDateUnits[component[2]]
+			if err != nil || n > int64(math.MaxInt64-offset)/int64(unit) {
+				return time.Time{}, fmt.Errorf("relative date %s is out of range", value)
+			}
+			offset += time.Duration(n) * unit
+		}
+		if value[0] == '-' {
+			offset = -offset
+		}
+		return now.Add(offset), nil
+	}
+
+	if t, err := time.ParseInLocation(time.DateOnly, value, time.UTC); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(generalizedTimeLayout, value); err == nil {
+		return t.UTC(), nil
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("unrecognized date format '%s'", value)
+	}
+	return t.UTC().Truncate(time.Second), nil
+}
+
+var relativeDateUnits = map[string]time.Duration{
+	"s": time.Second,
+	"m": time.Minute,
+	"h": time.Hour,
+	"d": 24 * time.Hour,
+	"w": 7 * 24 * time.Hour,
+}
+
+// formatDate returns the given time as a date if it is exactly midnight UTC, otherwise as a RFC 3339 timestamp
+func formatDate(t time.Time) string {
+	t = t.UTC()
+	if t.Equal(t.Truncate(24 * time.Hour)) {
+		return t.Format(time.DateOnly)
+	}
+	return t.Format(time.RFC3339)
+}
+
+func (d DateRange) dates() (*time.Time, *time.Time, error) {
+	notBefore, err := parseDate(d.NotBefore)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid notBefore: %s", err.Error())
+	}
+
+	notAfter, err := parseDate(d.NotAfter)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid not After: %s", err.Error())
+	}
+
+	return &notBefore, &notAfter, nil
+}
+
+func (d DateRange) mustDates() (time.Time, time.Time) {
+	nb, na, err := d.dates()
+	if err != nil {
+		panic(err.Error())
+	}
+	return *nb, *na
+}
+
+// IsValid is the values of the date range valid
+func (d DateRange) IsValid() bool {
+	nb, na, err := d.dates()
+	if err != nil {
+		return false
+	}
+	notBefore := *nb
+	notAfter := *na
+
+	return notBefore.Before(notAfter)
+}
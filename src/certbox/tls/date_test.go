@@ -0,0 +1,189 @@
+package tls_test
+
+import (
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/tls-inspector/certbox/tls"
+)
+
+// validityTags returns the ASN.1 tags used to encode the validity of the given certificate
+func validityTags(t *testing.T, certificate *tls.Certificate) (int, int) {
+	var tbs struct {
+		Version      int `asn1:"optional,explicit,default:0,tag:0"`
+		SerialNumber *big.Int
+		Signature    pkix.AlgorithmIdentifier
+		Issuer       asn1.RawValue
+		Validity     struct {
+			NotBefore asn1.RawValue
+			NotAfter  asn1.RawValue
+		}
+		Subject    asn1.RawValue
+		PublicKey  asn1.RawValue
+		Extensions asn1.RawValue `asn1:"optional,explicit,tag:3"`
+	}
+	if _, err := asn1.Unmarshal(certificate.X509().RawTBSCertificate, &tbs); err != nil {
+		t.Fatalf("Error parsing certificate: %s", err.Error())
+	}
+	return tbs.Validity.NotBefore.Tag, tbs.Validity.NotAfter.Tag
+}
+
+func TestDateRangeFormats(t *testing.T) {
+	t.Parallel()
+
+	certificate, err := tls.GenerateCertificate(tls.CertificateRequest{
+		KeyType:            tls.KeyTypeECDSA_256,
+		SignatureAlgorithm: tls.SignatureAlgorithmSHA256,
+		Subject:            tls.Name{CommonName: "example.com"},
+		Validity: tls.DateRange{
+			NotBefore: "2001-01-01T12:30:45.123+02:00",
+			NotAfter:  "20010102000000Z",
+		},
+	}, nil)
+	if err != nil {
+		t.Fatalf("Error generating certificate: %s", err.Error())
+	}
+	x := certificate.X509()
+	if expected := time.Date(2001, 1, 1, 10, 30, 45, 0, time.UTC); !x.NotBefore.Equal(expected) {
+		t.Errorf("Unexpected not before date. Expected '%s' got '%s'", expected, x.NotBefore)
+	}
+	if expected := time.Date(2001, 1, 2, 0, 0, 0, 0, time.UTC); !x.NotAfter.Equal(expected) {
+		t.Errorf("Unexpected not after date. Expected '%s' got '%s'", expected, x.NotAfter)
+	}
+
+	clone, err := certificate.Clone()
+	if err != nil {
+		t.Fatalf("Error cloning certificate: %s", err.Error())
+	}
+	if clone.Validity.NotBefore != "2001-01-01T10:30:45Z" {
+		t.Errorf("Unexpected cloned not before date '%s'", clone.Validity.NotBefore)
+	}
+	if clone.Validity.NotAfter != "2001-01-02" {
+		t.Errorf("Unexpected cloned not after date '%s'", clone.Validity.NotAfter)
+	}
+}
+
+func TestDateRangeRelative(t *testing.T) {
+	t.Parallel()
+
+	before := time.Now().UTC().Truncate(time.Second)
+	certificate, err := tls.GenerateCertificate(tls.CertificateRequest{
+		KeyType:            tls.KeyTypeECDSA_256,
+		SignatureAlgorithm: tls.SignatureAlgorithmSHA256,
+		Subject:            tls.Name{CommonName: "example.com"},
+		Validity: tls.DateRange{
+			NotBefore: "-1h",
+			NotAfter:  "+1d12h",
+		},
+	}, nil)
+	if err != nil {
+		t.Fatalf("Error generating certificate: %s", err.Error())
+	}
+	after := time.Now().UTC()
+
+	x := certificate.X509()
+	if x.NotBefore.Before(before.Add(-time.Hour)) || x.NotBefore.After(after.Add(-time.Hour)) {
+		t.Errorf("Unexpected not before date '%s'", x.NotBefore)
+	}
+	if x.NotAfter.Before(before.Add(36*time.Hour)) || x.NotAfter.After(after.Add(36*time.Hour)) {
+		t.Errorf("Unexpected not after date '%s'", x.NotAfter)
+	}
+
+	certificate, err = tls.GenerateCertificate(tls.CertificateRequest{
+		KeyType:            tls.KeyTypeECDSA_256,
+		SignatureAlgorithm: tls.SignatureAlgorithmSHA256,
+		Subject:            tls.Name{CommonName: "example.com"},
+		Validity: tls.DateRange{
+			NotBefore: "now",
+			NotAfter:  "+5m",
+		},
+	}, nil)
+	if err != nil {
+		t.Fatalf("Error generating certificate: %s", err.Error())
+	}
+	x = certificate.X509()
+	if x.NotAfter.Sub(x.NotBefore) != 5*time.Minute {
+		t.Errorf("Unexpected validity length %s", x.NotAfter.Sub(x.NotBefore))
+	}
+}
+
+func TestDateRangeEncoding(t *testing.T) {
+	t.Parallel()
+
+	type testCase struct {
+		Validity         tls.DateRange
+		NotBeforeTag     int
+		NotAfterTag      int
+		ExpectedNotAfter time.Time
+	}
+
+	cases := []testCase{
+		{
+			Validity:         tls.DateRange{NotBefore: "1950-01-01", NotAfter: "2049-12-31T23:59:59Z"},
+			NotBeforeTag:     asn1.TagUTCTime,
+			NotAfterTag:      asn1.TagUTCTime,
+			ExpectedNotAfter: time.Date(2049, 12, 31, 23, 59, 59, 0, time.UTC),
+		},
+		{
+			Validity:         tls.DateRange{NotBefore: "1949-12-31T23:59:59Z", NotAfter: "2050-01-01"},
+			NotBeforeTag:     asn1.TagGeneralizedTime,
+			NotAfterTag:      asn1.TagGeneralizedTime,
+			ExpectedNotAfter: time.Date(2050, 1, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			Validity:         tls.DateRange{NotBefore: "2001-01-01", NotAfter: tls.NoWellDefinedExpiration},
+			NotBeforeTag:     asn1.TagUTCTime,
+			NotAfterTag:      asn1.TagGeneralizedTime,
+			ExpectedNotAfter: time.Date(9999, 12, 31, 23, 59, 59, 0, time.UTC),
+		},
+	}
+
+	for _, c := range cases {
+		certificate, err := tls.GenerateCertificate(tls.CertificateRequest{
+			KeyType:            tls.KeyTypeECDSA_256,
+			SignatureAlgorithm: tls.SignatureAlgorithmSHA256,
+			Subject:            tls.Name{CommonName: "example.com"},
+			Validity:           c.Validity,
+		}, nil)
+		if err != nil {
+			t.Fatalf("Error generating certificate with validity %v: %s", c.Validity, err.Error())
+		}
+		notBeforeTag, notAfterTag := validityTags(t, certificate)
+		if notBeforeTag != c.NotBeforeTag {
+			t.Errorf("Unexpected not before encoding for %s. Expected %d got %d", c.Validity.NotBefore, c.NotBeforeTag, notBeforeTag)
+		}
+		if notAfterTag != c.NotAfterTag {
+			t.Errorf("Unexpected not after encoding for %s. Expected %d got %d", c.Validity.NotAfter, c.NotAfterTag, notAfterTag)
+		}
+		if x := certificate.X509(); !x.NotAfter.Equal(c.ExpectedNotAfter) {
+			t.Errorf("Unexpected not after date. Expected '%s' got '%s'", c.ExpectedNotAfter, x.NotAfter)
+		}
+	}
+}
+
+func TestDateRangeInvalid(t *testing.T) {
+	t.Parallel()
+
+	values := []string{
+		"",
+		"tomorrow",
+		"+5y",
+		"+1h-1m",
+		"2001-13-01",
+		"+99999999999999999999d",
+	}
+
+	for _, value := range values {
+		validity := tls.DateRange{NotBefore: "2001-01-01", NotAfter: value}
+		if validity.IsValid() {
+			t.Errorf("No error seen when one expected for date '%s'", value)
+		}
+	}
+
+	if (tls.DateRange{NotBefore: "+1h", NotAfter: "now"}).IsValid() {
+		t.Errorf("No error seen when one expected for not after before not before")
+	}
+}
@@ -0,0 +1,240 @@
+package tls
+
+import (
+	"math/big"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Difference describes a single field that differs between two certificates. Field identifies the field, such as
+// Subject.CN, NotAfter or Extensions[Key Usage].Critical. A and B are the values of the field in each certificate,
+// and are empty if the field is absent.
+type Difference struct {
+	Field string
+	A     string
+	B     string
+}
+
+// CertificateDiff describes every difference between two certificates
+type CertificateDiff struct {
+	Differences []Difference
+	// Text is a human readable rendering of the differences, or empty if there are none
+	Text string
+}
+
+// DiffCertificates will compare certificates a and b field by field
+func DiffCertificates(a Certificate, b Certificate) (*CertificateDiff, error) {
+	aDetails, err := InspectCertificate(a)
+	if err != nil {
+		return nil, err
+	}
+	bDetails, err := InspectCertificate(b)
+	if err != nil {
+		return nil, err
+	}
+	return diffCertificateDetails(aDetails, bDetails, nil), nil
+}
+
+// DiffCertificateRequest will compare the certificate with the certificate that would be generated for the given
+// request and issuer. Issuer may be nil for a self-signed certificate. Fields that can't be known before generation,
+// such as a random serial number, the random bits of a prefixed serial number or a public key that hasn't been
+// generated, are not compared.
+func DiffCertificateRequest(certificate Certificate, request CertificateRequest, issuer *Certificate) (*CertificateDiff, error) {
+	aDetails, err := InspectCertificate(certificate)
+	if err != nil {
+		return nil, err
+	}
+
+	preview, err := PreviewCertificate(request, issuer)
+	if err != nil {
+		return nil, err
+	}
+	previewCertificate, err := ImportDERCertificate(preview.Raw)
+	if err != nil {
+		return nil, err
+	}
+	bDetails, err := InspectCertificate(*previewCertificate)
+	if err != nil {
+		return nil, err
+	}
+
+	ignored := map[string]bool{}
+	switch request.Serial.Strategy {
+	case "", SerialStrategyRandom:
+		ignored["Serial"] = true
+	case SerialStrategyPrefixed:
+		// Only the prefix is known before generation
+		bits := uint(defaultSerialRandomBits)
+		if request.Serial.RandomBits > 0 {
+			bits = uint(request.Serial.RandomBits)
+		}
+		aPrefix := new(big.Int).Rsh(certificate.X509().SerialNumber, bits)
+		bPrefix := new(big.Int).Rsh(preview.SerialNumber, bits)
+		ignored["Serial"] = aPrefix.Cmp(bPrefix) == 0
+	}
+	if request.PrivateKey == "" {
+		ignored["PublicKey.Value"] = true
+		ignored["Extensions[Subject Key Identifier].Value"] = true
+	}
+	return diffCertificateDetails(aDetails, bDetails, ignored), nil
+}
+
+func diffCertificateDetails(a, b *CertificateDetails, ignored map[string]bool) *CertificateDiff {
+	diff := &CertificateDiff{Differences: []Difference{}}
+	add := func(field, aValue, bValue string) {
+		if aValue != bValue && !ignored[field] {
+			diff.Differences = append(diff.Differences, Difference{Field: field, A: aValue, B: bValue})
+		}
+	}
+
+	add("Version", strconv.Itoa(a.Version), strconv.Itoa(b.Version))
+	add("Serial", a.SerialHex, b.SerialHex)
+	add("SignatureAlgorithm", a.SignatureAlgorithm, b.SignatureAlgorithm)
+	diffNames("Issuer", a.Issuer, b.Issuer, add)
+	add("NotBefore", a.NotBefore, b.NotBefore)
+	add("NotAfter", a.NotAfter, b.NotAfter)
+	diffNames("Subject", a.Subject, b.Subject, add)
+
+	add("PublicKey.Algorithm", a.PublicKey.Algorithm, b.PublicKey.Algorithm)
+	add("PublicKey.Bits", strconv.Itoa(a.PublicKey.Bits), strconv.Itoa(b.PublicKey.Bits))
+	add("PublicKey.Curve", a.PublicKey.Curve, b.PublicKey.Curve)
+	add("PublicKey.Exponent", exponentText(a.PublicKey.Exponent), exponentText(b.PublicKey.Exponent))
+	add("PublicKey.Value", a.PublicKey.Value, b.PublicKey.Value)
+
+	diffExtensions(a.Extensions, b.Extensions, add)
+
+	if len(diff.Differences) > 0 {
+		diff.Text = diffText(diff.Differences)
+	}
+	return diff
+}
+
+// diffNames compares each attribute of names a and b. If every attribute is the same but the names still differ,
+// such as when the attributes are in a different order, the names are compared as a whole.
+func diffNames(field string, a, b NameDetails, add func(field, aValue, bValue string)) {
+	aAttributes, aOrder := nameAttributeValues(a)
+	bAttributes, bOrder := nameAttributeValues(b)
+
+	found := false
+	for _, attribute := range bOrder {
+		if !slices.Contains(aOrder, attribute) {
+			aOrder = append(aOrder, attribute)
+		}
+	}
+	for _, attribute := range aOrder {
+		aValue := strings.Join(aAttributes[attribute], ", ")
+		bValue := strings.Join(bAttributes[attribute], ", ")
+		if aValue != bValue {
+			found = true
+			add(field+"."+attribute, aValue, bValue)
+		}
+	}
+	if !found {
+		add(field, a.DN, b.DN)
+	}
+}
+
+// nameAttributeValues returns the values of each attribute type in name, and the order the types first appear in
+func nameAttributeValues(name NameDetails) (map[string][]string, []string) {
+	values := map[string][]string{}
+	order := []string{}
+	for _, rdn := range name.RDNs {
+		for _, attribute := range rdn {
+			key := attribute.ShortName
+			if key == "" {
+				key = attribute.OID
+			}
+			if _, seen := values[key]; !seen {
+				order = append(order, key)
+			}
+			values[key] = append(values[key], attribute.Value)
+		}
+	}
+	return values, order
+}
+
+// diffExtensions compares the criticality and value of each extension. Extensions present in only one certificate
+// are a single difference, with the value absent from the other.
+func diffExtensions(a, b []ExtensionDetails, add func(field, aValue, bValue string)) {
+	aOrder, bOrder := []string{}, []string{}
+	for _, ext := range a {
+		aOrder = append(aOrder, extensionLabel(ext))
+	}
+	for _, ext := range b {
+		bOrder = append(bOrder, extensionLabel(ext))
+	}
+
+	for i, ext := range a {
+		field := "Extensions[" + aOrder[i] + "]"
+		j := slices.Index(bOrder, aOrder[i])
+		if j == -1 {
+			add(field, extensionValueText(ext), "")
+			continue
+		}
+		add(field+".Critical", strconv.FormatBool(ext.Critical), strconv.FormatBool(b[j].Critical))
+		add(field+".Value", extensionValueText(ext), extensionValueText(b[j]))
+	}
+	for j, ext := range b {
+		if !slices.Contains(aOrder, bOrder[j]) {
+			add("Extensions["+bOrder[j]+"]", "", extensionValueText(ext))
+		}
+	}
+
+	aShared, bShared := []string{}, []string{}
+	for _, label := range aOrder {
+		if slices.Contains(bOrder, label) {
+			aShared = append(aShared, label)
+		}
+	}
+	for _, label := range bOrder {
+		if slices.Contains(aOrder, label) {
+			bShared = append(bShared, label)
+		}
+	}
+	add("Extensions.Order", strings.Join(aShared, ", "), strings.Join(bShared, ", "))
+}
+
+// extensionLabel returns the name of a known extension, or its OID
+func extensionLabel(ext ExtensionDetails) string {
+	if ext.Name != "" {
+		return ext.Name
+	}
+	return ext.OID
+}
+
+// extensionValueText returns the value of the extension as it is described by CertificateText
+func extensionValueText(ext ExtensionDetails) string {
+	w := &textWriter{}
+	w.extensionValue(0, ext)
+	return strings.TrimSuffix(w.String(), "\n")
+}
+
+func exponentText(exponent int) string {
+	if exponent == 0 {
+		return ""
+	}
+	return strconv.Itoa(exponent)
+}
+
+// diffText renders the differences like a unified diff, with the value from the first certificate prefixed by - and
+// the value from the second by +
+func diffText(differences []Difference) string {
+	w := &textWriter{}
+	for _, difference := range differences {
+		w.line(0, "%s:", difference.Field)
+		for _, side := range []struct {
+			prefix string
+			value  string
+		}{{"-", difference.A}, {"+", difference.B}} {
+			if side.value == "" {
+				w.line(4, "%s <absent>", side.prefix)
+				continue
+			}
+			for _, line := range strings.Split(side.value, "\n") {
+				w.line(4, "%s %s", side.prefix, line)
+			}
+		}
+	}
+	return w.String()
+}
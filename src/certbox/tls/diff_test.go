@@ -0,0 +1,224 @@
+package tls_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/tls-inspector/certbox/tls"
+)
+
+func diffRequest() tls.CertificateRequest {
+	return tls.CertificateRequest{
+		KeyType:            tls.KeyTypeECDSA_256,
+		SignatureAlgorithm: tls.SignatureAlgorithmSHA256,
+		Subject: tls.Name{
+			Organization: "example.com",
+			Country:      "CA",
+			CommonName:   "foo.example.com",
+		},
+		AlternateNames: []tls.AlternateName{{Type: tls.AlternateNameTypeDNS, Value: "foo.example.com"}},
+		Validity: tls.DateRange{
+			NotBefore: "2001-01-01",
+			NotAfter:  "2002-01-01",
+		},
+		Usage: tls.KeyUsage{DigitalSignature: true, ServerAuth: true},
+	}
+}
+
+func differenceFields(diff *tls.CertificateDiff) map[string]tls.Difference {
+	fields := map[string]tls.Difference{}
+	for _, difference := range diff.Differences {
+		fields[difference.Field] = difference
+	}
+	return fields
+}
+
+func TestDiffCertificates(t *testing.T) {
+	t.Parallel()
+
+	chain := generateLongCertificateChain(t)
+	issuer := chain[1]
+
+	a, err := tls.GenerateCertificate(diffRequest(), issuer)
+	if err != nil {
+		t.Fatalf("Error generating certificate: %s", err.Error())
+	}
+
+	diff, err := tls.DiffCertificates(*a, *a)
+	if err != nil {
+		t.Fatalf("Error comparing certificates: %s", err.Error())
+	}
+	if len(diff.Differences) != 0 || diff.Text != "" {
+		t.Errorf("Unexpected differences for the same certificate: %+v", diff.Differences)
+	}
+
+	request := diffRequest()
+	request.KeyType = tls.KeyTypeECDSA_384
+	request.Subject.CommonName = "bar.example.com"
+	request.AlternateNames = append(request.AlternateNames, tls.AlternateName{Type: tls.AlternateNameTypeDNS, Value: "bar.example.com"})
+	request.Validity.NotAfter = "2001-07-01"
+	request.Usage.ClientAuth = true
+	request.StatusProviders = tls.StatusProviders{CRL: []string{"http://crl.example.com/intermediate.crl"}}
+	b, err := tls.GenerateCertificate(request, issuer)
+	if err != nil {
+		t.Fatalf("Error generating certificate: %s", err.Error())
+	}
+
+	diff, err = tls.DiffCertificates(*a, *b)
+	if err != nil {
+		t.Fatalf("Error comparing certificates: %s", err.Error())
+	}
+	fields := differenceFields(diff)
+	expected := map[string]tls.Difference{
+		"Subject.CN":                           {A: "foo.example.com", B: "bar.example.com"},
+		"NotAfter":                             {A: "2002-01-01T00:00:00Z", B: "2001-07-01T00:00:00Z"},
+		"PublicKey.Bits":                       {A: "256", B: "384"},
+		"PublicKey.Curve":                      {A: "P-256", B: "P-384"},
+		"Extensions[Extended Key Usage].Value": {A: "TLS Web Server Authentication", B: "TLS Web Server Authentication, TLS Web Client Authentication"},
+		"Extensions[Subject Alternative Name].Value": {A: "DNS:foo.example.com", B: "DNS:foo.example.com, DNS:bar.example.com"},
+		"Extensions[CRL Distribution Points]":        {A: "", B: "Full Name:\n  URI:http://crl.example.com/intermediate.crl"},
+	}
+	for field, difference := range expected {
+		actual, ok := fields[field]
+		if !ok {
+			t.Errorf("No difference seen for %s. Differences: %+v", field, diff.Differences)
+			continue
+		}
+		if actual.A != difference.A || actual.B != difference.B {
+			t.Errorf("Unexpected difference for %s. Expected '%s' -> '%s' got '%s' -> '%s'", field, difference.A, difference.B, actual.A, actual.B)
+		}
+	}
+	for _, field := range []string{"Serial", "PublicKey.Value", "Extensions[Subject Key Identifier].Value"} {
+		if _, ok := fields[field]; !ok {
+			t.Errorf("No difference seen for %s", field)
+		}
+	}
+	for _, field := range []string{"Issuer", "NotBefore", "Subject.O", "Extensions[Key Usage].Value", "Extensions.Order"} {
+		if _, ok := fields[field]; ok {
+			t.Errorf("Unexpected difference for %s", field)
+		}
+	}
+
+	if !strings.Contains(diff.Text, "Subject.CN:\n    - foo.example.com\n    + bar.example.com\n") {
+		t.Errorf("Unexpected diff text:\n%s", diff.Text)
+	}
+	if !strings.Contains(diff.Text, "Extensions[CRL Distribution Points]:\n    - <absent>\n    + Full Name:\n    +   URI:http://crl.example.com/intermediate.crl\n") {
+		t.Errorf("Unexpected diff text:\n%s", diff.Text)
+	}
+}
+
+func TestDiffCertificatesNameOrder(t *testing.T) {
+	t.Parallel()
+
+	a, err := tls.GenerateCertificate(diffRequest(), nil)
+	if err != nil {
+		t.Fatalf("Error generating certificate: %s", err.Error())
+	}
+	request := diffRequest()
+	request.Subject = tls.Name{
+		RDNs: [][]tls.NameAttribute{
+			{{OID: "2.5.4.3", Value: "foo.example.com"}},
+			{{OID: "2.5.4.10", Value: "example.com"}},
+			{{OID: "2.5.4.6", Value: "CA"}},
+		},
+	}
+	b, err := tls.GenerateCertificate(request, nil)
+	if err != nil {
+		t.Fatalf("Error generating certificate: %s", err.Error())
+	}
+
+	diff, err := tls.DiffCertificates(*a, *b)
+	if err != nil {
+		t.Fatalf("Error comparing certificates: %s", err.Error())
+	}
+	fields := differenceFields(diff)
+	if _, ok := fields["Subject"]; !ok {
+		t.Errorf("No difference seen for subject order. Differences: %+v", diff.Differences)
+	}
+	if _, ok := fields["Subject.CN"]; ok {
+		t.Errorf("Unexpected difference for subject common name")
+	}
+}
+
+func TestDiffCertificateRequest(t *testing.T) {
+	t.Parallel()
+
+	chain := generateLongCertificateChain(t)
+	issuer := chain[1]
+
+	certificate, err := tls.GenerateCertificate(diffRequest(), issuer)
+	if err != nil {
+		t.Fatalf("Error generating certificate: %s", err.Error())
+	}
+
+	diff, err := tls.DiffCertificateRequest(*certificate, diffRequest(), issuer)
+	if err != nil {
+		t.Fatalf("Error comparing certificate: %s", err.Error())
+	}
+	if len(diff.Differences) != 0 {
+		t.Errorf("Unexpected differences with the request the certificate was generated from:\n%s", diff.Text)
+	}
+
+	request := diffRequest()
+	request.Usage.KeyEncipherment = true
+	request.Serial = tls.SerialNumber{Strategy: tls.SerialStrategyExplicit, Value: "1"}
+	diff, err = tls.DiffCertificateRequest(*certificate, request, issuer)
+	if err != nil {
+		t.Fatalf("Error comparing certificate: %s", err.Error())
+	}
+	fields := differenceFields(diff)
+	if len(fields) != 2 {
+		t.Errorf("Unexpected differences:\n%s", diff.Text)
+	}
+	if difference := fields["Extensions[Key Usage].Value"]; difference.A != "Digital Signature" || difference.B != "Digital Signature, Key Encipherment" {
+		t.Errorf("Unexpected key usage difference %+v", difference)
+	}
+	if difference := fields["Serial"]; difference.B != "01" {
+		t.Errorf("Unexpected serial difference %+v", difference)
+	}
+
+	// Only the prefix of a prefixed serial number is compared
+	request = diffRequest()
+	request.Serial = tls.SerialNumber{Strategy: tls.SerialStrategyPrefixed, Value: "0x5eed", RandomBits: 32}
+	prefixed, err := tls.GenerateCertificate(request, issuer)
+	if err != nil {
+		t.Fatalf("Error generating certificate: %s", err.Error())
+	}
+	diff, err = tls.DiffCertificateRequest(*prefixed, request, issuer)
+	if err != nil {
+		t.Fatalf("Error comparing certificate: %s", err.Error())
+	}
+	if len(diff.Differences) != 0 {
+		t.Errorf("Unexpected differences with the request the certificate was generated from:\n%s", diff.Text)
+	}
+	request.Serial.Value = "0x5eee"
+	diff, err = tls.DiffCertificateRequest(*prefixed, request, issuer)
+	if err != nil {
+		t.Fatalf("Error comparing certificate: %s", err.Error())
+	}
+	if _, ok := differenceFields(diff)["Serial"]; !ok || len(diff.Differences) != 1 {
+		t.Errorf("Unexpected differences:\n%s", diff.Text)
+	}
+}
+
+func TestDiffCertificatesInvalid(t *testing.T) {
+	t.Parallel()
+
+	certificate, err := tls.GenerateCertificate(diffRequest(), nil)
+	if err != nil {
+		t.Fatalf("Error generating certificate: %s", err.Error())
+	}
+	invalid := tls.Certificate{CertificateData: "invalid"}
+
+	if _, err := tls.DiffCertificates(*certificate, invalid); err == nil {
+		t.Errorf("No error seen when one expected for invalid certificate")
+	}
+	if _, err := tls.DiffCertificateRequest(invalid, diffRequest(), nil); err == nil {
+		t.Errorf("No error seen when one expected for invalid certificate")
+	}
+	request := diffRequest()
+	request.KeyType = "invalid"
+	if _, err := tls.DiffCertificateRequest(*certificate, request, nil); err == nil {
+		t.Errorf("No error seen when one expected for invalid request")
+	}
+}
@@ -0,0 +1,218 @@
+package tls
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"math/big"
+	mrand "math/rand/v2"
+	"sync"
+)
+
+// entropy is the source of randomness for generating keys, serial numbers and signatures. It is passed to everything
+// that needs randomness rather than being global, so deterministic generation never affects other callers.
+type entropy struct {
+	reader io.Reader
+	// deterministic is set if reader is derived from a seed
+	deterministic bool
+}
+
+// secureEntropy is used for everything other than DeterministicEntropy
+var secureEntropy = &entropy{reader: rand.Reader}
+
+// deterministicReader is a stream of pseudo-random bytes derived from a seed
+type deterministicReader struct {
+	lock   sync.Mutex
+	stream *mrand.ChaCha8
+}
+
+func (r *deterministicReader) Read(p []byte) (int, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return r.stream.Read(p)
+}
+
+// DeterministicEntropy generates certificates, CSRs and PKCS12 files with every key, serial number, signature and
+// PKCS12 salt derived from a seed instead of a secure source of randomness. Making the same calls in the same order
+// with the same seed produces byte-identical output. Validity dates must be absolute for the output to be
+// reproducible.
+//
+// THIS IS ONLY FOR TESTS. Anybody who knows the seed can recreate the private keys.
+type DeterministicEntropy struct {
+	entropy *entropy
+}
+
+// NewDeterministicEntropy returns deterministic entropy derived from the given seed
+func NewDeterministicEntropy(seed string) (*DeterministicEntropy, error) {
+	if seed == "" {
+		return nil, fmt.Errorf("a seed is required for deterministic entropy")
+	}
+
+	reader := &deterministicReader{stream: mrand.NewChaCha8(sha256.Sum256([]byte(seed)))}
+	return &DeterministicEntropy{entropy: &entropy{reader: reader, deterministic: true}}, nil
+}
+
+// GenerateCertificate is GenerateCertificate with deterministic entropy
+func (d *DeterministicEntropy) GenerateCertificate(request CertificateRequest, issuer *Certificate) (*Certificate, error) {
+	return generateCertificate(request, issuer, d.entropy)
+}
+
+// GenerateCSR is GenerateCSR with deterministic entropy
+func (d *DeterministicEntropy) GenerateCSR(request CertificateRequest) ([]byte, []byte, error) {
+	return generateCSR(request, d.entropy)
+}
+
+// ExportCSR is ExportCSR with deterministic entropy
+func (d *DeterministicEntropy) ExportCSR(request *CertificateRequest) ([]byte, []byte, error) {
+	return exportCSR(request, d.entropy)
+}
+
+// ExportPKCS12 is ExportPKCS12 with deterministic entropy
+func (d *DeterministicEntropy) ExportPKCS12(certificate *Certificate, issuer *Certificate, password string) ([]byte, error) {
+	return exportPKCS12(certificate, issuer, password, d.entropy)
+}
+
+// signer returns pKey as a signer. With deterministic entropy ECDSA signatures are produced as described in RFC 6979,
+// since otherwise they are always randomized.
+func (e *entropy) signer(pKey crypto.PrivateKey) crypto.Signer {
+	signer := pKey.(crypto.Signer)
+	if _, isECDSA := signer.(*ecdsa.PrivateKey); isECDSA && e.deterministic {
+		return deterministicECDSASigner{signer}
+	}
+	return signer
+}
+
+func (e *entropy) randomSerialNumber() (*big.Int, error) {
+	serialNumberLimit := new(big.Int).Lsh(big.NewInt(1), 128)
+	return rand.Int(e.reader, serialNumberLimit)
+}
+
+func (e *entropy) generateRSAKey(length int) (crypto.PrivateKey, error) {
+	if e.deterministic {
+		return deterministicRSAKey(length, e.reader)
+	}
+	return rsa.GenerateKey(e.reader, length)
+}
+
+func (e *entropy) generateECDSAKey(curve elliptic.Curve) (crypto.PrivateKey, error) {
+	if e.deterministic {
+		return deterministicECDSAKey(curve, e.reader)
+	}
+	return ecdsa.GenerateKey(curve, e.reader)
+}
+
+func (e *entropy) generateEd25519Key() (crypto.PrivateKey, error) {
+	if e.deterministic {
+		seed := make([]byte, ed25519.SeedSize)
+		if _, err := io.ReadFull(e.reader, seed); err != nil {
+			return nil, err
+		}
+		return ed25519.NewKeyFromSeed(seed), nil
+	}
+	_, pKey, err := ed25519.GenerateKey(e.reader)
+	return pKey, err
+}
+
+type deterministicECDSASigner struct {
+	crypto.Signer
+}
+
+func (s deterministicECDSASigner) Sign(_ io.Reader, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	return s.Signer.Sign(nil, digest, opts)
+}
+
+// The standard library mixes secure randomness into generated keys regardless of the reader it's given, so keys
+// are derived directly from the deterministic stream instead.
+
+func deterministicECDSAKey(curve elliptic.Curve, r io.Reader) (crypto.PrivateKey, error) {
+	params := curve.Params()
+	b := make([]byte, params.BitSize/8+8)
+	if _, err := io.ReadFull(r, b); err != nil {
+		return nil, err
+	}
+
+	// Reduce a value with 64 extra bits into [1, N-1] so that any bias is negligible
+	d := new(big.Int).SetBytes(b)
+	d.Mod(d, new(big.Int).Sub(params.N, big.NewInt(1)))
+	d.Add(d, big.NewInt(1))
+
+	pKey := &ecdsa.PrivateKey{D: d}
+	pKey.Curve = curve
+	pKey.X, pKey.Y = curve.ScalarBaseMult(d.FillBytes(make([]byte, (params.BitSize+7)/8)))
+	return pKey, nil
+}
+
+func deterministicRSAKey(bits int, r io.Reader) (crypto.PrivateKey, error) {
+	e := big.NewInt(65537)
+	one := big.NewInt(1)
+	for {
+		p, err := deterministicPrime(bits-bits/2, r)
+		if err != nil {
+			return nil, err
+		}
+		q, err := deterministicPrime(bits/2, r)
+		if err != nil {
+			return nil, err
+		}
+		if p.Cmp(q) == 0 {
+			continue
+		}
+
+		n := new(big.Int).Mul(p, q)
+		if n.BitLen() != bits {
+			continue
+		}
+		totient := new(big.Int).Mul(new(big.Int).Sub(p, one), new(big.Int).Sub(q, one))
+		d := new(big.Int).ModInverse(e, totient)
+		if d == nil {
+			continue
+		}
+
+		pKey := &rsa.PrivateKey{
+			PublicKey: rsa.PublicKey{N: n, E: int(e.Int64())},
+			D:         d,
+			Primes:    []*big.Int{p, q},
+		}
+		if err := pKey.Validate(); err != nil {
+			return nil, err
+		}
+		pKey.Precompute()
+		return pKey, nil
+	}
+}
+
+// deterministicPrime returns the first prime at or after a random odd number of the given length with its top two bits
+// set, so the product of two such primes has exactly twice as many bits
+func deterministicPrime(bits int, r io.Reader) (*big.Int, error) {
+	b := make([]byte, (bits+7)/8)
+	if _, err := io.ReadFull(r, b); err != nil {
+		return nil, err
+	}
+
+	topBits := uint(bits % 8)
+	if topBits == 0 {
+		topBits = 8
+	}
+	b[0] &= uint8(int(1<<topBits) - 1)
+	if topBits >= 2 {
+		b[0] |= 3 << (topBits - 2)
+	} else {
+		b[0] |= 1
+		b[1] |= 0x80
+	}
+	b[len(b)-1] |= 1
+
+	two := big.NewInt(2)
+	for p := new(big.Int).SetBytes(b); p.BitLen() == bits; p.Add(p, two) {
+		if p.ProbablyPrime(20) {
+			return p, nil
+		}
+	}
+	return deterministicPrime(bits, r)
+}
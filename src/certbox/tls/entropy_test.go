@@ -0,0 +1,104 @@
+package tls_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/tls-inspector/certbox/tls"
+)
+
+func deterministicRequest(keyType, signatureAlgorithm string) tls.CertificateRequest {
+	return tls.CertificateRequest{
+		KeyType:            keyType,
+		SignatureAlgorithm: signatureAlgorithm,
+		Subject: tls.Name{
+			Organization: "example.com",
+			CommonName:   "example.com Fixture",
+		},
+		Validity: tls.DateRange{
+			NotBefore: "2001-01-01",
+			NotAfter:  "2002-01-01",
+		},
+		Usage: tls.KeyUsage{
+			DigitalSignature: true,
+			CertSign:         true,
+		},
+		IsCertificateAuthority: true,
+	}
+}
+
+// generateDeterministic returns the certificate, CSR and PKCS12 data generated for request with the given seed
+func generateDeterministic(t *testing.T, seed string, request tls.CertificateRequest) (*tls.Certificate, []byte, []byte) {
+	entropy, err := tls.NewDeterministicEntropy(seed)
+	if err != nil {
+		t.Fatalf("Error creating deterministic entropy: %s", err.Error())
+	}
+	certificate, err := entropy.GenerateCertificate(request, nil)
+	if err != nil {
+		t.Fatalf("Error generating %s certificate: %s", request.KeyType, err.Error())
+	}
+	csr, _, err := entropy.ExportCSR(&request)
+	if err != nil {
+		t.Fatalf("Error generating %s CSR: %s", request.KeyType, err.Error())
+	}
+	p12, err := entropy.ExportPKCS12(certificate, nil, "password")
+	if err != nil {
+		t.Fatalf("Error exporting %s certificate: %s", request.KeyType, err.Error())
+	}
+	return certificate, csr, p12
+}
+
+func TestDeterministicEntropy(t *testing.T) {
+	t.Parallel()
+
+	requests := []tls.CertificateRequest{
+		deterministicRequest(tls.KeyTypeRSA_2048, tls.SignatureAlgorithmSHA256),
+		deterministicRequest(tls.KeyTypeRSA_2048, tls.SignatureAlgorithmSHA256PSS),
+		deterministicRequest(tls.KeyTypeECDSA_256, tls.SignatureAlgorithmSHA256),
+		deterministicRequest(tls.KeyTypeECDSA_384, tls.SignatureAlgorithmSHA384),
+		deterministicRequest(tls.KeyTypeECDSA_521, tls.SignatureAlgorithmSHA512),
+		deterministicRequest(tls.KeyTypeEd25519, ""),
+	}
+
+	for _, request := range requests {
+		certificate, csr, p12 := generateDeterministic(t, "fixture", request)
+		again, csrAgain, p12Again := generateDeterministic(t, "fixture", request)
+		if certificate.CertificateData != again.CertificateData || certificate.KeyData != again.KeyData || certificate.Serial != again.Serial {
+			t.Errorf("%s %s certificate is not reproducible", request.KeyType, request.SignatureAlgorithm)
+		}
+		if !bytes.Equal(csr, csrAgain) {
+			t.Errorf("%s %s CSR is not reproducible", request.KeyType, request.SignatureAlgorithm)
+		}
+		if !bytes.Equal(p12, p12Again) {
+			t.Errorf("%s %s PKCS12 is not reproducible", request.KeyType, request.SignatureAlgorithm)
+		}
+
+		x := certificate.X509()
+		if err := x.CheckSignature(x.SignatureAlgorithm, x.RawTBSCertificate, x.Signature); err != nil {
+			t.Errorf("Deterministic %s certificate has an invalid signature: %s", request.KeyType, err.Error())
+		}
+
+		other, _, _ := generateDeterministic(t, "other fixture", request)
+		if other.KeyData == certificate.KeyData || other.Serial == certificate.Serial {
+			t.Errorf("%s certificates generated with different seeds should not match", request.KeyType)
+		}
+	}
+
+	// Generation without deterministic entropy remains random
+	request := requests[2]
+	first, err := tls.GenerateCertificate(request, nil)
+	if err != nil {
+		t.Fatalf("Error generating certificate: %s", err.Error())
+	}
+	second, err := tls.GenerateCertificate(request, nil)
+	if err != nil {
+		t.Fatalf("Error generating certificate: %s", err.Error())
+	}
+	if first.KeyData == second.KeyData {
+		t.Errorf("Certificates generated without a seed should not match")
+	}
+
+	if _, err := tls.NewDeterministicEntropy(""); err == nil {
+		t.Errorf("No error seen when one expected for empty seed")
+	}
+}
@@ -1,9 +1,9 @@
 package tls
 
 import (
-	"crypto/rand"
 	"crypto/x509"
 	"encoding/pem"
+	"fmt"
 
 	pkcs12 "software.sslmate.com/src/go-pkcs12"
 )
@@ -14,18 +14,29 @@ import (
 //
 // A password is required. Providing an empty string will return an error.
 func ExportPKCS12(certificate *Certificate, issuer *Certificate, password string) ([]byte, error) {
+	return exportPKCS12(certificate, issuer, password, secureEntropy)
+}
+
+func exportPKCS12(certificate *Certificate, issuer *Certificate, password string, e *entropy) ([]byte, error) {
+	if certificate.KeyData == "" {
+		return nil, fmt.Errorf("certificate has no private key")
+	}
+
 	caCerts := []*x509.Certificate{}
 	if issuer != nil {
 		caCerts = append(caCerts, issuer.X509())
 	}
 
-	return pkcs12.Encode(rand.Reader, certificate.PKey(), certificate.X509(), caCerts, password)
+	return pkcs12.Encode(e.reader, certificate.PKey(), certificate.X509(), caCerts, password)
 }
 
 // ExportPEM will generate PEM files for the certificate and private key.
-// Returns the certificate data, key data, and optional error.
+// Returns the certificate data, key data, and optional error. Key data is nil if the certificate has no key.
 func ExportPEM(certificate *Certificate) ([]byte, []byte, error) {
 	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certificate.certificateDataBytes()})
+	if certificate.KeyData == "" {
+		return certPEM, nil, nil
+	}
 
 	blockType := ""
 	switch certificate.X509().PublicKeyAlgorithm {
@@ -44,7 +55,11 @@ func ExportPEM(certificate *Certificate) ([]byte, []byte, error) {
 // ExportCSR will generate PEM files for the certificate and private key.
 // Returns the certificate data, key data, and optional error.
 func ExportCSR(certificate *CertificateRequest) ([]byte, []byte, error) {
-	csr, pkey, err := GenerateCSR(*certificate)
+	return exportCSR(certificate, secureEntropy)
+}
+
+func exportCSR(certificate *CertificateRequest, e *entropy) ([]byte, []byte, error) {
+	csr, pkey, err := generateCSR(*certificate, e)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -52,11 +67,12 @@ func ExportCSR(certificate *CertificateRequest) ([]byte, []byte, error) {
 	csrPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: csr})
 
 	blockType := ""
-	if certificate.KeyType[0] == 'r' {
+	switch certificate.KeyType {
+	case KeyTypeRSA_2048, KeyTypeRSA_3072, KeyTypeRSA_4096, KeyTypeRSA_8192:
 		blockType = "RSA PRIVATE KEY"
-	} else if certificate.KeyType[0] == 'e' {
+	case KeyTypeECDSA_256, KeyTypeECDSA_384, KeyTypeECDSA_521:
 		blockType = "EC PRIVATE KEY"
-	} else {
+	default:
 		blockType = "PRIVATE KEY"
 	}
 	keyPEM := pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: pkey})
@@ -65,7 +81,10 @@ func ExportCSR(certificate *CertificateRequest) ([]byte, []byte, error) {
 }
 
 // ExportDER will generate DER files for the certificate and private key.
-// Returns the certificate data, key data, and optional error.
+// Returns the certificate data, key data, and optional error. Key data is nil if the certificate has no key.
 func ExportDER(certificate *Certificate) ([]byte, []byte, error) {
+	if certificate.KeyData == "" {
+		return certificate.certificateDataBytes(), nil, nil
+	}
 	return certificate.certificateDataBytes(), certificate.keyDataBytes(), nil
 }
@@ -41,3 +41,59 @@ func TestExportPEM(t *testing.T) {
 		t.Fatalf("Empty PEM key data")
 	}
 }
+
+func TestExportKeyTypes(t *testing.T) {
+	t.Parallel()
+
+	keyTypes := []string{
+		tls.KeyTypeRSA_3072,
+		tls.KeyTypeECDSA_521,
+		tls.KeyTypeEd25519,
+	}
+
+	for _, keyType := range keyTypes {
+		request := tls.CertificateRequest{
+			KeyType:            keyType,
+			SignatureAlgorithm: tls.SignatureAlgorithmSHA384,
+			Subject: tls.Name{
+				CommonName: "example.com Example Root",
+			},
+			Validity: tls.DateRange{
+				NotBefore: "2001-01-01",
+				NotAfter:  "2002-01-01",
+			},
+			Usage: tls.KeyUsage{
+				DigitalSignature: true,
+				CertSign:         true,
+			},
+			IsCertificateAuthority: true,
+		}
+		root, err := tls.GenerateCertificate(request, nil)
+		if err != nil {
+			t.Fatalf("Error generating %s certificate: %s", keyType, err.Error())
+		}
+
+		request.Subject.CommonName = "foo.example.com"
+		request.IsCertificateAuthority = false
+		leaf, err := tls.GenerateCertificate(request, root)
+		if err != nil {
+			t.Fatalf("Error generating %s certificate: %s", keyType, err.Error())
+		}
+		if err := leaf.X509().CheckSignatureFrom(root.X509()); err != nil {
+			t.Errorf("Invalid %s signature: %s", keyType, err.Error())
+		}
+
+		if _, _, err := tls.ExportPEM(leaf); err != nil {
+			t.Errorf("Error generating %s PEM export: %s", keyType, err.Error())
+		}
+		if _, _, err := tls.ExportDER(leaf); err != nil {
+			t.Errorf("Error generating %s DER export: %s", keyType, err.Error())
+		}
+		if _, err := tls.ExportPKCS12(leaf, root, "12345678"); err != nil {
+			t.Errorf("Error generating %s PKCS12 export: %s", keyType, err.Error())
+		}
+		if _, _, err := tls.ExportCSR(&request); err != nil {
+			t.Errorf("Error generating %s CSR export: %s", keyType, err.Error())
+		}
+	}
+}
@@ -0,0 +1,322 @@
+package tls
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	_ "crypto/md5" // Required to sign MD5WithRSA certificates
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/hex"
+	"fmt"
+)
+
+// FaithfulCloneOptions describes the options for a faithful clone of a certificate
+type FaithfulCloneOptions struct {
+	// KeyType is the type of the new subject key. Defaults to the algorithm and size of the original key.
+	KeyType string
+}
+
+// FaithfulClone returns a copy of the given certificate that is identical to the original except for its subject
+// public key and signature. The serial number, issuer and subject are kept exactly as encoded, as are the validity and
+// all extensions, including the subject and authority key identifiers. The signature algorithm of the original is also
+// kept, so the signing key must be of a matching type.
+//
+// The clone is signed by issuer if one is given. Otherwise a self-signed certificate is signed with its own new key,
+// and any other certificate is signed by a fake issuer with the issuer name and key identifier of the original. The
+// fake issuer chain is returned, ordered from the immediate issuer to the root, and is empty if no fake issuer was
+// needed.
+func FaithfulClone(certificate Certificate, issuer *Certificate, options FaithfulCloneOptions) (*Certificate, []*Certificate, error) {
+	x := certificate.X509()
+
+	pKey, err := faithfulKey(x.PublicKey, options.KeyType)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	chain := []*Certificate{}
+	signer := pKey.(crypto.Signer)
+	if issuer != nil {
+		if issuer.KeyData == "" {
+			return nil, nil, fmt.Errorf("issuer has no private key")
+		}
+		signer = issuer.PKey().(crypto.Signer)
+	} else if !isSelfIssued(x) {
+		fake, err := fakeIssuer(x)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error generating fake issuer: %s", err.Error())
+		}
+		chain = append(chain, fake)
+		signer = fake.PKey().(crypto.Signer)
+	}
+
+	certBytes, err := faithfulCertificate(x, pKey.(crypto.Signer).Public(), signer)
+	if err != nil {
+		return nil, nil, err
+	}
+	pKeyBytes, err := x509.MarshalPKCS8PrivateKey(pKey)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	clone := &Certificate{
+		CertificateAuthority: x.IsCA,
+		CertificateData:      hex.EncodeToString(certBytes),
+		KeyData:              hex.EncodeToString(pKeyBytes),
+	}
+	clone.setSerial(x.SerialNumber)
+	clone.Subject = nameFromRaw(clone.X509().RawSubject)
+	return clone, chain, nil
+}
+
+// FaithfulCloneChain returns a faithful clone of every certificate in chain, which must be ordered from the leaf to the
+// root with each certificate issued by the one that follows it. Each clone is signed by the clone of its issuer, so the
+// cloned chain validates against the cloned root. The subject, issuer, extensions and key identifiers of every
+// certificate are kept, so the authority key identifier of each clone matches the subject key identifier of its
+// parent as it did in the original chain.
+//
+// The clones are returned in the same order as chain. If the last certificate is not self-signed, the fake issuer that
+// signed its clone is appended. The options apply to every certificate, so any key type must be able to produce the
+// signature algorithm of each certificate it signs.
+func FaithfulCloneChain(chain []Certificate, options FaithfulCloneOptions) ([]*Certificate, error) {
+	if len(chain) == 0 {
+		return nil, fmt.Errorf("no certificates to clone")
+	}
+
+	for i := 0; i < len(chain)-1; i++ {
+		x, parent := chain[i].X509(), chain[i+1].X509()
+		if !bytes.Equal(x.RawIssuer, parent.RawSubject) {
+			return nil, fmt.Errorf("certificate '%s' is not issued by '%s'", chain[i].Subject.CommonName, chain[i+1].Subject.CommonName)
+		}
+	}
+
+	clones := make([]*Certificate, len(chain))
+	root, fakeChain, err := FaithfulClone(chain[len(chain)-1], nil, options)
+	if err != nil {
+		return nil, fmt.Errorf("error cloning '%s': %s", chain[len(chain)-1].Subject.CommonName, err.Error())
+	}
+	clones[len(chain)-1] = root
+
+	for i := len(chain) - 2; i >= 0; i-- {
+		clone, _, err := FaithfulClone(chain[i], clones[i+1], options)
+		if err != nil {
+			return nil, fmt.Errorf("error cloning '%s': %s", chain[i].Subject.CommonName, err.Error())
+		}
+		clones[i] = clone
+	}
+
+	return append(clones, fakeChain...), nil
+}
+
+// isSelfIssued returns true if the issuer of the given certificate is its own subject, and if it identifies its
+// authority key, that the authority key is its own
+func isSelfIssued(x *x509.Certificate) bool {
+	if !bytes.Equal(x.RawIssuer, x.RawSubject) {
+		return false
+	}
+	return len(x.AuthorityKeyId) == 0 || bytes.Equal(x.AuthorityKeyId, x.SubjectKeyId)
+}
+
+// faithfulKey returns a new private key of the given key type, or of the same algorithm and size as pub if no key type
+// is specified
+func faithfulKey(pub crypto.PublicKey, keyType string) (crypto.PrivateKey, error) {
+	if keyType != "" {
+		request := CertificateRequest{KeyType: keyType}
+		return request.generatePrivateKey(secureEntropy)
+	}
+
+	switch publicKey := pub.(type) {
+	case *rsa.PublicKey:
+		return secureEntropy.generateRSAKey(publicKey.N.BitLen())
+	case *ecdsa.PublicKey:
+		return secureEntropy.generateECDSAKey(publicKey.Curve)
+	case ed25519.PublicKey:
+		return secureEntropy.generateEd25519Key()
+	}
+	return nil, fmt.Errorf("unsupported public key algorithm %s, a key type must be specified", publicKeyAlgorithmName(pub))
+}
+
+// fakeIssuer returns a self-signed certificate authority with the issuer name and authority key identifier of the
+// given certificate, and a key that can produce its signature algorithm
+func fakeIssuer(x *x509.Certificate) (*Certificate, error) {
+	keyAlgorithm, opts, err := signatureParameters(x.SignatureAlgorithm)
+	if err != nil {
+		return nil, err
+	}
+
+	var pKey crypto.PrivateKey
+	switch keyAlgorithm {
+	case x509.RSA:
+		// The length of an RSA signature is the length of the key that produced it
+		pKey, err = secureEntropy.generateRSAKey(len(x.Signature) * 8)
+	case x509.ECDSA:
+		switch opts.HashFunc() {
+		case crypto.SHA384:
+			pKey, err = secureEntropy.generateECDSAKey(elliptic.P384())
+		case crypto.SHA512:
+			pKey, err = secureEntropy.generateECDSAKey(elliptic.P521())
+		default:
+			pKey, err = secureEntropy.generateECDSAKey(elliptic.P256())
+		}
+	case x509.Ed25519:
+		pKey, err = secureEntropy.generateEd25519Key()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	serial, err := secureEntropy.randomSerialNumber()
+	if err != nil {
+		return nil, err
+	}
+	tpl := &x509.Certificate{
+		SerialNumber:          serial,
+		RawSubject:            x.RawIssuer,
+		NotBefore:             x.NotBefore,
+		NotAfter:              x.NotAfter,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		SubjectKeyId:          x.AuthorityKeyId,
+	}
+
+	pub := pKey.(crypto.Signer).Public()
+	certBytes, err := x509.CreateCertificate(secureEntropy.reader, tpl, tpl, pub, secureEntropy.signer(pKey))
+	if err != nil {
+		return nil, err
+	}
+	pKeyBytes, err := x509.MarshalPKCS8PrivateKey(pKey)
+	if err != nil {
+		return nil, err
+	}
+
+	certificate := &Certificate{
+		CertificateAuthority: true,
+		CertificateData:      hex.EncodeToString(certBytes),
+		KeyData:              hex.EncodeToString(pKeyBytes),
+	}
+	certificate.setSerial(serial)
+	certificate.Subject = nameFromRaw(certificate.X509().RawSubject)
+	return certificate, nil
+}
+
+// faithfulCertificate returns the DER encoding of the given certificate with its subject public key replaced by pub,
+// signed by signer. All other bytes of the TBSCertificate are unchanged.
+func faithfulCertificate(x *x509.Certificate, pub crypto.PublicKey, signer crypto.Signer) ([]byte, error) {
+	var certificate struct {
+		TBSCertificate     asn1.RawValue
+		SignatureAlgorithm asn1.RawValue
+		Signature          asn1.BitString
+	}
+	if _, err := asn1.Unmarshal(x.Raw, &certificate); err != nil {
+		return nil, fmt.Errorf("invalid certificate: %s", err.Error())
+	}
+
+	elements := [][]byte{}
+	for data := certificate.TBSCertificate.Bytes; len(data) > 0; {
+		var element asn1.RawValue
+		var err error
+		data, err = asn1.Unmarshal(data, &element)
+		if err != nil {
+			return nil, fmt.Errorf("invalid certificate: %s", err.Error())
+		}
+		elements = append(elements, element.FullBytes)
+	}
+
+	// The version is optional and precedes the serial number, signature, issuer, validity, subject and public key
+	publicKeyIdx := 5
+	if len(elements) > 0 && elements[0][0] == 0xa0 {
+		publicKeyIdx++
+	}
+	if len(elements) <= publicKeyIdx {
+		return nil, fmt.Errorf("invalid certificate: missing subject public key info")
+	}
+
+	publicKeyBytes, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		return nil, err
+	}
+	elements[publicKeyIdx] = publicKeyBytes
+
+	tbs, err := asn1.Marshal(asn1.RawValue{Tag: asn1.TagSequence, IsCompound: true, Bytes: bytes.Join(elements, nil)})
+	if err != nil {
+		return nil, err
+	}
+
+	signature, err := signWithAlgorithm(signer, x.SignatureAlgorithm, tbs)
+	if err != nil {
+		return nil, err
+	}
+
+	certificate.TBSCertificate = asn1.RawValue{FullBytes: tbs}
+	certificate.Signature = asn1.BitString{Bytes: signature, BitLength: len(signature) * 8}
+	return asn1.Marshal(certificate)
+}
+
+// signWithAlgorithm returns the signature of data by signer using the given signature algorithm
+func signWithAlgorithm(signer crypto.Signer, algorithm x509.SignatureAlgorithm, data []byte) ([]byte, error) {
+	keyAlgorithm, opts, err := signatureParameters(algorithm)
+	if err != nil {
+		return nil, err
+	}
+	if x509PublicKeyAlgorithm(signer.Public()) != keyAlgorithm {
+		return nil, fmt.Errorf("%s signing key can not produce a %s signature", publicKeyAlgorithmName(signer.Public()), algorithm)
+	}
+
+	digest := data
+	if hash := opts.HashFunc(); hash != 0 {
+		h := hash.New()
+		h.Write(data)
+		digest = h.Sum(nil)
+	}
+	return signer.Sign(secureEntropy.reader, digest, opts)
+}
+
+// signatureParameters returns the public key algorithm and signer options that produce the given signature algorithm
+func signatureParameters(algorithm x509.SignatureAlgorithm) (x509.PublicKeyAlgorithm, crypto.SignerOpts, error) {
+	switch algorithm {
+	case x509.MD5WithRSA:
+		return x509.RSA, crypto.MD5, nil
+	case x509.SHA1WithRSA:
+		return x509.RSA, crypto.SHA1, nil
+	case x509.SHA256WithRSA:
+		return x509.RSA, crypto.SHA256, nil
+	case x509.SHA384WithRSA:
+		return x509.RSA, crypto.SHA384, nil
+	case x509.SHA512WithRSA:
+		return x509.RSA, crypto.SHA512, nil
+	case x509.SHA256WithRSAPSS:
+		return x509.RSA, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash, Hash: crypto.SHA256}, nil
+	case x509.SHA384WithRSAPSS:
+		return x509.RSA, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash, Hash: crypto.SHA384}, nil
+	case x509.SHA512WithRSAPSS:
+		return x509.RSA, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash, Hash: crypto.SHA512}, nil
+	case x509.ECDSAWithSHA1:
+		return x509.ECDSA, crypto.SHA1, nil
+	case x509.ECDSAWithSHA256:
+		return x509.ECDSA, crypto.SHA256, nil
+	case x509.ECDSAWithSHA384:
+		return x509.ECDSA, crypto.SHA384, nil
+	case x509.ECDSAWithSHA512:
+		return x509.ECDSA, crypto.SHA512, nil
+	case x509.PureEd25519:
+		return x509.Ed25519, crypto.Hash(0), nil
+	}
+	return x509.UnknownPublicKeyAlgorithm, nil, fmt.Errorf("unsupported signature algorithm %s", algorithm)
+}
+
+// x509PublicKeyAlgorithm returns the public key algorithm of the given public key
+func x509PublicKeyAlgorithm(pub crypto.PublicKey) x509.PublicKeyAlgorithm {
+	switch pub.(type) {
+	case *rsa.PublicKey:
+		return x509.RSA
+	case *ecdsa.PublicKey:
+		return x509.ECDSA
+	case ed25519.PublicKey:
+		return x509.Ed25519
+	}
+	return x509.UnknownPublicKeyAlgorithm
+}
@@ -0,0 +1,279 @@
+package tls_test
+
+import (
+	"bytes"
+	"crypto/rsa"
+	"crypto/x509"
+	"strings"
+	"testing"
+
+	"github.com/tls-inspector/certbox/tls"
+)
+
+// assertFaithfulClone fails the test if clone differs from original in anything but its public key and signature
+func assertFaithfulClone(t *testing.T, original, clone *x509.Certificate) {
+	if bytes.Equal(original.RawSubjectPublicKeyInfo, clone.RawSubjectPublicKeyInfo) {
+		t.Errorf("Clone of '%s' has the same public key as the original", original.Subject.CommonName)
+	}
+	if original.SerialNumber.Cmp(clone.SerialNumber) != 0 {
+		t.Errorf("Clone serial number does not match. Expected %s got %s", original.SerialNumber, clone.SerialNumber)
+	}
+	if !bytes.Equal(original.RawSubject, clone.RawSubject) || !bytes.Equal(original.RawIssuer, clone.RawIssuer) {
+		t.Errorf("Clone subject or issuer does not match the original")
+	}
+	if !original.NotBefore.Equal(clone.NotBefore) || !original.NotAfter.Equal(clone.NotAfter) {
+		t.Errorf("Clone validity does not match the original")
+	}
+	if original.SignatureAlgorithm != clone.SignatureAlgorithm {
+		t.Errorf("Clone signature algorithm does not match. Expected %s got %s", original.SignatureAlgorithm, clone.SignatureAlgorithm)
+	}
+	if len(original.Extensions) != len(clone.Extensions) {
+		t.Fatalf("Clone has %d extensions, expected %d", len(clone.Extensions), len(original.Extensions))
+	}
+	for i, extension := range original.Extensions {
+		cloned := clone.Extensions[i]
+		if !extension.Id.Equal(cloned.Id) || extension.Critical != cloned.Critical || !bytes.Equal(extension.Value, cloned.Value) {
+			t.Errorf("Cloned extension %s does not match the original", extension.Id)
+		}
+	}
+
+	originalCertificate, err := tls.ImportDERCertificate(original.Raw)
+	if err != nil {
+		t.Fatalf("Error importing certificate: %s", err.Error())
+	}
+	cloneCertificate, err := tls.ImportDERCertificate(clone.Raw)
+	if err != nil {
+		t.Fatalf("Error importing certificate: %s", err.Error())
+	}
+	diff, err := tls.DiffCertificates(*originalCertificate, *cloneCertificate)
+	if err != nil {
+		t.Fatalf("Error comparing certificates: %s", err.Error())
+	}
+	for _, difference := range diff.Differences {
+		if !strings.HasPrefix(difference.Field, "PublicKey.") {
+			t.Errorf("Clone of '%s' differs from the original:\n%s", original.Subject.CommonName, diff.Text)
+			break
+		}
+	}
+}
+
+func TestFaithfulClone(t *testing.T) {
+	t.Parallel()
+
+	root, leaf, err := generateCertificateChain()
+	if err != nil {
+		t.Fatalf("Error generating certificate chain: %s", err.Error())
+	}
+
+	clone, chain, err := tls.FaithfulClone(*leaf, nil, tls.FaithfulCloneOptions{})
+	if err != nil {
+		t.Fatalf("Error cloning certificate: %s", err.Error())
+	}
+	if len(chain) != 1 {
+		t.Fatalf("Expected a fake issuer chain of 1 certificate, got %d", len(chain))
+	}
+	assertFaithfulClone(t, leaf.X509(), clone.X509())
+	if clone.Serial != leaf.Serial || clone.KeyData == "" || clone.KeyData == leaf.KeyData {
+		t.Errorf("Unexpected clone certificate %+v", clone)
+	}
+
+	fake := chain[0].X509()
+	if !bytes.Equal(fake.RawSubject, root.X509().RawSubject) {
+		t.Errorf("Fake issuer subject does not match original issuer")
+	}
+	if !bytes.Equal(fake.SubjectKeyId, leaf.X509().AuthorityKeyId) {
+		t.Errorf("Fake issuer SKID does not match original AKID")
+	}
+
+	roots := x509.NewCertPool()
+	roots.AddCert(fake)
+	if _, err := clone.X509().Verify(x509.VerifyOptions{Roots: roots, CurrentTime: clone.X509().NotBefore}); err != nil {
+		t.Errorf("Error verifying clone with fake issuer: %s", err.Error())
+	}
+	if err := leaf.X509().CheckSignatureFrom(fake); err == nil {
+		t.Errorf("Original certificate should not be signed by the fake issuer")
+	}
+}
+
+func TestFaithfulCloneSelfSigned(t *testing.T) {
+	t.Parallel()
+
+	original, err := tls.ImportPEMCertificate([]byte(rsa2048cert))
+	if err != nil {
+		t.Fatalf("Error importing certificate: %s", err.Error())
+	}
+
+	clone, chain, err := tls.FaithfulClone(*original, nil, tls.FaithfulCloneOptions{KeyType: tls.KeyTypeRSA_3072})
+	if err != nil {
+		t.Fatalf("Error cloning certificate: %s", err.Error())
+	}
+	if size := clone.X509().PublicKey.(*rsa.PublicKey).N.BitLen(); size != 3072 {
+		t.Errorf("Unexpected clone key size %d", size)
+	}
+	if len(chain) != 0 {
+		t.Errorf("Unexpected fake issuer chain for self-signed certificate")
+	}
+	assertFaithfulClone(t, original.X509(), clone.X509())
+	if err := clone.X509().CheckSignatureFrom(clone.X509()); err != nil {
+		t.Errorf("Self-signed clone does not verify: %s", err.Error())
+	}
+}
+
+func TestFaithfulCloneWithIssuer(t *testing.T) {
+	t.Parallel()
+
+	root, leaf, err := generateCertificateChain()
+	if err != nil {
+		t.Fatalf("Error generating certificate chain: %s", err.Error())
+	}
+
+	rootClone, _, err := tls.FaithfulClone(*root, nil, tls.FaithfulCloneOptions{})
+	if err != nil {
+		t.Fatalf("Error cloning certificate: %s", err.Error())
+	}
+	leafClone, chain, err := tls.FaithfulClone(*leaf, rootClone, tls.FaithfulCloneOptions{})
+	if err != nil {
+		t.Fatalf("Error cloning certificate: %s", err.Error())
+	}
+	if len(chain) != 0 {
+		t.Errorf("Unexpected fake issuer chain when issuer is given")
+	}
+	assertFaithfulClone(t, leaf.X509(), leafClone.X509())
+	x := leafClone.X509()
+	if err := rootClone.X509().CheckSignature(x.SignatureAlgorithm, x.RawTBSCertificate, x.Signature); err != nil {
+		t.Errorf("Clone is not signed by the given issuer: %s", err.Error())
+	}
+}
+
+func TestFaithfulCloneInvalid(t *testing.T) {
+	t.Parallel()
+
+	_, leaf, err := generateCertificateChain()
+	if err != nil {
+		t.Fatalf("Error generating certificate chain: %s", err.Error())
+	}
+	issuer, err := tls.GenerateCertificate(tls.CertificateRequest{
+		KeyType: tls.KeyTypeEd25519,
+		Subject: tls.Name{CommonName: "Ed25519 Root"},
+		Validity: tls.DateRange{
+			NotBefore: "2001-01-01",
+			NotAfter:  "2002-01-01",
+		},
+		IsCertificateAuthority: true,
+	}, nil)
+	if err != nil {
+		t.Fatalf("Error generating certificate: %s", err.Error())
+	}
+
+	if _, _, err := tls.FaithfulClone(*leaf, issuer, tls.FaithfulCloneOptions{}); err == nil {
+		t.Errorf("No error seen when one expected for issuer key that can't produce the original signature algorithm")
+	}
+	if _, _, err := tls.FaithfulClone(*leaf, nil, tls.FaithfulCloneOptions{KeyType: "foo"}); err == nil {
+		t.Errorf("No error seen when one expected for invalid key type")
+	}
+	issuer.KeyData = ""
+	if _, _, err := tls.FaithfulClone(*leaf, issuer, tls.FaithfulCloneOptions{}); err == nil {
+		t.Errorf("No error seen when one expected for issuer without private key")
+	}
+}
+
+func generateLongCertificateChain(t *testing.T) []*tls.Certificate {
+	request := func(commonName string, isCA bool) tls.CertificateRequest {
+		return tls.CertificateRequest{
+			KeyType:            tls.KeyTypeECDSA_256,
+			SignatureAlgorithm: tls.SignatureAlgorithmSHA256,
+			Subject: tls.Name{
+				Organization: "example.com",
+				CommonName:   commonName,
+			},
+			Validity: tls.DateRange{
+				NotBefore: "2001-01-01",
+				NotAfter:  "2002-01-01",
+			},
+			Usage: tls.KeyUsage{
+				DigitalSignature: true,
+				CertSign:         isCA,
+				ServerAuth:       !isCA,
+			},
+			IsCertificateAuthority: isCA,
+		}
+	}
+
+	root, err := tls.GenerateCertificate(request("example.com Root", true), nil)
+	if err != nil {
+		t.Fatalf("Error generating certificate: %s", err.Error())
+	}
+	intermediate, err := tls.GenerateCertificate(request("example.com Intermediate", true), root)
+	if err != nil {
+		t.Fatalf("Error generating certificate: %s", err.Error())
+	}
+	leafRequest := request("foo.example.com", false)
+	leafRequest.AlternateNames = []tls.AlternateName{{Type: tls.AlternateNameTypeDNS, Value: "foo.example.com"}}
+	leaf, err := tls.GenerateCertificate(leafRequest, intermediate)
+	if err != nil {
+		t.Fatalf("Error generating certificate: %s", err.Error())
+	}
+	return []*tls.Certificate{leaf, intermediate, root}
+}
+
+func TestFaithfulCloneChain(t *testing.T) {
+	t.Parallel()
+
+	generated := generateLongCertificateChain(t)
+	pemData := []byte{}
+	for _, certificate := range generated {
+		certPEM, _, err := tls.ExportPEM(certificate)
+		if err != nil {
+			t.Fatalf("Error exporting certificate: %s", err.Error())
+		}
+		pemData = append(pemData, certPEM...)
+	}
+
+	imported, err := tls.ImportPEMCertificates(pemData)
+	if err != nil {
+		t.Fatalf("Error importing certificates: %s", err.Error())
+	}
+	chain := []tls.Certificate{}
+	for _, certificate := range imported {
+		chain = append(chain, *certificate)
+	}
+
+	clones, err := tls.FaithfulCloneChain(chain, tls.FaithfulCloneOptions{})
+	if err != nil {
+		t.Fatalf("Error cloning chain: %s", err.Error())
+	}
+	if len(clones) != 3 {
+		t.Fatalf("Expected 3 cloned certificates, got %d", len(clones))
+	}
+	for i, clone := range clones {
+		assertFaithfulClone(t, chain[i].X509(), clone.X509())
+		if i > 0 && !bytes.Equal(clone.X509().SubjectKeyId, clones[i-1].X509().AuthorityKeyId) {
+			t.Errorf("AKID of '%s' does not match SKID of its issuer", clones[i-1].Subject.CommonName)
+		}
+	}
+
+	roots := x509.NewCertPool()
+	roots.AddCert(clones[2].X509())
+	intermediates := x509.NewCertPool()
+	intermediates.AddCert(clones[1].X509())
+	leaf := clones[0].X509()
+	if _, err := leaf.Verify(x509.VerifyOptions{Roots: roots, Intermediates: intermediates, CurrentTime: leaf.NotBefore, DNSName: "foo.example.com"}); err != nil {
+		t.Errorf("Error verifying cloned chain: %s", err.Error())
+	}
+
+	// Without the root the top of the chain is signed by a fake issuer
+	clones, err = tls.FaithfulCloneChain(chain[:2], tls.FaithfulCloneOptions{})
+	if err != nil {
+		t.Fatalf("Error cloning chain: %s", err.Error())
+	}
+	if len(clones) != 3 || !bytes.Equal(clones[2].X509().RawSubject, chain[2].X509().RawSubject) {
+		t.Errorf("Expected cloned chain to end with a fake issuer")
+	}
+
+	if _, err := tls.FaithfulCloneChain([]tls.Certificate{chain[2], chain[1], chain[0]}, tls.FaithfulCloneOptions{}); err == nil {
+		t.Errorf("No error seen when one expected for chain in the wrong order")
+	}
+	if _, err := tls.FaithfulCloneChain([]tls.Certificate{chain[0], chain[2]}, tls.FaithfulCloneOptions{}); err == nil {
+		t.Errorf("No error seen when one expected for chain with a missing certificate")
+	}
+}
@@ -24,9 +24,9 @@ func ImportPEM(certData []byte, keyData []byte, password string) (*Certificate,
 		CertificateData: hex.EncodeToString(certPEM.Bytes),
 	}
 
-	certificate.Serial = certificate.X509().Subject.SerialNumber
+	certificate.setSerial(certificate.X509().SerialNumber)
 	certificate.CertificateAuthority = certificate.X509().IsCA
-	certificate.Subject = nameFromPkix(certificate.X509().Subject)
+	certificate.Subject = nameFromRaw(certificate.X509().RawSubject)
 
 	if password != "" {
 		//lint:ignore SA1019 Responsability lies with user
@@ -49,13 +49,49 @@ func ImportPEMCertificate(certData []byte) (*Certificate, error) {
 		return nil, fmt.Errorf("cert is not valid PEM")
 	}
 
+	return ImportDERCertificate(certPEM.Bytes)
+}
+
+// ImportPEMCertificates try to import every PEM certificate in the given data, in the order they appear
+func ImportPEMCertificates(certData []byte) ([]*Certificate, error) {
+	certificates := []*Certificate{}
+	for {
+		var certPEM *pem.Block
+		certPEM, certData = pem.Decode(certData)
+		if certPEM == nil {
+			break
+		}
+		if certPEM.Type != "CERTIFICATE" {
+			continue
+		}
+
+		certificate, err := ImportDERCertificate(certPEM.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		certificates = append(certificates, certificate)
+	}
+	if len(certificates) == 0 {
+		return nil, fmt.Errorf("no PEM certificates found")
+	}
+
+	return certificates, nil
+}
+
+// ImportDERCertificate try to import the given DER certificate only
+func ImportDERCertificate(certData []byte) (*Certificate, error) {
+	x, err := x509.ParseCertificate(certData)
+	if err != nil {
+		return nil, fmt.Errorf("invalid certificate: %s", err.Error())
+	}
+
 	certificate := Certificate{
-		CertificateData: hex.EncodeToString(certPEM.Bytes),
+		CertificateData: hex.EncodeToString(certData),
 	}
 
-	certificate.Serial = certificate.X509().Subject.SerialNumber
-	certificate.CertificateAuthority = certificate.X509().IsCA
-	certificate.Subject = nameFromPkix(certificate.X509().Subject)
+	certificate.setSerial(x.SerialNumber)
+	certificate.CertificateAuthority = x.IsCA
+	certificate.Subject = nameFromRaw(x.RawSubject)
 
 	return &certificate, nil
 }
@@ -76,9 +112,9 @@ func ImportP12(p12Data []byte, password string) (*Certificate, error) {
 		CertificateData: hex.EncodeToString(xCert.Raw),
 		KeyData:         hex.EncodeToString(pkeyBytes),
 	}
-	certificate.Serial = certificate.X509().Subject.SerialNumber
+	certificate.setSerial(certificate.X509().SerialNumber)
 	certificate.CertificateAuthority = certificate.X509().IsCA
-	certificate.Subject = nameFromPkix(certificate.X509().Subject)
+	certificate.Subject = nameFromRaw(certificate.X509().RawSubject)
 
 	return &certificate, nil
 }
@@ -92,6 +92,18 @@ func TestImportPEM(t *testing.T) {
 	if certificate.Description() == "" {
 		t.Fatal("Empty description")
 	}
+	assertSuperfishSerial(t, certificate)
+}
+
+// assertSuperfishSerial checks that the serial number was taken from the certificate, which has no serial number in
+// its subject
+func assertSuperfishSerial(t *testing.T, certificate *tls.Certificate) {
+	if certificate.Serial != "15203047915477327079" {
+		t.Errorf("Unexpected decimal serial number '%s'", certificate.Serial)
+	}
+	if certificate.SerialHex != "d2fc1387a944dce7" {
+		t.Errorf("Unexpected hexadecimal serial number '%s'", certificate.SerialHex)
+	}
 }
 
 func TestImportPEMInvalidPassword(t *testing.T) {
@@ -178,6 +190,7 @@ func TestImportP12(t *testing.T) {
 	if certificate.Description() == "" {
 		t.Fatal("Empty description")
 	}
+	assertSuperfishSerial(t, certificate)
 }
 
 func TestImportInvalidPEM(t *testing.T) {
@@ -268,7 +281,10 @@ func TestImportClone(t *testing.T) {
 		panic(err)
 	}
 
-	clone := reimport.Clone()
+	clone, err := reimport.Clone()
+	if err != nil {
+		t.Fatalf("Error cloning certificate: %s", err.Error())
+	}
 
 	if request.KeyType != clone.KeyType {
 		t.Errorf("Incorrect KeyType")
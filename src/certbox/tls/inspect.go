@@ -0,0 +1,579 @@
+package tls
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"net"
+	"time"
+)
+
+// General name types that are not also alternate name types
+const (
+	GeneralNameTypeDirectory    = "directory"
+	GeneralNameTypeOther        = "other"
+	GeneralNameTypeRegisteredID = "registeredid"
+	// GeneralNameTypeRaw is any other general name, described by its hexadecimal DER encoding
+	GeneralNameTypeRaw = "raw"
+)
+
+// CertificateDetails describes every detail of a certificate
+type CertificateDetails struct {
+	Version   int
+	Serial    string
+	SerialHex string
+	// SignatureAlgorithm is the name of the signature algorithm, such as SHA256-RSA
+	SignatureAlgorithm    string
+	SignatureAlgorithmOID string
+	// Signature is the hexadecimal signature value
+	Signature string
+	Issuer    NameDetails
+	Subject   NameDetails
+	// NotBefore and NotAfter are RFC 3339 timestamps in UTC
+	NotBefore    string
+	NotAfter     string
+	PublicKey    PublicKeyDetails
+	Fingerprints Fingerprints
+	Pins         PublicKeyPins
+	Extensions   []ExtensionDetails
+}
+
+// NameDetails describes a X.509 name
+type NameDetails struct {
+	// DN is the RFC 4514 string representation of the name, such as CN=example.com,O=Example
+	DN string
+	// RDNs is the ordered sequence of relative distinguished names as they are encoded
+	RDNs [][]NameAttributeDetails
+}
+
+// NameAttributeDetails describes a single attribute of a X.509 name
+type NameAttributeDetails struct {
+	OID string
+	// ShortName is the common abbreviation of the attribute type, such as CN, or empty if there isn't one
+	ShortName string
+	Value     string
+	// Type is the ASN.1 string type of Value, such as ASN1TypePrintableString, or ASN1TypeRaw if Value is a
+	// hexadecimal DER encoded value of any other type
+	Type string
+}
+
+// PublicKeyDetails describes the subject public key of a certificate
+type PublicKeyDetails struct {
+	// Algorithm is the name of the public key algorithm, such as RSA
+	Algorithm    string
+	AlgorithmOID string
+	Bits         int
+	// Curve is the name of the ECDSA curve, such as P-256
+	Curve string
+	// Modulus is the hexadecimal RSA modulus and Exponent the RSA public exponent
+	Modulus  string
+	Exponent int
+	// Value is the hexadecimal encoded subject public key
+	Value string
+}
+
+// Fingerprints are the hexadecimal digests of the DER encoded certificate
+type Fingerprints struct {
+	SHA1   string
+	SHA256 string
+}
+
+// PublicKeyPins are digests of the DER encoded subject public key info
+type PublicKeyPins struct {
+	// SHA256 is base64 encoded, as used by pin-sha256
+	SHA256    string
+	SHA256Hex string
+}
+
+// ExtensionDetails describes a single certificate extension. Properties matching the type of a known extension are
+// set once it is decoded, otherwise its value is described by ASN1 if it is valid DER.
+type ExtensionDetails struct {
+	OID string
+	// Name is the name of a known extension, such as Key Usage
+	Name     string
+	Critical bool
+	// Raw is the hexadecimal DER encoded value of the extension
+	Raw string
+	// Error describes why a known extension could not be decoded
+	Error string
+
+	SubjectKeyID          string
+	AuthorityKeyID        *AuthorityKeyID
+	KeyUsage              []string
+	ExtendedKeyUsage      []string
+	BasicConstraints      *BasicConstraints
+	AlternateNames        []GeneralName
+	NameConstraints       *NameConstraints
+	Policies              []CertificatePolicy
+	PolicyConstraints     *PolicyConstraints
+	CRLDistributionPoints []GeneralName
+	AuthorityInfoAccess   []AccessDescription
+	ASN1                  *ASN1Value
+}
+
+// GeneralName describes a X.509 general name. Type is one of the alternate name types or general name types.
+type GeneralName struct {
+	Type  string
+	Value string
+	// OID is the type of other names
+	OID string
+}
+
+// AuthorityKeyID describes the authority key identifier extension
+type AuthorityKeyID struct {
+	KeyID     string
+	Issuer    []GeneralName
+	SerialHex string
+}
+
+// BasicConstraints describes the basic constraints extension. MaxPathLen is nil when there is no path length.
+type BasicConstraints struct {
+	CertificateAuthority bool
+	MaxPathLen           *int
+}
+
+// AccessDescription describes a single entry of an information access extension
+type AccessDescription struct {
+	// Method is the name of the access method, such as OCSP, or its OID if it isn't known
+	Method   string
+	Location GeneralName
+}
+
+var (
+	oidExtensionIssuerAltName = asn1.ObjectIdentifier([]int{2, 5, 29, 18})
+	oidExtensionSCTList       = asn1.ObjectIdentifier([]int{1, 3, 6, 1, 4, 1, 11129, 2, 4, 2})
+	oidExtensionCTPoison      = asn1.ObjectIdentifier([]int{1, 3, 6, 1, 4, 1, 11129, 2, 4, 3})
+	oidExtensionOCSPNoCheck   = asn1.ObjectIdentifier([]int{1, 3, 6, 1, 5, 5, 7, 48, 1, 5})
+	oidExtensionTLSFeature    = asn1.ObjectIdentifier([]int{1, 3, 6, 1, 5, 5, 7, 1, 24})
+	oidExtensionCRLNumber     = asn1.ObjectIdentifier([]int{2, 5, 29, 20})
+	oidExtensionCRLReason     = asn1.ObjectIdentifier([]int{2, 5, 29, 21})
+	oidAccessMethodOCSP       = asn1.ObjectIdentifier([]int{1, 3, 6, 1, 5, 5, 7, 48, 1})
+	oidAccessMethodCAIssuers  = asn1.ObjectIdentifier([]int{1, 3, 6, 1, 5, 5, 7, 48, 2})
+)
+
+var extensionNames = map[string]string{
+	oidExtensionSubjectKeyId.String():        "Subject Key Identifier",
+	oidExtensionKeyUsage.String():            "Key Usage",
+	oidExtensionExtendedKeyUsage.String():    "Extended Key Usage",
+	oidExtensionAuthorityKeyId.String():      "Authority Key Identifier",
+	oidExtensionBasicConstraints.String():    "Basic Constraints",
+	oidExtensionSubjectAltName.String():      "Subject Alternative Name",
+	oidExtensionIssuerAltName.String():       "Issuer Alternative Name",
+	oidExtensionCRLDistPoints.String():       "CRL Distribution Points",
+	oidExtensionAuthorityInfo.String():       "Authority Information Access",
+	oidExtensionNameConstraints.String():     "Name Constraints",
+	oidExtensionCertificatePolicies.String(): "Certificate Policies",
+	oidExtensionPolicyMappings.String():      "Policy Mappings",
+	oidExtensionPolicyConstraints.String():   "Policy Constraints",
+	oidExtensionInhibitAnyPolicy.String():    "Inhibit Any Policy",
+	oidExtensionSCTList.String():             "CT Precertificate SCTs",
+	oidExtensionCTPoison.String():            "CT Precertificate Poison",
+	oidExtensionOCSPNoCheck.String():         "OCSP No Check",
+	oidExtensionTLSFeature.String():          "TLS Feature",
+	oidExtensionCRLNumber.String():           "CRL Number",
+	oidExtensionDeltaCRLIndicator.String():   "Delta CRL Indicator",
+	oidExtensionCRLReason.String():           "CRL Reason Code",
+}
+
+var nameAttributeShortNames = map[string]string{
+	oidNameCommonName.String():         "CN",
+	oidNameSerialNumber.String():       "SERIALNUMBER",
+	oidNameCountry.String():            "C",
+	oidNameLocality.String():           "L",
+	oidNameProvince.String():           "ST",
+	oidNameStreetAddress.String():      "STREET",
+	oidNameOrganization.String():       "O",
+	oidNameOrganizationalUnit.String(): "OU",
+	oidNamePostalCode.String():         "POSTALCODE",
+	oidNameDomainComponent.String():    "DC",
+	oidNameEmailAddress.String():       "emailAddress",
+}
+
+// keyUsageNames are the names of each key usage bit, in order
+var keyUsageNames = []string{
+	"Digital Signature",
+	"Content Commitment",
+	"Key Encipherment",
+	"Data Encipherment",
+	"Key Agreement",
+	"Certificate Sign",
+	"CRL Sign",
+	"Encipher Only",
+	"Decipher Only",
+}
+
+var extendedKeyUsageNames = map[string]string{
+	"2.5.29.37.0":       "Any Extended Key Usage",
+	"1.3.6.1.5.5.7.3.1": "TLS Web Server Authentication",
+	"1.3.6.1.5.5.7.3.2": "TLS Web Client Authentication",
+	"1.3.6.1.5.5.7.3.3": "Code Signing",
+	"1.3.6.1.5.5.7.3.4": "E-mail Protection",
+	"1.3.6.1.5.5.7.3.8": "Time Stamping",
+	"1.3.6.1.5.5.7.3.9": "OCSP Signing",
+}
+
+// InspectCertificate returns a detailed breakdown of the given certificate, including every extension
+func InspectCertificate(certificate Certificate) (*CertificateDetails, error) {
+	data, err := hex.DecodeString(certificate.CertificateData)
+	if err != nil {
+		return nil, fmt.Errorf("invalid certificate data: %s", err.Error())
+	}
+	x, err := x509.ParseCertificate(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid certificate: %s", err.Error())
+	}
+
+	var outer struct {
+		TBSCertificate     asn1.RawValue
+		SignatureAlgorithm pkix.AlgorithmIdentifier
+		Signature          asn1.BitString
+	}
+	if _, err := asn1.Unmarshal(x.Raw, &outer); err != nil {
+		return nil, fmt.Errorf("invalid certificate: %s", err.Error())
+	}
+
+	sha1Sum := sha1.Sum(x.Raw)
+	sha256Sum := sha256.Sum256(x.Raw)
+	pinSum := sha256.Sum256(x.RawSubjectPublicKeyInfo)
+
+	details := &CertificateDetails{
+		Version:               x.Version,
+		Serial:                x.SerialNumber.String(),
+		SerialHex:             FormatSerialHex(x.SerialNumber),
+		SignatureAlgorithm:    x.SignatureAlgorithm.String(),
+		SignatureAlgorithmOID: outer.SignatureAlgorithm.Algorithm.String(),
+		Signature:             hex.EncodeToString(x.Signature),
+		Issuer:                nameDetailsFromRaw(x.RawIssuer),
+		Subject:               nameDetailsFromRaw(x.RawSubject),
+		NotBefore:             x.NotBefore.UTC().Format(time.RFC3339),
+		NotAfter:              x.NotAfter.UTC().Format(time.RFC3339),
+		Fingerprints: Fingerprints{
+			SHA1:   hex.EncodeToString(sha1Sum[:]),
+			SHA256: hex.EncodeToString(sha256Sum[:]),
+		},
+		Pins: PublicKeyPins{
+			SHA256:    base64.StdEncoding.EncodeToString(pinSum[:]),
+			SHA256Hex: hex.EncodeToString(pinSum[:]),
+		},
+		Extensions: []ExtensionDetails{},
+	}
+
+	details.PublicKey, err = publicKeyDetails(x.RawSubjectPublicKeyInfo, x.PublicKeyAlgorithm, x.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, ext := range x.Extensions {
+		details.Extensions = append(details.Extensions, extensionDetails(x, ext))
+	}
+
+	return details, nil
+}
+
+func nameDetailsFromRaw(raw []byte) NameDetails {
+	details := NameDetails{RDNs: [][]NameAttributeDetails{}}
+
+	var sequence pkix.RDNSequence
+	if _, err := asn1.Unmarshal(raw, &sequence); err != nil {
+		return details
+	}
+	details.DN = sequence.String()
+
+	var rawSequence rawRDNSequence
+	if _, err := asn1.Unmarshal(raw, &rawSequence); err != nil {
+		return details
+	}
+	for _, set := range rawSequence {
+		rdn := []NameAttributeDetails{}
+		for _, atv := range set {
+			attribute := nameAttributeFromRaw(atv)
+			if attribute.Type == "" {
+				attribute.Type = defaultNameAttributeType(atv.Type, attribute.Value)
+			}
+			rdn = append(rdn, NameAttributeDetails{
+				OID:       attribute.OID,
+				ShortName: nameAttributeShortNames[attribute.OID],
+				Value:     attribute.Value,
+				Type:      attribute.Type,
+			})
+		}
+		details.RDNs = append(details.RDNs, rdn)
+	}
+	return details
+}
+
+// publicKeyDetails returns the details of the given DER encoded subject public key info, which was parsed as pub
+func publicKeyDetails(rawSPKI []byte, algorithm x509.PublicKeyAlgorithm, pub crypto.PublicKey) (PublicKeyDetails, error) {
+	var spki struct {
+		Algorithm pkix.AlgorithmIdentifier
+		PublicKey asn1.BitString
+	}
+	if _, err := asn1.Unmarshal(rawSPKI, &spki); err != nil {
+		return PublicKeyDetails{}, fmt.Errorf("invalid subject public key info: %s", err.Error())
+	}
+
+	details := PublicKeyDetails{
+		Algorithm:    algorithm.String(),
+		AlgorithmOID: spki.Algorithm.Algorithm.String(),
+		Value:        hex.EncodeToString(spki.PublicKey.Bytes),
+	}
+
+	switch pub := pub.(type) {
+	case *rsa.PublicKey:
+		details.Bits = pub.N.BitLen()
+		details.Modulus = hex.EncodeToString(pub.N.Bytes())
+		details.Exponent = pub.E
+	case *ecdsa.PublicKey:
+		details.Bits = pub.Curve.Params().BitSize
+		details.Curve = pub.Curve.Params().Name
+	case ed25519.PublicKey:
+		details.Bits = 256
+	}
+	return details, nil
+}
+
+// extensionDetails returns the details of ext, an extension of the certificate x. The name constraints extension is
+// only decoded for certificates.
+func extensionDetails(x *x509.Certificate, ext pkix.Extension) ExtensionDetails {
+	details := ExtensionDetails{
+		OID:      ext.Id.String(),
+		Name:     extensionNames[ext.Id.String()],
+		Critical: ext.Critical,
+		Raw:      hex.EncodeToString(ext.Value),
+	}
+
+	var err error
+	switch {
+	case ext.Id.Equal(oidExtensionSubjectKeyId):
+		var keyID []byte
+		err = unmarshalExtension(ext.Value, &keyID)
+		details.SubjectKeyID = hex.EncodeToString(keyID)
+	case ext.Id.Equal(oidExtensionAuthorityKeyId):
+		details.AuthorityKeyID, err = authorityKeyIDFromExtension(ext.Value)
+	case ext.Id.Equal(oidExtensionKeyUsage):
+		var bits asn1.BitString
+		err = unmarshalExtension(ext.Value, &bits)
+		details.KeyUsage = []string{}
+		for i, name := range keyUsageNames {
+			if bits.At(i) == 1 {
+				details.KeyUsage = append(details.KeyUsage, name)
+			}
+		}
+	case ext.Id.Equal(oidExtensionExtendedKeyUsage):
+		oids := []asn1.ObjectIdentifier{}
+		err = unmarshalExtension(ext.Value, &oids)
+		details.ExtendedKeyUsage = []string{}
+		for _, oid := range oids {
+			name, known := extendedKeyUsageNames[oid.String()]
+			if !known {
+				name = oid.String()
+			}
+			details.ExtendedKeyUsage = append(details.ExtendedKeyUsage, name)
+		}
+	case ext.Id.Equal(oidExtensionBasicConstraints):
+		var constraints struct {
+			IsCA       bool `asn1:"optional"`
+			MaxPathLen int  `asn1:"optional,default:-1"`
+		}
+		err = unmarshalExtension(ext.Value, &constraints)
+		details.BasicConstraints = &BasicConstraints{CertificateAuthority: constraints.IsCA}
+		if constraints.MaxPathLen >= 0 {
+			details.BasicConstraints.MaxPathLen = &constraints.MaxPathLen
+		}
+	case ext.Id.Equal(oidExtensionSubjectAltName), ext.Id.Equal(oidExtensionIssuerAltName):
+		var names asn1.RawValue
+		err = unmarshalExtension(ext.Value, &names)
+		if err == nil {
+			details.AlternateNames, err = generalNamesFromDER(names.Bytes)
+		}
+	case ext.Id.Equal(oidExtensionNameConstraints) && x != nil:
+		constraints := nameConstraintsFromX509(x)
+		constraints.Critical = ext.Critical
+		details.NameConstraints = &constraints
+	case ext.Id.Equal(oidExtensionCertificatePolicies):
+		details.Policies, err = certificatePoliciesFromExtension(ext.Value)
+	case ext.Id.Equal(oidExtensionPolicyMappings), ext.Id.Equal(oidExtensionPolicyConstraints), ext.Id.Equal(oidExtensionInhibitAnyPolicy):
+		var constraints PolicyConstraints
+		constraints, err = policyConstraintsFromExtensions([]pkix.Extension{ext})
+		details.PolicyConstraints = &constraints
+	case ext.Id.Equal(oidExtensionCRLDistPoints):
+		details.CRLDistributionPoints, err = distributionPointsFromExtension(ext.Value)
+	case ext.Id.Equal(oidExtensionAuthorityInfo):
+		details.AuthorityInfoAccess, err = accessDescriptionsFromExtension(ext.Value)
+	default:
+		if value, err := asn1ValueFromDER(ext.Value); err == nil {
+			details.ASN1 = &value
+		}
+	}
+
+	if err != nil {
+		details = ExtensionDetails{
+			OID:      details.OID,
+			Name:     details.Name,
+			Critical: details.Critical,
+			Raw:      details.Raw,
+			Error:    err.Error(),
+		}
+	}
+	return details
+}
+
+// unmarshalExtension parses the DER encoded extension value into out, which must consume the whole value
+func unmarshalExtension(value []byte, out any) error {
+	rest, err := asn1.Unmarshal(value, out)
+	if err != nil {
+		return err
+	}
+	if len(rest) > 0 {
+		return fmt.Errorf("trailing data after extension value")
+	}
+	return nil
+}
+
+func authorityKeyIDFromExtension(value []byte) (*AuthorityKeyID, error) {
+	var aki struct {
+		KeyID  []byte        `asn1:"optional,tag:0"`
+		Issuer asn1.RawValue `asn1:"optional,tag:1"`
+		Serial *big.Int      `asn1:"optional,tag:2"`
+	}
+	if err := unmarshalExtension(value, &aki); err != nil {
+		return nil, err
+	}
+
+	details := &AuthorityKeyID{KeyID: hex.EncodeToString(aki.KeyID)}
+	if len(aki.Issuer.FullBytes) > 0 {
+		issuer, err := generalNamesFromDER(aki.Issuer.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		details.Issuer = issuer
+	}
+	if aki.Serial != nil {
+		details.SerialHex = FormatSerialHex(aki.Serial)
+	}
+	return details, nil
+}
+
+func distributionPointsFromExtension(value []byte) ([]GeneralName, error) {
+	var points []struct {
+		DistributionPoint asn1.RawValue  `asn1:"optional,tag:0"`
+		Reasons           asn1.BitString `asn1:"optional,tag:1"`
+		CRLIssuer         asn1.RawValue  `asn1:"optional,tag:2"`
+	}
+	if err := unmarshalExtension(value, &points); err != nil {
+		return nil, err
+	}
+
+	names := []GeneralName{}
+	for _, point := range points {
+		var name asn1.RawValue
+		if _, err := asn1.Unmarshal(point.DistributionPoint.Bytes, &name); err != nil {
+			continue
+		}
+		// Only a full name is described, as a name relative to the CRL issuer is uncommon
+		if name.Class != asn1.ClassContextSpecific || name.Tag != 0 {
+			continue
+		}
+		fullName, err := generalNamesFromDER(name.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, fullName...)
+	}
+	return names, nil
+}
+
+func accessDescriptionsFromExtension(value []byte) ([]AccessDescription, error) {
+	var descriptions []struct {
+		Method   asn1.ObjectIdentifier
+		Location asn1.RawValue
+	}
+	if err := unmarshalExtension(value, &descriptions); err != nil {
+		return nil, err
+	}
+
+	access := []AccessDescription{}
+	for _, description := range descriptions {
+		method := description.Method.String()
+		switch {
+		case description.Method.Equal(oidAccessMethodOCSP):
+			method = "OCSP"
+		case description.Method.Equal(oidAccessMethodCAIssuers):
+			method = "CA Issuers"
+		}
+		access = append(access, AccessDescription{
+			Method:   method,
+			Location: generalNameFromRaw(description.Location),
+		})
+	}
+	return access, nil
+}
+
+// generalNamesFromDER returns the general names in the given DER encoded sequence contents
+func generalNamesFromDER(data []byte) ([]GeneralName, error) {
+	names := []GeneralName{}
+	for len(data) > 0 {
+		var raw asn1.RawValue
+		var err error
+		data, err = asn1.Unmarshal(data, &raw)
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, generalNameFromRaw(raw))
+	}
+	return names, nil
+}
+
+func generalNameFromRaw(raw asn1.RawValue) GeneralName {
+	rawName := GeneralName{Type: GeneralNameTypeRaw, Value: hex.EncodeToString(raw.FullBytes)}
+	if raw.Class != asn1.ClassContextSpecific {
+		return rawName
+	}
+
+	switch raw.Tag {
+	case 0:
+		var otherName struct {
+			TypeID asn1.ObjectIdentifier
+			Value  asn1.RawValue `asn1:"explicit,tag:0"`
+		}
+		if _, err := asn1.UnmarshalWithParams(raw.FullBytes, &otherName, "tag:0"); err != nil {
+			return rawName
+		}
+		value := asn1ValueFromRaw(otherName.Value)
+		return GeneralName{Type: GeneralNameTypeOther, Value: value.Value, OID: otherName.TypeID.String()}
+	case 1:
+		return GeneralName{Type: AlternateNameTypeEmail, Value: string(raw.Bytes)}
+	case 2:
+		return GeneralName{Type: AlternateNameTypeDNS, Value: string(raw.Bytes)}
+	case 4:
+		var sequence pkix.RDNSequence
+		if _, err := asn1.Unmarshal(raw.Bytes, &sequence); err != nil {
+			return rawName
+		}
+		return GeneralName{Type: GeneralNameTypeDirectory, Value: sequence.String()}
+	case 6:
+		return GeneralName{Type: AlternateNameTypeURI, Value: string(raw.Bytes)}
+	case 7:
+		switch len(raw.Bytes) {
+		case net.IPv4len, net.IPv6len:
+			return GeneralName{Type: AlternateNameTypeIP, Value: net.IP(raw.Bytes).String()}
+		}
+	case 8:
+		var oid asn1.ObjectIdentifier
+		if _, err := asn1.UnmarshalWithParams(raw.FullBytes, &oid, "tag:8"); err != nil {
+			return rawName
+		}
+		return GeneralName{Type: GeneralNameTypeRegisteredID, Value: oid.String()}
+	}
+	return rawName
+}
@@ -0,0 +1,173 @@
+package tls_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/tls-inspector/certbox/tls"
+)
+
+func TestInspectCertificate(t *testing.T) {
+	t.Parallel()
+
+	certificate, err := tls.ImportPEMCertificate([]byte(pemCert))
+	if err != nil {
+		t.Fatalf("Error importing certificate: %s", err.Error())
+	}
+	details, err := tls.InspectCertificate(*certificate)
+	if err != nil {
+		t.Fatalf("Error inspecting certificate: %s", err.Error())
+	}
+
+	if details.Version != 3 {
+		t.Errorf("Unexpected version %d", details.Version)
+	}
+	if details.SerialHex != "d2fc1387a944dce7" || details.Serial != "15203047915477327079" {
+		t.Errorf("Unexpected serial number '%s' (%s)", details.SerialHex, details.Serial)
+	}
+	if details.SignatureAlgorithm != "SHA1-RSA" || details.SignatureAlgorithmOID != "1.2.840.113549.1.1.5" {
+		t.Errorf("Unexpected signature algorithm '%s' (%s)", details.SignatureAlgorithm, details.SignatureAlgorithmOID)
+	}
+	if details.Subject.DN != "CN=Superfish\\, Inc.,C=US,ST=CA,L=SF,O=Superfish\\, Inc." {
+		t.Errorf("Unexpected subject '%s'", details.Subject.DN)
+	}
+	if len(details.Issuer.RDNs) != 5 || details.Issuer.RDNs[0][0].ShortName != "O" || details.Issuer.RDNs[0][0].Value != "Superfish, Inc." {
+		t.Errorf("Unexpected issuer RDNs %+v", details.Issuer.RDNs)
+	}
+	if details.NotBefore != "2014-05-12T16:25:26Z" || details.NotAfter != "2034-05-07T16:25:26Z" {
+		t.Errorf("Unexpected validity %s - %s", details.NotBefore, details.NotAfter)
+	}
+	if details.PublicKey.Algorithm != "RSA" || details.PublicKey.Bits != 1024 || details.PublicKey.Exponent != 65537 {
+		t.Errorf("Unexpected public key %+v", details.PublicKey)
+	}
+	if details.Fingerprints.SHA1 != "c864484869d41d2b0d32319c5a62f9315aaf2cbd" {
+		t.Errorf("Unexpected SHA-1 fingerprint '%s'", details.Fingerprints.SHA1)
+	}
+	if details.Fingerprints.SHA256 != "b6fe9151402bad1c06d7e66db67a26aa7356f2e6c644dbcf9f98968ff632e1b7" {
+		t.Errorf("Unexpected SHA-256 fingerprint '%s'", details.Fingerprints.SHA256)
+	}
+	if details.Pins.SHA256 != "S7jzW6HhJvjd4bDEIGJe2G3OYae92tveqauleP8TFF4=" {
+		t.Errorf("Unexpected SPKI pin '%s'", details.Pins.SHA256)
+	}
+
+	if len(details.Extensions) != 3 {
+		t.Fatalf("Unexpected number of extensions. Expected 3 got %d", len(details.Extensions))
+	}
+	basicConstraints := details.Extensions[0].BasicConstraints
+	if basicConstraints == nil || !basicConstraints.CertificateAuthority || basicConstraints.MaxPathLen != nil {
+		t.Errorf("Unexpected basic constraints %+v", details.Extensions[0])
+	}
+	if details.Extensions[1].SubjectKeyID != "fb98b3537f14442ee8eed5099a5e0e5686a83588" {
+		t.Errorf("Unexpected subject key identifier '%s'", details.Extensions[1].SubjectKeyID)
+	}
+	aki := details.Extensions[2].AuthorityKeyID
+	if aki == nil || aki.KeyID != details.Extensions[1].SubjectKeyID || aki.SerialHex != details.SerialHex {
+		t.Fatalf("Unexpected authority key identifier %+v", details.Extensions[2])
+	}
+	if len(aki.Issuer) != 1 || aki.Issuer[0].Type != tls.GeneralNameTypeDirectory || aki.Issuer[0].Value != details.Issuer.DN {
+		t.Errorf("Unexpected authority key identifier issuer %+v", aki.Issuer)
+	}
+}
+
+func TestInspectCertificateExtensions(t *testing.T) {
+	t.Parallel()
+
+	maxPathLen := 2
+	certificate, err := tls.GenerateCertificate(tls.CertificateRequest{
+		KeyType:            tls.KeyTypeECDSA_256,
+		SignatureAlgorithm: tls.SignatureAlgorithmSHA256,
+		Subject:            tls.Name{Organization: "example.com", CommonName: "example.com Root"},
+		Validity:           tls.DateRange{NotBefore: "2001-01-01", NotAfter: "2002-01-01"},
+		AlternateNames: []tls.AlternateName{
+			{Type: tls.AlternateNameTypeDNS, Value: "example.com"},
+			{Type: tls.AlternateNameTypeEmail, Value: "ca@example.com"},
+			{Type: tls.AlternateNameTypeIP, Value: "192.0.2.1"},
+			{Type: tls.AlternateNameTypeURI, Value: "https://example.com/"},
+		},
+		Usage:                  tls.KeyUsage{DigitalSignature: true, CertSign: true, CRLSign: true, ServerAuth: true, CustomEKUs: []string{"1.2.3.4"}},
+		IsCertificateAuthority: true,
+		MaxPathLen:             maxPathLen,
+		NameConstraints:        tls.NameConstraints{Critical: true, PermittedDNSDomains: []string{"example.com"}},
+		Policies:               []tls.CertificatePolicy{{OID: tls.PolicyDomainValidated}},
+		StatusProviders: tls.StatusProviders{
+			CRL:       []string{"http://crl.example.com/root.crl"},
+			OCSP:      []string{"http://ocsp.example.com"},
+			CAIssuers: []string{"http://example.com/root.crt"},
+		},
+		Extensions: []tls.Extension{{OID: "1.2.3.4.5", Value: "custom"}},
+	}, nil)
+	if err != nil {
+		t.Fatalf("Error generating certificate: %s", err.Error())
+	}
+
+	details, err := tls.InspectCertificate(*certificate)
+	if err != nil {
+		t.Fatalf("Error inspecting certificate: %s", err.Error())
+	}
+	if details.PublicKey.Algorithm != "ECDSA" || details.PublicKey.Curve != "P-256" || details.PublicKey.Bits != 256 {
+		t.Errorf("Unexpected public key %+v", details.PublicKey)
+	}
+
+	extensions := map[string]tls.ExtensionDetails{}
+	for _, extension := range details.Extensions {
+		if extension.Raw == "" {
+			t.Errorf("Missing raw value of extension %s", extension.OID)
+		}
+		if extension.Error != "" {
+			t.Errorf("Error decoding extension %s: %s", extension.OID, extension.Error)
+		}
+		extensions[extension.OID] = extension
+	}
+
+	if usage := strings.Join(extensions["2.5.29.15"].KeyUsage, ","); usage != "Digital Signature,Certificate Sign,CRL Sign" {
+		t.Errorf("Unexpected key usage '%s'", usage)
+	}
+	if usage := strings.Join(extensions["2.5.29.37"].ExtendedKeyUsage, ","); usage != "TLS Web Server Authentication,1.2.3.4" {
+		t.Errorf("Unexpected extended key usage '%s'", usage)
+	}
+	if bc := extensions["2.5.29.19"].BasicConstraints; bc == nil || !bc.CertificateAuthority || bc.MaxPathLen == nil || *bc.MaxPathLen != maxPathLen {
+		t.Errorf("Unexpected basic constraints %+v", extensions["2.5.29.19"])
+	}
+
+	names := []string{}
+	for _, name := range extensions["2.5.29.17"].AlternateNames {
+		names = append(names, name.Type+":"+name.Value)
+	}
+	if strings.Join(names, ",") != "dns:example.com,email:ca@example.com,ip:192.0.2.1,uri:https://example.com/" {
+		t.Errorf("Unexpected alternate names '%s'", strings.Join(names, ","))
+	}
+
+	nc := extensions["2.5.29.30"]
+	if !nc.Critical || nc.NameConstraints == nil || strings.Join(nc.NameConstraints.PermittedDNSDomains, ",") != "example.com" {
+		t.Errorf("Unexpected name constraints %+v", nc)
+	}
+	if policies := extensions["2.5.29.32"].Policies; len(policies) != 1 || policies[0].OID != tls.PolicyDomainValidated {
+		t.Errorf("Unexpected certificate policies %+v", policies)
+	}
+	if crl := extensions["2.5.29.31"].CRLDistributionPoints; len(crl) != 1 || crl[0].Value != "http://crl.example.com/root.crl" {
+		t.Errorf("Unexpected CRL distribution points %+v", crl)
+	}
+	aia := extensions["1.3.6.1.5.5.7.1.1"].AuthorityInfoAccess
+	if len(aia) != 2 || aia[0].Method != "OCSP" || aia[0].Location.Value != "http://ocsp.example.com" || aia[1].Method != "CA Issuers" {
+		t.Errorf("Unexpected authority information access %+v", aia)
+	}
+
+	custom := extensions["1.2.3.4.5"]
+	if custom.Name != "" || custom.ASN1 == nil || custom.ASN1.Type != tls.ASN1TypePrintableString || custom.ASN1.Value != "custom" {
+		t.Errorf("Unexpected custom extension %+v", custom)
+	}
+}
+
+func TestInspectCertificateInvalid(t *testing.T) {
+	t.Parallel()
+
+	invalid := map[string]tls.Certificate{
+		"invalid hex":         {CertificateData: "not hex"},
+		"invalid certificate": {CertificateData: "3003020101"},
+	}
+	for name, certificate := range invalid {
+		if _, err := tls.InspectCertificate(certificate); err == nil {
+			t.Errorf("No error seen when one expected for %s", name)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package tls
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+)
+
+// parsePrivateKeyPEM will parse the given PEM encoded private key. PKCS#1, PKCS#8 and SEC1 keys are supported, as are
+// encrypted PKCS#8 keys and legacy encrypted PEM blocks when a password is provided.
+func parsePrivateKeyPEM(keyPEM []byte, password string) (crypto.PrivateKey, error) {
+	block, _ := pem.Decode(keyPEM)
+	if block == nil {
+		return nil, fmt.Errorf("key is not valid PEM")
+	}
+
+	data := block.Bytes
+	if block.Type == "ENCRYPTED PRIVATE KEY" {
+		if password == "" {
+			return nil, fmt.Errorf("key is encrypted but no password was provided")
+		}
+		decrypted, err := decryptPKCS8(block.Bytes, password)
+		if err != nil {
+			return nil, err
+		}
+		return x509.ParsePKCS8PrivateKey(decrypted)
+	}
+
+	//lint:ignore SA1019 Responsability lies with user
+	if x509.IsEncryptedPEMBlock(block) {
+		if password == "" {
+			return nil, fmt.Errorf("key is encrypted but no password was provided")
+		}
+		//lint:ignore SA1019 Responsability lies with user
+		decrypted, err := x509.DecryptPEMBlock(block, []byte(password))
+		if err != nil {
+			return nil, err
+		}
+		data = decrypted
+	}
+
+	if key, err := x509.ParsePKCS8PrivateKey(data); err == nil {
+		return key, nil
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(data); err == nil {
+		return key, nil
+	}
+	if key, err := x509.ParseECPrivateKey(data); err == nil {
+		return key, nil
+	}
+
+	return nil, fmt.Errorf("unsupported private key format")
+}
+
+// keyTypeForPublicKey returns the key type enum value for the given public key
+func keyTypeForPublicKey(pub crypto.PublicKey) (string, error) {
+	switch publicKey := pub.(type) {
+	case *rsa.PublicKey:
+		size := publicKey.Size()
+		switch size {
+		case 256:
+			return KeyTypeRSA_2048, nil
+		case 384:
+			return KeyTypeRSA_3072, nil
+		case 512:
+			return KeyTypeRSA_4096, nil
+		case 1024:
+			return KeyTypeRSA_8192, nil
+		default:
+			return "", fmt.Errorf("unsupported RSA key length: %d", size)
+		}
+	case *ecdsa.PublicKey:
+		size := publicKey.Params().BitSize
+		switch size {
+		case 256:
+			return KeyTypeECDSA_256, nil
+		case 384:
+			return KeyTypeECDSA_384, nil
+		case 521:
+			return KeyTypeECDSA_521, nil
+		default:
+			return "", fmt.Errorf("unsupported ECC curve size: %d", size)
+		}
+	case ed25519.PublicKey:
+		return KeyTypeEd25519, nil
+	}
+	return "", fmt.Errorf("unsupported public key algorithm: %T", pub)
+}
@@ -0,0 +1,262 @@
+package tls
+
+import (
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+)
+
+// Name describes a X.509 name object
+type Name struct {
+	Organization       string
+	City               string
+	Province           string
+	Country            string
+	CommonName         string
+	OrganizationalUnit []string
+	StreetAddress      []string
+	PostalCode         []string
+	SerialNumber       string
+	DomainComponent    []string
+	EmailAddress       []string
+	// Attributes are any additional attributes with custom OIDs
+	Attributes []NameAttribute
+	// RDNs is the complete ordered sequence of relative distinguished names, where each entry is a set of one or more
+	// attributes. When set it is encoded as-is and all other properties are ignored. Names read from a certificate
+	// only include RDNs if they can't be described by the other properties, such as when the attributes are in an
+	// unusual order or a standard attribute has multiple values.
+	RDNs [][]NameAttribute
+}
+
+// NameAttribute describes a single attribute of a X.509 name
+type NameAttribute struct {
+	// OID is the attribute type, such as 2.5.4.3 for the common name
+	OID   string
+	Value string
+	// Type is the ASN.1 string type of Value, such as ASN1TypeUTF8String, or ASN1TypeRaw if Value is a hexadecimal DER
+	// encoded value of any other type. If empty, domain components and email addresses are encoded as an IA5String and
+	// all other attributes as a PrintableString if possible, otherwise as a UTF8String.
+	Type string
+}
+
+var (
+	oidNameCommonName         = asn1.ObjectIdentifier{2, 5, 4, 3}
+	oidNameSerialNumber       = asn1.ObjectIdentifier{2, 5, 4, 5}
+	oidNameCountry            = asn1.ObjectIdentifier{2, 5, 4, 6}
+	oidNameLocality           = asn1.ObjectIdentifier{2, 5, 4, 7}
+	oidNameProvince           = asn1.ObjectIdentifier{2, 5, 4, 8}
+	oidNameStreetAddress      = asn1.ObjectIdentifier{2, 5, 4, 9}
+	oidNameOrganization       = asn1.ObjectIdentifier{2, 5, 4, 10}
+	oidNameOrganizationalUnit = asn1.ObjectIdentifier{2, 5, 4, 11}
+	oidNamePostalCode         = asn1.ObjectIdentifier{2, 5, 4, 17}
+	oidNameDomainComponent    = asn1.ObjectIdentifier{0, 9, 2342, 19200300, 100, 1, 25}
+	oidNameEmailAddress       = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 1}
+)
+
+// attributes returns the ordered attributes described by the properties of this name, ignoring RDNs. Attributes are
+// in the same order as pkix.Name.ToRDNSequence, with the attributes it doesn't support following the serial number.
+func (n Name) attributes() []NameAttribute {
+	attributes := []NameAttribute{}
+	add := func(oid asn1.ObjectIdentifier, values ...string) {
+		for _, value := range values {
+			if value != "" {
+				attributes = append(attributes, NameAttribute{OID: oid.String(), Value: value})
+			}
+		}
+	}
+
+	add(oidNameCountry, n.Country)
+	add(oidNameProvince, n.Province)
+	add(oidNameLocality, n.City)
+	add(oidNameStreetAddress, n.StreetAddress...)
+	add(oidNamePostalCode, n.PostalCode...)
+	add(oidNameOrganization, n.Organization)
+	add(oidNameOrganizationalUnit, n.OrganizationalUnit...)
+	add(oidNameCommonName, n.CommonName)
+	add(oidNameSerialNumber, n.SerialNumber)
+	add(oidNameDomainComponent, n.DomainComponent...)
+	add(oidNameEmailAddress, n.EmailAddress...)
+	attributes = append(attributes, n.Attributes...)
+	return attributes
+}
+
+// rdns returns the relative distinguished names for this name
+func (n Name) rdns() [][]NameAttribute {
+	if len(n.RDNs) > 0 {
+		return n.RDNs
+	}
+
+	rdns := [][]NameAttribute{}
+	for _, attribute := range n.attributes() {
+		rdns = append(rdns, []NameAttribute{attribute})
+	}
+	return rdns
+}
+
+func (n Name) rdnSequence() (pkix.RDNSequence, error) {
+	sequence := pkix.RDNSequence{}
+	for _, rdn := range n.rdns() {
+		if len(rdn) == 0 {
+			return nil, fmt.Errorf("empty relative distinguished name")
+		}
+		set := pkix.RelativeDistinguishedNameSET{}
+		for _, attribute := range rdn {
+			oid, err := parseOid(attribute.OID)
+			if err != nil {
+				return nil, fmt.Errorf("invalid name attribute oid: %s", attribute.OID)
+			}
+			valueType := attribute.Type
+			if valueType == "" {
+				valueType = defaultNameAttributeType(oid, attribute.Value)
+			}
+			if _, isString := asn1StringTags[valueType]; !isString && valueType != ASN1TypeRaw {
+				return nil, fmt.Errorf("invalid type for name attribute %s: '%s'", attribute.OID, valueType)
+			}
+			value, err := ASN1Value{Type: valueType, Value: attribute.Value}.marshal()
+			if err != nil {
+				return nil, fmt.Errorf("invalid value for name attribute %s: %s", attribute.OID, err.Error())
+			}
+			set = append(set, pkix.AttributeTypeAndValue{Type: oid, Value: asn1.RawValue{FullBytes: value}})
+		}
+		sequence = append(sequence, set)
+	}
+	return sequence, nil
+}
+
+// pkix returns the pkix name and DER encoded RDN sequence of this name
+func (n Name) pkix() (pkix.Name, []byte, error) {
+	sequence, err := n.rdnSequence()
+	if err != nil {
+		return pkix.Name{}, nil, err
+	}
+	raw, err := asn1.Marshal(sequence)
+	if err != nil {
+		return pkix.Name{}, nil, fmt.Errorf("invalid name: %s", err.Error())
+	}
+
+	// The sequence holds encoded values, so it's parsed again for pkix.Name to see the string values
+	var parsed pkix.RDNSequence
+	if _, err := asn1.Unmarshal(raw, &parsed); err != nil {
+		return pkix.Name{}, nil, fmt.Errorf("invalid name: %s", err.Error())
+	}
+	name := pkix.Name{}
+	name.FillFromRDNSequence(&parsed)
+	return name, raw, nil
+}
+
+// defaultNameAttributeType returns the ASN.1 string type used for a name attribute without a type
+func defaultNameAttributeType(oid asn1.ObjectIdentifier, value string) string {
+	// RFC 5280 requires that these attributes are encoded as an IA5String
+	if oid.Equal(oidNameDomainComponent) || oid.Equal(oidNameEmailAddress) {
+		return ASN1TypeIA5String
+	}
+	if _, err := asn1.MarshalWithParams(value, "printable"); err == nil {
+		return ASN1TypePrintableString
+	}
+	return ASN1TypeUTF8String
+}
+
+// rawAttributeTypeAndValue is a pkix.AttributeTypeAndValue that keeps the encoded value, so the type of string values
+// isn't lost
+type rawAttributeTypeAndValue struct {
+	Type  asn1.ObjectIdentifier
+	Value asn1.RawValue
+}
+
+// rawRelativeDistinguishedNameSET is a pkix.RelativeDistinguishedNameSET of rawAttributeTypeAndValue. The SET suffix
+// makes encoding/asn1 treat it as a SET.
+type rawRelativeDistinguishedNameSET []rawAttributeTypeAndValue
+
+type rawRDNSequence []rawRelativeDistinguishedNameSET
+
+// nameAttributeFromRaw returns the name attribute for the given type and encoded value. String values of the default
+// type for the attribute don't include a Type, and values that aren't a string are hexadecimal with the raw type.
+func nameAttributeFromRaw(atv rawAttributeTypeAndValue) NameAttribute {
+	attribute := NameAttribute{OID: atv.Type.String()}
+	value := asn1ValueFromRaw(atv.Value)
+	if _, isString := asn1StringTags[value.Type]; !isString || value.Tagging != "" {
+		attribute.Value = hex.EncodeToString(atv.Value.FullBytes)
+		attribute.Type = ASN1TypeRaw
+		return attribute
+	}
+	attribute.Value = value.Value
+	if value.Type != defaultNameAttributeType(atv.Type, value.Value) {
+		attribute.Type = value.Type
+	}
+	return attribute
+}
+
+// nameFromRaw returns the name for the given DER encoded RDN sequence, such as the RawSubject of a certificate
+func nameFromRaw(raw []byte) Name {
+	var sequence rawRDNSequence
+	if _, err := asn1.Unmarshal(raw, &sequence); err != nil {
+		return Name{}
+	}
+	return nameFromRDNSequence(sequence)
+}
+
+func nameFromRDNSequence(sequence rawRDNSequence) Name {
+	n := Name{}
+	rdns := [][]NameAttribute{}
+
+	setFirst := func(field *string, value string) bool {
+		if *field != "" {
+			return false
+		}
+		*field = value
+		return true
+	}
+
+	for _, set := range sequence {
+		rdn := []NameAttribute{}
+		for _, atv := range set {
+			attribute := nameAttributeFromRaw(atv)
+			rdn = append(rdn, attribute)
+
+			// Attributes that aren't of the default type can only be described as custom attributes
+			value := attribute.Value
+			isStandard := attribute.Type == ""
+			switch {
+			case !isStandard:
+				break
+			case atv.Type.Equal(oidNameCommonName):
+				isStandard = setFirst(&n.CommonName, value)
+			case atv.Type.Equal(oidNameSerialNumber):
+				isStandard = setFirst(&n.SerialNumber, value)
+			case atv.Type.Equal(oidNameCountry):
+				isStandard = setFirst(&n.Country, value)
+			case atv.Type.Equal(oidNameLocality):
+				isStandard = setFirst(&n.City, value)
+			case atv.Type.Equal(oidNameProvince):
+				isStandard = setFirst(&n.Province, value)
+			case atv.Type.Equal(oidNameOrganization):
+				isStandard = setFirst(&n.Organization, value)
+			case atv.Type.Equal(oidNameStreetAddress):
+				n.StreetAddress = append(n.StreetAddress, value)
+			case atv.Type.Equal(oidNameOrganizationalUnit):
+				n.OrganizationalUnit = append(n.OrganizationalUnit, value)
+			case atv.Type.Equal(oidNamePostalCode):
+				n.PostalCode = append(n.PostalCode, value)
+			case atv.Type.Equal(oidNameDomainComponent):
+				n.DomainComponent = append(n.DomainComponent, value)
+			case atv.Type.Equal(oidNameEmailAddress):
+				n.EmailAddress = append(n.EmailAddress, value)
+			default:
+				n.Attributes = append(n.Attributes, attribute)
+			}
+			if !isStandard {
+				n.Attributes = append(n.Attributes, attribute)
+			}
+		}
+		rdns = append(rdns, rdn)
+	}
+
+	// Only include the full RDN sequence if encoding the properties would not produce the same name
+	if !reflect.DeepEqual(n.rdns(), rdns) {
+		n.RDNs = rdns
+	}
+
+	return n
+}
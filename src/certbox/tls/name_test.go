@@ -0,0 +1,249 @@
+package tls_test
+
+import (
+	"bytes"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/tls-inspector/certbox/tls"
+)
+
+func assertNameRoundTrip(t *testing.T, name tls.Name) {
+	certificate, err := tls.GenerateCertificate(tls.CertificateRequest{
+		KeyType:            tls.KeyTypeECDSA_256,
+		SignatureAlgorithm: tls.SignatureAlgorithmSHA256,
+		Subject:            name,
+		Validity: tls.DateRange{
+			NotBefore: "2001-01-01",
+			NotAfter:  "2002-01-01",
+		},
+	}, nil)
+	if err != nil {
+		t.Fatalf("Error generating certificate: %s", err.Error())
+	}
+	if !reflect.DeepEqual(certificate.Subject, name) {
+		t.Errorf("Generated certificate subject does not match.\nExpected: %+v\nGot:      %+v", name, certificate.Subject)
+	}
+
+	pemData, _, err := tls.ExportPEM(certificate)
+	if err != nil {
+		t.Fatalf("Error exporting certificate: %s", err.Error())
+	}
+	imported, err := tls.ImportPEMCertificate(pemData)
+	if err != nil {
+		t.Fatalf("Error importing certificate: %s", err.Error())
+	}
+	if !reflect.DeepEqual(imported.Subject, name) {
+		t.Errorf("Imported certificate subject does not match.\nExpected: %+v\nGot:      %+v", name, imported.Subject)
+	}
+
+	clone, err := certificate.Clone()
+	if err != nil {
+		t.Fatalf("Error cloning certificate: %s", err.Error())
+	}
+	if !reflect.DeepEqual(clone.Subject, name) {
+		t.Errorf("Cloned certificate subject does not match.\nExpected: %+v\nGot:      %+v", name, clone.Subject)
+	}
+
+	data, err := json.Marshal(certificate.Subject)
+	if err != nil {
+		t.Fatalf("Error encoding name: %s", err.Error())
+	}
+	decoded := tls.Name{}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Error decoding name: %s", err.Error())
+	}
+	again, err := tls.GenerateCertificate(tls.CertificateRequest{
+		KeyType:            tls.KeyTypeECDSA_256,
+		SignatureAlgorithm: tls.SignatureAlgorithmSHA256,
+		Subject:            decoded,
+		Validity: tls.DateRange{
+			NotBefore: "2001-01-01",
+			NotAfter:  "2002-01-01",
+		},
+	}, nil)
+	if err != nil {
+		t.Fatalf("Error generating certificate: %s", err.Error())
+	}
+	if !reflect.DeepEqual(again.Subject, name) {
+		t.Errorf("Subject from JSON does not match.\nExpected: %+v\nGot:      %+v", name, again.Subject)
+	}
+}
+
+func TestNameAttributes(t *testing.T) {
+	t.Parallel()
+
+	assertNameRoundTrip(t, tls.Name{
+		Organization:       "Example Inc.",
+		City:               "Vancouver",
+		Province:           "British Columbia",
+		Country:            "CA",
+		CommonName:         "example.com",
+		OrganizationalUnit: []string{"Engineering", "Security"},
+		StreetAddress:      []string{"123 Fake St."},
+		PostalCode:         []string{"V1V 1V1"},
+		SerialNumber:       "1234",
+		DomainComponent:    []string{"com", "example"},
+		EmailAddress:       []string{"admin@example.com"},
+		Attributes: []tls.NameAttribute{
+			{OID: "1.2.3.4.5", Value: "custom"},
+		},
+	})
+}
+
+func TestNameRDNs(t *testing.T) {
+	t.Parallel()
+
+	// Multi-valued RDN and attributes not in the usual order. Attributes within an RDN are a DER SET, so they must be
+	// in their canonical order to round-trip.
+	assertNameRoundTrip(t, tls.Name{
+		Organization: "Other Inc.",
+		CommonName:   "example.com",
+		Attributes: []tls.NameAttribute{
+			{OID: "2.5.4.10", Value: "Example Inc."},
+		},
+		RDNs: [][]tls.NameAttribute{
+			{{OID: "2.5.4.3", Value: "example.com"}},
+			{{OID: "2.5.4.10", Value: "Other Inc."}, {OID: "2.5.4.10", Value: "Example Inc."}},
+		},
+	})
+
+	// Names that can be described without RDNs should not include them
+	certificate, err := tls.GenerateCertificate(tls.CertificateRequest{
+		KeyType:            tls.KeyTypeECDSA_256,
+		SignatureAlgorithm: tls.SignatureAlgorithmSHA256,
+		Subject: tls.Name{
+			RDNs: [][]tls.NameAttribute{
+				{{OID: "2.5.4.6", Value: "CA"}},
+				{{OID: "2.5.4.3", Value: "example.com"}},
+			},
+		},
+		Validity: tls.DateRange{
+			NotBefore: "2001-01-01",
+			NotAfter:  "2002-01-01",
+		},
+	}, nil)
+	if err != nil {
+		t.Fatalf("Error generating certificate: %s", err.Error())
+	}
+	if certificate.Subject.RDNs != nil {
+		t.Errorf("Unexpected RDNs for simple name: %+v", certificate.Subject.RDNs)
+	}
+	if certificate.Subject.Country != "CA" || certificate.Subject.CommonName != "example.com" {
+		t.Errorf("Unexpected subject %+v", certificate.Subject)
+	}
+
+	if _, err := tls.GenerateCertificate(tls.CertificateRequest{
+		KeyType:            tls.KeyTypeECDSA_256,
+		SignatureAlgorithm: tls.SignatureAlgorithmSHA256,
+		Subject: tls.Name{
+			Attributes: []tls.NameAttribute{{OID: "not an oid", Value: "foo"}},
+		},
+		Validity: tls.DateRange{
+			NotBefore: "2001-01-01",
+			NotAfter:  "2002-01-01",
+		},
+	}, nil); err == nil {
+		t.Errorf("No error seen when one expected for invalid name attribute oid")
+	}
+}
+
+func TestNameOrder(t *testing.T) {
+	t.Parallel()
+
+	// Attributes supported by pkix.Name are encoded in the same order as pkix.Name.ToRDNSequence
+	certificate, err := tls.GenerateCertificate(tls.CertificateRequest{
+		KeyType:            tls.KeyTypeECDSA_256,
+		SignatureAlgorithm: tls.SignatureAlgorithmSHA256,
+		Subject: tls.Name{
+			Organization:       "Example Inc.",
+			City:               "Vancouver",
+			Province:           "British Columbia",
+			Country:            "CA",
+			CommonName:         "example.com",
+			OrganizationalUnit: []string{"Engineering"},
+			StreetAddress:      []string{"123 Fake St."},
+			PostalCode:         []string{"V1V 1V1"},
+			SerialNumber:       "1234",
+		},
+		Validity: tls.DateRange{
+			NotBefore: "2001-01-01",
+			NotAfter:  "2002-01-01",
+		},
+	}, nil)
+	if err != nil {
+		t.Fatalf("Error generating certificate: %s", err.Error())
+	}
+	expected, err := asn1.Marshal(pkix.Name{
+		Organization:       []string{"Example Inc."},
+		Locality:           []string{"Vancouver"},
+		Province:           []string{"British Columbia"},
+		Country:            []string{"CA"},
+		CommonName:         "example.com",
+		OrganizationalUnit: []string{"Engineering"},
+		StreetAddress:      []string{"123 Fake St."},
+		PostalCode:         []string{"V1V 1V1"},
+		SerialNumber:       "1234",
+	}.ToRDNSequence())
+	if err != nil {
+		t.Fatalf("Error encoding name: %s", err.Error())
+	}
+	if !bytes.Equal(certificate.X509().RawSubject, expected) {
+		t.Errorf("Unexpected subject encoding %x", certificate.X509().RawSubject)
+	}
+}
+
+func TestNameAttributeTypes(t *testing.T) {
+	t.Parallel()
+
+	name := tls.Name{
+		Attributes: []tls.NameAttribute{
+			{OID: "2.5.4.3", Value: "example.com", Type: tls.ASN1TypeUTF8String},
+			{OID: "1.2.3.4.5", Value: "custom", Type: tls.ASN1TypeIA5String},
+			{OID: "1.2.3.4.6", Value: "020105", Type: tls.ASN1TypeRaw},
+		},
+	}
+	assertNameRoundTrip(t, name)
+
+	certificate, err := tls.GenerateCertificate(tls.CertificateRequest{
+		KeyType:            tls.KeyTypeECDSA_256,
+		SignatureAlgorithm: tls.SignatureAlgorithmSHA256,
+		Subject:            name,
+		Validity: tls.DateRange{
+			NotBefore: "2001-01-01",
+			NotAfter:  "2002-01-01",
+		},
+	}, nil)
+	if err != nil {
+		t.Fatalf("Error generating certificate: %s", err.Error())
+	}
+	details, err := tls.InspectCertificate(*certificate)
+	if err != nil {
+		t.Fatalf("Error inspecting certificate: %s", err.Error())
+	}
+	types := []string{}
+	for _, rdn := range details.Subject.RDNs {
+		for _, attribute := range rdn {
+			types = append(types, attribute.Type)
+		}
+	}
+	if !reflect.DeepEqual(types, []string{tls.ASN1TypeUTF8String, tls.ASN1TypeIA5String, tls.ASN1TypeRaw}) {
+		t.Errorf("Unexpected attribute types %+v", types)
+	}
+
+	name.Attributes = []tls.NameAttribute{{OID: "1.2.3.4.5", Value: "1", Type: tls.ASN1TypeInteger}}
+	if _, err := tls.GenerateCertificate(tls.CertificateRequest{
+		KeyType:            tls.KeyTypeECDSA_256,
+		SignatureAlgorithm: tls.SignatureAlgorithmSHA256,
+		Subject:            name,
+		Validity: tls.DateRange{
+			NotBefore: "2001-01-01",
+			NotAfter:  "2002-01-01",
+		},
+	}, nil); err == nil {
+		t.Errorf("No error seen when one expected for invalid name attribute type")
+	}
+}
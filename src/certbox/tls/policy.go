@@ -0,0 +1,369 @@
+package tls
+
+import (
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"fmt"
+	"unicode/utf16"
+)
+
+// Common certificate policy OIDs
+const (
+	// PolicyAnyPolicy is the special anyPolicy OID defined in RFC 5280
+	PolicyAnyPolicy = "2.5.29.32.0"
+	// PolicyExtendedValidation is the CA/Browser Forum extended validation policy
+	PolicyExtendedValidation = "2.23.140.1.1"
+	// PolicyDomainValidated is the CA/Browser Forum domain validated policy
+	PolicyDomainValidated = "2.23.140.1.2.1"
+	// PolicyOrganizationValidated is the CA/Browser Forum organization validated policy
+	PolicyOrganizationValidated = "2.23.140.1.2.2"
+	// PolicyIndividualValidated is the CA/Browser Forum individual validated policy
+	PolicyIndividualValidated = "2.23.140.1.2.3"
+)
+
+var (
+	oidExtensionCertificatePolicies = asn1.ObjectIdentifier([]int{2, 5, 29, 32})
+	oidExtensionPolicyMappings      = asn1.ObjectIdentifier([]int{2, 5, 29, 33})
+	oidExtensionPolicyConstraints   = asn1.ObjectIdentifier([]int{2, 5, 29, 36})
+	oidExtensionInhibitAnyPolicy    = asn1.ObjectIdentifier([]int{2, 5, 29, 54})
+	oidPolicyQualifierCPS           = asn1.ObjectIdentifier([]int{1, 3, 6, 1, 5, 5, 7, 2, 1})
+	oidPolicyQualifierUserNotice    = asn1.ObjectIdentifier([]int{1, 3, 6, 1, 5, 5, 7, 2, 2})
+)
+
+// CertificatePolicy describes a single policy of the certificate policies extension
+type CertificatePolicy struct {
+	// OID is the policy identifier, such as PolicyDomainValidated
+	OID string
+	// CPS are the URIs of the certification practice statements for this policy
+	CPS         []string
+	UserNotices []UserNotice
+}
+
+// UserNotice describes a user notice policy qualifier. Either or both of a notice reference (Organization and
+// NoticeNumbers) or ExplicitText may be provided.
+type UserNotice struct {
+	Organization  string
+	NoticeNumbers []int
+	ExplicitText  string
+}
+
+// PolicyMapping describes a mapping from a policy of the issuer domain to an equivalent policy of the subject domain
+type PolicyMapping struct {
+	IssuerDomainPolicy  string
+	SubjectDomainPolicy string
+}
+
+// PolicyConstraints describes the policy mappings, policy constraints and inhibit anyPolicy extensions of a
+// certificate authority. Each limit is the number of additional certificates that may follow this certificate in
+// the path before the limit applies. Zero means the limit is absent unless the matching Zero property is set.
+type PolicyConstraints struct {
+	Mappings                  []PolicyMapping
+	RequireExplicitPolicy     int
+	RequireExplicitPolicyZero bool
+	InhibitPolicyMapping      int
+	InhibitPolicyMappingZero  bool
+	InhibitAnyPolicy          int
+	InhibitAnyPolicyZero      bool
+}
+
+type policyInformation struct {
+	Policy     asn1.ObjectIdentifier
+	Qualifiers []policyQualifierInfo `asn1:"optional"`
+}
+
+type policyQualifierInfo struct {
+	Id        asn1.ObjectIdentifier
+	Qualifier asn1.RawValue
+}
+
+type noticeReference struct {
+	Organization  asn1.RawValue
+	NoticeNumbers []int
+}
+
+type policyMapping struct {
+	IssuerDomainPolicy  asn1.ObjectIdentifier
+	SubjectDomainPolicy asn1.ObjectIdentifier
+}
+
+func (c PolicyConstraints) isEmpty() bool {
+	return len(c.Mappings) == 0 &&
+		c.RequireExplicitPolicy == 0 && !c.RequireExplicitPolicyZero &&
+		c.InhibitPolicyMapping == 0 && !c.InhibitPolicyMappingZero &&
+		c.InhibitAnyPolicy == 0 && !c.InhibitAnyPolicyZero
+}
+
+// certificatePoliciesExtension returns the certificate policies extension for the given policies
+func certificatePoliciesExtension(policies []CertificatePolicy) (pkix.Extension, error) {
+	information := []policyInformation{}
+	for _, policy := range policies {
+		oid, err := parseOid(policy.OID)
+		if err != nil {
+			return pkix.Extension{}, fmt.Errorf("invalid policy oid: %s", policy.OID)
+		}
+
+		info := policyInformation{Policy: oid}
+		for _, cps := range policy.CPS {
+			if !isIA5String(cps) {
+				return pkix.Extension{}, fmt.Errorf("invalid cps uri %s", cps)
+			}
+			info.Qualifiers = append(info.Qualifiers, policyQualifierInfo{
+				Id:        oidPolicyQualifierCPS,
+				Qualifier: asn1.RawValue{Tag: asn1.TagIA5String, Bytes: []byte(cps)},
+			})
+		}
+		for _, notice := range policy.UserNotices {
+			value, err := notice.marshal()
+			if err != nil {
+				return pkix.Extension{}, err
+			}
+			info.Qualifiers = append(info.Qualifiers, policyQualifierInfo{
+				Id:        oidPolicyQualifierUserNotice,
+				Qualifier: asn1.RawValue{FullBytes: value},
+			})
+		}
+		information = append(information, info)
+	}
+
+	value, err := asn1.Marshal(information)
+	if err != nil {
+		return pkix.Extension{}, fmt.Errorf("invalid certificate policies: %s", err.Error())
+	}
+	return pkix.Extension{Id: oidExtensionCertificatePolicies, Value: value}, nil
+}
+
+func (n UserNotice) marshal() ([]byte, error) {
+	if n.Organization == "" && len(n.NoticeNumbers) == 0 && n.ExplicitText == "" {
+		return nil, fmt.Errorf("empty user notice")
+	}
+	if (n.Organization == "") != (len(n.NoticeNumbers) == 0) {
+		return nil, fmt.Errorf("user notice reference requires both an organization and notice numbers")
+	}
+
+	elements := []byte{}
+	if n.Organization != "" {
+		reference, err := asn1.Marshal(noticeReference{
+			Organization:  asn1.RawValue{Tag: asn1.TagUTF8String, Bytes: []byte(n.Organization)},
+			NoticeNumbers: n.NoticeNumbers,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("invalid user notice reference: %s", err.Error())
+		}
+		elements = append(elements, reference...)
+	}
+	if n.ExplicitText != "" {
+		text, err := asn1.MarshalWithParams(n.ExplicitText, "utf8")
+		if err != nil {
+			return nil, fmt.Errorf("invalid user notice text: %s", err.Error())
+		}
+		elements = append(elements, text...)
+	}
+
+	return asn1.Marshal(asn1.RawValue{Tag: asn1.TagSequence, IsCompound: true, Bytes: elements})
+}
+
+// extensions returns the policy mappings, policy constraints and inhibit anyPolicy extensions described by c. All
+// of them are marked critical, as required or recommended by RFC 5280.
+func (c PolicyConstraints) extensions() ([]pkix.Extension, error) {
+	extensions := []pkix.Extension{}
+
+	if len(c.Mappings) > 0 {
+		mappings := []policyMapping{}
+		for _, mapping := range c.Mappings {
+			issuerPolicy, err := parseOid(mapping.IssuerDomainPolicy)
+			if err != nil {
+				return nil, fmt.Errorf("invalid issuer domain policy oid: %s", mapping.IssuerDomainPolicy)
+			}
+			subjectPolicy, err := parseOid(mapping.SubjectDomainPolicy)
+			if err != nil {
+				return nil, fmt.Errorf("invalid subject domain policy oid: %s", mapping.SubjectDomainPolicy)
+			}
+			if mapping.IssuerDomainPolicy == PolicyAnyPolicy || mapping.SubjectDomainPolicy == PolicyAnyPolicy {
+				return nil, fmt.Errorf("policies can not be mapped to or from anyPolicy")
+			}
+			mappings = append(mappings, policyMapping{issuerPolicy, subjectPolicy})
+		}
+		value, err := asn1.Marshal(mappings)
+		if err != nil {
+			return nil, err
+		}
+		extensions = append(extensions, pkix.Extension{Id: oidExtensionPolicyMappings, Critical: true, Value: value})
+	}
+
+	if c.RequireExplicitPolicy < 0 || c.InhibitPolicyMapping < 0 || c.InhibitAnyPolicy < 0 {
+		return nil, fmt.Errorf("policy constraints can not be negative")
+	}
+
+	constraints := []byte{}
+	if c.RequireExplicitPolicy > 0 || c.RequireExplicitPolicyZero {
+		value, err := asn1.MarshalWithParams(c.RequireExplicitPolicy, "tag:0")
+		if err != nil {
+			return nil, err
+		}
+		constraints = append(constraints, value...)
+	}
+	if c.InhibitPolicyMapping > 0 || c.InhibitPolicyMappingZero {
+		value, err := asn1.MarshalWithParams(c.InhibitPolicyMapping, "tag:1")
+		if err != nil {
+			return nil, err
+		}
+		constraints = append(constraints, value...)
+	}
+	if len(constraints) > 0 {
+		value, err := asn1.Marshal(asn1.RawValue{Tag: asn1.TagSequence, IsCompound: true, Bytes: constraints})
+		if err != nil {
+			return nil, err
+		}
+		extensions = append(extensions, pkix.Extension{Id: oidExtensionPolicyConstraints, Critical: true, Value: value})
+	}
+
+	if c.InhibitAnyPolicy > 0 || c.InhibitAnyPolicyZero {
+		value, err := asn1.Marshal(c.InhibitAnyPolicy)
+		if err != nil {
+			return nil, err
+		}
+		extensions = append(extensions, pkix.Extension{Id: oidExtensionInhibitAnyPolicy, Critical: true, Value: value})
+	}
+
+	return extensions, nil
+}
+
+// certificatePoliciesFromExtension returns the policies described by the given certificate policies extension value.
+// Policy qualifiers other than CPS URIs and user notices are ignored.
+func certificatePoliciesFromExtension(value []byte) ([]CertificatePolicy, error) {
+	information := []policyInformation{}
+	if _, err := asn1.Unmarshal(value, &information); err != nil {
+		return nil, err
+	}
+
+	policies := []CertificatePolicy{}
+	for _, info := range information {
+		policy := CertificatePolicy{OID: info.Policy.String()}
+		for _, qualifier := range info.Qualifiers {
+			switch {
+			case qualifier.Id.Equal(oidPolicyQualifierCPS):
+				policy.CPS = append(policy.CPS, string(qualifier.Qualifier.Bytes))
+			case qualifier.Id.Equal(oidPolicyQualifierUserNotice):
+				notice, err := userNoticeFromQualifier(qualifier.Qualifier.FullBytes)
+				if err != nil {
+					return nil, err
+				}
+				policy.UserNotices = append(policy.UserNotices, notice)
+			}
+		}
+		policies = append(policies, policy)
+	}
+	return policies, nil
+}
+
+func userNoticeFromQualifier(value []byte) (UserNotice, error) {
+	notice := UserNotice{}
+
+	var sequence asn1.RawValue
+	if _, err := asn1.Unmarshal(value, &sequence); err != nil {
+		return notice, fmt.Errorf("invalid user notice: %s", err.Error())
+	}
+
+	rest := sequence.Bytes
+	for len(rest) > 0 {
+		var element asn1.RawValue
+		var err error
+		rest, err = asn1.Unmarshal(rest, &element)
+		if err != nil {
+			return notice, fmt.Errorf("invalid user notice: %s", err.Error())
+		}
+
+		if element.Class == asn1.ClassUniversal && element.Tag == asn1.TagSequence {
+			reference := noticeReference{}
+			if _, err := asn1.Unmarshal(element.FullBytes, &reference); err != nil {
+				return notice, fmt.Errorf("invalid user notice reference: %s", err.Error())
+			}
+			notice.Organization = displayText(reference.Organization)
+			notice.NoticeNumbers = reference.NoticeNumbers
+		} else {
+			notice.ExplicitText = displayText(element)
+		}
+	}
+
+	return notice, nil
+}
+
+// displayText returns the string value of a DisplayText, which may be an IA5String, VisibleString, BMPString or
+// UTF8String
+func displayText(value asn1.RawValue) string {
+	if value.Tag != 30 {
+		return string(value.Bytes)
+	}
+
+	// BMPString is big-endian UTF-16
+	codes := []uint16{}
+	for i := 0; i+1 < len(value.Bytes); i += 2 {
+		codes = append(codes, uint16(value.Bytes[i])<<8|uint16(value.Bytes[i+1]))
+	}
+	return string(utf16.Decode(codes))
+}
+
+// policyConstraintsFromExtensions returns the policy constraints described by the given certificate extensions
+func policyConstraintsFromExtensions(extensions []pkix.Extension) (PolicyConstraints, error) {
+	c := PolicyConstraints{}
+
+	for _, ext := range extensions {
+		switch {
+		case ext.Id.Equal(oidExtensionPolicyMappings):
+			mappings := []policyMapping{}
+			if _, err := asn1.Unmarshal(ext.Value, &mappings); err != nil {
+				return c, fmt.Errorf("invalid policy mappings: %s", err.Error())
+			}
+			for _, mapping := range mappings {
+				c.Mappings = append(c.Mappings, PolicyMapping{
+					IssuerDomainPolicy:  mapping.IssuerDomainPolicy.String(),
+					SubjectDomainPolicy: mapping.SubjectDomainPolicy.String(),
+				})
+			}
+		case ext.Id.Equal(oidExtensionPolicyConstraints):
+			var sequence asn1.RawValue
+			if _, err := asn1.Unmarshal(ext.Value, &sequence); err != nil {
+				return c, fmt.Errorf("invalid policy constraints: %s", err.Error())
+			}
+			rest := sequence.Bytes
+			for len(rest) > 0 {
+				var element asn1.RawValue
+				var err error
+				rest, err = asn1.Unmarshal(rest, &element)
+				if err != nil {
+					return c, fmt.Errorf("invalid policy constraints: %s", err.Error())
+				}
+				var skipCerts int
+				if _, err := asn1.UnmarshalWithParams(element.FullBytes, &skipCerts, fmt.Sprintf("tag:%d", element.Tag)); err != nil {
+					return c, fmt.Errorf("invalid policy constraints: %s", err.Error())
+				}
+				switch element.Tag {
+				case 0:
+					c.RequireExplicitPolicy = skipCerts
+					c.RequireExplicitPolicyZero = skipCerts == 0
+				case 1:
+					c.InhibitPolicyMapping = skipCerts
+					c.InhibitPolicyMappingZero = skipCerts == 0
+				}
+			}
+		case ext.Id.Equal(oidExtensionInhibitAnyPolicy):
+			var skipCerts int
+			if _, err := asn1.Unmarshal(ext.Value, &skipCerts); err != nil {
+				return c, fmt.Errorf("invalid inhibit any policy: %s", err.Error())
+			}
+			c.InhibitAnyPolicy = skipCerts
+			c.InhibitAnyPolicyZero = skipCerts == 0
+		}
+	}
+
+	return c, nil
+}
+
+func isIA5String(value string) bool {
+	for i := 0; i < len(value); i++ {
+		if value[i] > 127 {
+			return false
+		}
+	}
+	return true
+}
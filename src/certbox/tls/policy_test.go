@@ -0,0 +1,210 @@
+package tls_test
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"math/big"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/tls-inspector/certbox/tls"
+)
+
+func policyRequest(isCA bool) tls.CertificateRequest {
+	return tls.CertificateRequest{
+		KeyType:            tls.KeyTypeECDSA_256,
+		SignatureAlgorithm: tls.SignatureAlgorithmSHA256,
+		Subject: tls.Name{
+			Organization: "example.com",
+			CommonName:   "example.com Policy CA",
+		},
+		Validity: tls.DateRange{
+			NotBefore: "2001-01-01",
+			NotAfter:  "2002-01-01",
+		},
+		Usage: tls.KeyUsage{
+			DigitalSignature: true,
+			CertSign:         true,
+		},
+		IsCertificateAuthority: isCA,
+	}
+}
+
+func TestCertificatePolicies(t *testing.T) {
+	t.Parallel()
+
+	policies := []tls.CertificatePolicy{
+		{
+			OID: tls.PolicyDomainValidated,
+		},
+		{
+			OID: tls.PolicyExtendedValidation,
+			CPS: []string{"https://example.com/cps"},
+			UserNotices: []tls.UserNotice{
+				{Organization: "Example Inc.", NoticeNumbers: []int{1, 2}, ExplicitText: "Ünïcödé notice"},
+				{ExplicitText: "Text only"},
+			},
+		},
+		{
+			OID: "1.2.3.4.5",
+			CPS: []string{"https://example.com/cps/1", "https://example.com/cps/2"},
+		},
+	}
+	constraints := tls.PolicyConstraints{
+		Mappings: []tls.PolicyMapping{
+			{IssuerDomainPolicy: "1.2.3.4.5", SubjectDomainPolicy: "1.2.3.4.6"},
+		},
+		RequireExplicitPolicyZero: true,
+		InhibitPolicyMapping:      2,
+		InhibitAnyPolicyZero:      true,
+	}
+
+	request := policyRequest(true)
+	request.Policies = policies
+	request.PolicyConstraints = constraints
+	certificate, err := tls.GenerateCertificate(request, nil)
+	if err != nil {
+		t.Fatalf("Error generating certificate: %s", err.Error())
+	}
+
+	x := certificate.X509()
+	if len(x.Policies) != 3 || x.Policies[1].String() != tls.PolicyExtendedValidation {
+		t.Errorf("Unexpected certificate policies %v", x.Policies)
+	}
+	for _, extension := range x.Extensions {
+		switch extension.Id.String() {
+		case "2.5.29.33", "2.5.29.36", "2.5.29.54":
+			if !extension.Critical {
+				t.Errorf("Extension %s should be critical", extension.Id)
+			}
+		case "2.5.29.32":
+			if extension.Critical {
+				t.Errorf("Certificate policies extension should not be critical")
+			}
+		}
+	}
+
+	clone, err := certificate.Clone()
+	if err != nil {
+		t.Fatalf("Error cloning certificate: %s", err.Error())
+	}
+	if !reflect.DeepEqual(clone.Policies, policies) {
+		t.Errorf("Cloned policies do not match.\nExpected: %+v\nGot:      %+v", policies, clone.Policies)
+	}
+	if !reflect.DeepEqual(clone.PolicyConstraints, constraints) {
+		t.Errorf("Cloned policy constraints do not match.\nExpected: %+v\nGot:      %+v", constraints, clone.PolicyConstraints)
+	}
+	for _, extension := range clone.Extensions {
+		t.Errorf("Unexpected extension %s in clone", extension.OID)
+	}
+}
+
+func TestCertificatePoliciesInvalid(t *testing.T) {
+	t.Parallel()
+
+	invalid := map[string]tls.CertificateRequest{}
+
+	request := policyRequest(true)
+	request.Policies = []tls.CertificatePolicy{{OID: "not an oid"}}
+	invalid["invalid policy oid"] = request
+
+	request = policyRequest(true)
+	request.Policies = []tls.CertificatePolicy{{OID: tls.PolicyDomainValidated, UserNotices: []tls.UserNotice{{Organization: "Example Inc."}}}}
+	invalid["notice reference without numbers"] = request
+
+	request = policyRequest(true)
+	request.PolicyConstraints = tls.PolicyConstraints{Mappings: []tls.PolicyMapping{{IssuerDomainPolicy: tls.PolicyAnyPolicy, SubjectDomainPolicy: "1.2.3.4"}}}
+	invalid["mapping from anyPolicy"] = request
+
+	request = policyRequest(false)
+	request.PolicyConstraints = tls.PolicyConstraints{InhibitAnyPolicy: 1}
+	invalid["constraints without certificate authority"] = request
+
+	request = policyRequest(true)
+	request.Policies = []tls.CertificatePolicy{{OID: tls.PolicyDomainValidated}}
+	request.Extensions = []tls.Extension{{OID: "2.5.29.32", Raw: "3000"}}
+	invalid["policies and a certificate policies extension"] = request
+
+	request = policyRequest(true)
+	request.PolicyConstraints = tls.PolicyConstraints{RequireExplicitPolicyZero: true}
+	request.Extensions = []tls.Extension{{OID: "2.5.29.36", Raw: "3000"}}
+	invalid["policy constraints and a policy constraints extension"] = request
+
+	for name, request := range invalid {
+		if _, err := tls.GenerateCertificate(request, nil); err == nil {
+			t.Errorf("No error seen when one expected for %s", name)
+		}
+	}
+}
+
+func TestCloneUnsupportedCertificatePolicies(t *testing.T) {
+	t.Parallel()
+
+	type qualifier struct {
+		ID    asn1.ObjectIdentifier
+		Value asn1.RawValue
+	}
+	type policy struct {
+		ID         asn1.ObjectIdentifier
+		Qualifiers []qualifier
+	}
+	// A user notice qualifier that isn't a sequence
+	value, err := asn1.Marshal([]policy{{
+		ID:         asn1.ObjectIdentifier{2, 23, 140, 1, 2, 1},
+		Qualifiers: []qualifier{{ID: asn1.ObjectIdentifier{1, 3, 6, 1, 5, 5, 7, 2, 2}, Value: asn1.RawValue{Tag: asn1.TagOctetString, Bytes: []byte{0xff}}}},
+	}})
+	if err != nil {
+		t.Fatalf("Error encoding policies: %s", err.Error())
+	}
+
+	pKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Error generating key: %s", err.Error())
+	}
+	template := &x509.Certificate{
+		SerialNumber:    big.NewInt(1),
+		Subject:         pkix.Name{CommonName: "example.com"},
+		NotBefore:       time.Date(2001, 1, 1, 0, 0, 0, 0, time.UTC),
+		NotAfter:        time.Date(2002, 1, 1, 0, 0, 0, 0, time.UTC),
+		ExtraExtensions: []pkix.Extension{{Id: asn1.ObjectIdentifier{2, 5, 29, 32}, Value: value}},
+	}
+	data, err := x509.CreateCertificate(rand.Reader, template, template, pKey.Public(), pKey)
+	if err != nil {
+		t.Fatalf("Error generating certificate: %s", err.Error())
+	}
+	certificate, err := tls.ImportDERCertificate(data)
+	if err != nil {
+		t.Fatalf("Error importing certificate: %s", err.Error())
+	}
+
+	clone, err := certificate.Clone()
+	if err != nil {
+		t.Fatalf("Error cloning certificate: %s", err.Error())
+	}
+	if len(clone.Policies) != 0 {
+		t.Errorf("Unexpected cloned policies %+v", clone.Policies)
+	}
+	if len(clone.Extensions) != 1 || clone.Extensions[0].OID != "2.5.29.32" {
+		t.Fatalf("Unexpected cloned extensions %+v", clone.Extensions)
+	}
+
+	generated, err := tls.GenerateCertificate(clone, nil)
+	if err != nil {
+		t.Fatalf("Error generating certificate: %s", err.Error())
+	}
+	found := false
+	for _, ext := range generated.X509().Extensions {
+		if ext.Id.Equal(asn1.ObjectIdentifier{2, 5, 29, 32}) {
+			found = bytes.Equal(ext.Value, value)
+		}
+	}
+	if !found {
+		t.Errorf("Generated certificate does not include the cloned certificate policies")
+	}
+}
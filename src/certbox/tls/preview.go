@@ -0,0 +1,124 @@
+package tls
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"fmt"
+	"math/big"
+	"sync"
+)
+
+// PreviewCertificate returns the certificate that GenerateCertificate would issue for the given request and issuer,
+// without the issuer's private key. The certificate is signed by a throwaway key so its signature is not valid. Unless
+// the request includes a private key, the public key is a placeholder of the requested type and size, so the subject
+// key identifier will differ from the generated certificate. The serial number is also only representative of what
+// would be generated.
+func PreviewCertificate(request CertificateRequest, issuer *Certificate) (*x509.Certificate, error) {
+	if !request.Validity.IsValid() {
+		return nil, fmt.Errorf("invalid validity")
+	}
+
+	var pub crypto.PublicKey
+	if request.PrivateKey != "" {
+		pKey, err := request.existingPrivateKey()
+		if err != nil {
+			return nil, err
+		}
+		pub = pKey.(crypto.Signer).Public()
+	} else {
+		var err error
+		pub, err = previewPublicKey(request.KeyType)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	tpl, err := request.template(pub, secureEntropy)
+	if err != nil {
+		return nil, err
+	}
+
+	parent, signingKey := *tpl, pub
+	var issuerX *x509.Certificate
+	if issuer != nil {
+		issuerX = issuer.X509()
+		parent, signingKey = *issuerX, issuerX.PublicKey
+	}
+	if err := finishTemplate(tpl, signingKey, request.SignatureAlgorithm, request.IsCertificateAuthority, issuerX); err != nil {
+		return nil, err
+	}
+
+	signer, err := previewSigner(tpl.SignatureAlgorithm)
+	if err != nil {
+		return nil, err
+	}
+	parent.PublicKey = signer.Public()
+	data, err := x509.CreateCertificate(rand.Reader, tpl, &parent, pub, signer)
+	if err != nil {
+		return nil, err
+	}
+	return x509.ParseCertificate(data)
+}
+
+// previewRSASigner is a throwaway RSA key that is only generated once, since RSA keys are slow to generate. It must be
+// at least 1040 bits to produce a SHA-512 PSS signature.
+var previewRSASigner = sync.OnceValues(func() (*rsa.PrivateKey, error) {
+	return rsa.GenerateKey(rand.Reader, 2048)
+})
+
+// previewSigner returns a throwaway key that can sign with the given signature algorithm
+func previewSigner(algorithm x509.SignatureAlgorithm) (crypto.Signer, error) {
+	switch algorithm {
+	case x509.ECDSAWithSHA256, x509.ECDSAWithSHA384, x509.ECDSAWithSHA512:
+		return ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case x509.PureEd25519:
+		_, pKey, err := ed25519.GenerateKey(rand.Reader)
+		return pKey, err
+	}
+	return previewRSASigner()
+}
+
+// previewPublicKey returns a public key of the given key type. RSA keys are not generated, as only their size is
+// meaningful and generating large keys is slow.
+func previewPublicKey(keyType string) (crypto.PublicKey, error) {
+	rsaKey := func(bits int) crypto.PublicKey {
+		n := new(big.Int).Lsh(big.NewInt(1), uint(bits-1))
+		return &rsa.PublicKey{N: n.Or(n, big.NewInt(1)), E: 65537}
+	}
+	ecdsaKey := func(curve elliptic.Curve) (crypto.PublicKey, error) {
+		pKey, err := ecdsa.GenerateKey(curve, rand.Reader)
+		if err != nil {
+			return nil, err
+		}
+		return pKey.Public(), nil
+	}
+
+	switch keyType {
+	case KeyTypeRSA_2048:
+		return rsaKey(2048), nil
+	case KeyTypeRSA_3072:
+		return rsaKey(3072), nil
+	case KeyTypeRSA_4096:
+		return rsaKey(4096), nil
+	case KeyTypeRSA_8192:
+		return rsaKey(8192), nil
+	case KeyTypeECDSA_256:
+		return ecdsaKey(elliptic.P256())
+	case KeyTypeECDSA_384:
+		return ecdsaKey(elliptic.P384())
+	case KeyTypeECDSA_521:
+		return ecdsaKey(elliptic.P521())
+	case KeyTypeEd25519:
+		pub, _, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, err
+		}
+		return pub, nil
+	}
+	return nil, fmt.Errorf("invalid key type")
+}
@@ -0,0 +1,140 @@
+package tls_test
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/tls-inspector/certbox/tls"
+)
+
+func TestPreviewCertificate(t *testing.T) {
+	t.Parallel()
+
+	chain := generateLongCertificateChain(t)
+	issuer := chain[1]
+
+	request := tls.CertificateRequest{
+		KeyType:            tls.KeyTypeRSA_4096,
+		SignatureAlgorithm: tls.SignatureAlgorithmSHA384,
+		Subject:            tls.Name{Organization: "example.com", CommonName: "foo.example.com"},
+		AlternateNames:     []tls.AlternateName{{Type: tls.AlternateNameTypeDNS, Value: "foo.example.com"}},
+		Validity: tls.DateRange{
+			NotBefore: "2001-01-01",
+			NotAfter:  "2002-01-01",
+		},
+		Usage:  tls.KeyUsage{DigitalSignature: true, ServerAuth: true},
+		Serial: tls.SerialNumber{Strategy: tls.SerialStrategyExplicit, Value: "1234"},
+	}
+
+	x, err := tls.PreviewCertificate(request, issuer)
+	if err != nil {
+		t.Fatalf("Error previewing certificate: %s", err.Error())
+	}
+	if x.SerialNumber.String() != "1234" {
+		t.Errorf("Unexpected serial number %s", x.SerialNumber.String())
+	}
+	if x.IsCA {
+		t.Errorf("Preview should not be a certificate authority")
+	}
+	if !bytes.Equal(x.RawIssuer, issuer.X509().RawSubject) {
+		t.Errorf("Unexpected issuer %s", x.Issuer.String())
+	}
+	if !bytes.Equal(x.AuthorityKeyId, issuer.X509().SubjectKeyId) {
+		t.Errorf("Unexpected authority key identifier")
+	}
+	if x.SignatureAlgorithm != x509.ECDSAWithSHA384 {
+		t.Errorf("Unexpected signature algorithm %s", x.SignatureAlgorithm.String())
+	}
+	pub, ok := x.PublicKey.(*rsa.PublicKey)
+	if !ok || pub.N.BitLen() != 4096 {
+		t.Errorf("Unexpected public key %T", x.PublicKey)
+	}
+	if len(x.DNSNames) != 1 || x.DNSNames[0] != "foo.example.com" {
+		t.Errorf("Unexpected dns names %v", x.DNSNames)
+	}
+
+	x, err = tls.PreviewCertificate(request, nil)
+	if err != nil {
+		t.Fatalf("Error previewing certificate: %s", err.Error())
+	}
+	if !x.IsCA || !bytes.Equal(x.RawIssuer, x.RawSubject) {
+		t.Errorf("Self-signed preview should be a self-issued certificate authority")
+	}
+	if x.SignatureAlgorithm != x509.SHA384WithRSA {
+		t.Errorf("Unexpected signature algorithm %s", x.SignatureAlgorithm.String())
+	}
+
+	// The throwaway key must be large enough for every signature algorithm
+	request.SignatureAlgorithm = tls.SignatureAlgorithmSHA512PSS
+	x, err = tls.PreviewCertificate(request, nil)
+	if err != nil {
+		t.Fatalf("Error previewing certificate: %s", err.Error())
+	}
+	if x.SignatureAlgorithm != x509.SHA512WithRSAPSS {
+		t.Errorf("Unexpected signature algorithm %s", x.SignatureAlgorithm.String())
+	}
+
+	request.KeyType = "invalid"
+	if _, err := tls.PreviewCertificate(request, nil); err == nil {
+		t.Errorf("No error seen when one expected for invalid key type")
+	}
+}
+
+func TestPreviewCertificateIssuerWithoutKeyID(t *testing.T) {
+	t.Parallel()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Error generating key: %s", err.Error())
+	}
+	// Only certificate authorities are given a subject key identifier automatically
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "example.com Root"},
+		NotBefore:    time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC),
+		NotAfter:     time.Date(2010, 1, 1, 0, 0, 0, 0, time.UTC),
+		KeyUsage:     x509.KeyUsageCertSign,
+	}
+	data, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	if err != nil {
+		t.Fatalf("Error generating certificate: %s", err.Error())
+	}
+	keyData, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatalf("Error marshalling key: %s", err.Error())
+	}
+	issuer := &tls.Certificate{CertificateData: hex.EncodeToString(data), KeyData: hex.EncodeToString(keyData)}
+	if len(issuer.X509().SubjectKeyId) != 0 {
+		t.Fatalf("Issuer should not have a subject key identifier")
+	}
+
+	request := tls.CertificateRequest{
+		KeyType:            tls.KeyTypeECDSA_256,
+		SignatureAlgorithm: tls.SignatureAlgorithmSHA256,
+		Subject:            tls.Name{CommonName: "foo.example.com"},
+		Validity: tls.DateRange{
+			NotBefore: "2001-01-01",
+			NotAfter:  "2002-01-01",
+		},
+	}
+	certificate, err := tls.GenerateCertificate(request, issuer)
+	if err != nil {
+		t.Fatalf("Error generating certificate: %s", err.Error())
+	}
+	x, err := tls.PreviewCertificate(request, issuer)
+	if err != nil {
+		t.Fatalf("Error previewing certificate: %s", err.Error())
+	}
+	if len(x.AuthorityKeyId) == 0 || !bytes.Equal(x.AuthorityKeyId, certificate.X509().AuthorityKeyId) {
+		t.Errorf("Preview authority key identifier %x does not match generated certificate %x", x.AuthorityKeyId, certificate.X509().AuthorityKeyId)
+	}
+}
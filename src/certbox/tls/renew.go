@@ -0,0 +1,181 @@
+package tls
+
+import (
+	"crypto"
+	"crypto/sha1"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/hex"
+	"fmt"
+	"time"
+)
+
+// Validity modes for renewing a certificate
+const (
+	// RenewValidityShift moves the validity period of the original certificate to start today, keeping its length
+	RenewValidityShift = "shift"
+	// RenewValidityExtend keeps the start of the original validity period and extends its end by its length
+	RenewValidityExtend = "extend"
+)
+
+// RenewOptions describes the options for renewing a certificate
+type RenewOptions struct {
+	// RotateKey will generate a new private key for the renewed certificate instead of reusing the existing key
+	RotateKey bool
+	// KeyType is the type of the new key when RotateKey is set. Defaults to the type of the existing key.
+	KeyType string
+	// Validity, when set, replaces the validity of the original certificate. Otherwise ValidityMode is used.
+	Validity DateRange
+	// ValidityMode is either RenewValidityShift or RenewValidityExtend. Defaults to RenewValidityShift.
+	ValidityMode string
+	// SignatureAlgorithm defaults to the signature algorithm of the original certificate
+	SignatureAlgorithm string
+}
+
+// RenewCertificate will issue a replacement for the given certificate signed by the issuer, or self-signed if issuer
+// is nil. The subject, alternate names, usage and all other extensions of the original certificate are copied exactly,
+// including their criticality. A new serial number is always assigned.
+//
+// When the key is kept and the certificate is self-signed, the original certificate must include its private key.
+// The renewed certificate only includes key data if the key was rotated or the original had key data.
+func RenewCertificate(certificate Certificate, issuer *Certificate, options RenewOptions) (*Certificate, error) {
+	x := certificate.X509()
+
+	var pub crypto.PublicKey
+	var pKey crypto.PrivateKey
+	var err error
+	if options.RotateKey {
+		request := CertificateRequest{KeyType: options.KeyType}
+		if request.KeyType == "" {
+			request.KeyType, err = keyTypeForPublicKey(x.PublicKey)
+			if err != nil {
+				return nil, err
+			}
+		}
+		pKey, err = request.generatePrivateKey(secureEntropy)
+		if err != nil {
+			return nil, err
+		}
+		pub = pKey.(crypto.Signer).Public()
+	} else {
+		if options.KeyType != "" {
+			return nil, fmt.Errorf("key type can only be changed when rotating the key")
+		}
+		pub = x.PublicKey
+		if certificate.KeyData != "" {
+			pKey = certificate.PKey()
+		} else if issuer == nil {
+			return nil, fmt.Errorf("self-signed certificate can not be renewed without its private key")
+		}
+	}
+
+	notBefore, notAfter, err := renewedValidity(x, options)
+	if err != nil {
+		return nil, err
+	}
+
+	serial, err := secureEntropy.randomSerialNumber()
+	if err != nil {
+		return nil, err
+	}
+
+	tpl := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               x.Subject,
+		RawSubject:            x.RawSubject,
+		NotBefore:             notBefore,
+		NotAfter:              notAfter,
+		BasicConstraintsValid: x.BasicConstraintsValid,
+		SubjectKeyId:          x.SubjectKeyId,
+		// The alternate names are encoded by the copied extension, but are needed to check the issuers name constraints
+		DNSNames:       x.DNSNames,
+		EmailAddresses: x.EmailAddresses,
+		IPAddresses:    x.IPAddresses,
+		URIs:           x.URIs,
+	}
+	if options.RotateKey {
+		publicKeyBytes, err := x509.MarshalPKIXPublicKey(pub)
+		if err != nil {
+			return nil, err
+		}
+		subjectKeyId := sha1.Sum(publicKeyBytes)
+		tpl.SubjectKeyId = subjectKeyId[:]
+	}
+
+	authorityKeyId := x.AuthorityKeyId
+	if issuer != nil {
+		authorityKeyId = issuer.X509().SubjectKeyId
+	} else if len(authorityKeyId) > 0 {
+		authorityKeyId = tpl.SubjectKeyId
+	}
+
+	// Extensions in ExtraExtensions take precedence over those that would be produced from the template, so copying
+	// every extension preserves the original order, encoding and criticality. Only the key identifiers are replaced,
+	// as they must describe the new issuer and key. The authorityCertIssuer and authorityCertSerialNumber fields of the
+	// authority key identifier are not kept, as they may not describe the new issuer. If the issuer has no subject key
+	// identifier the original extension is dropped, so the authority key identifier is added the same way as for a
+	// generated certificate.
+	for _, ext := range x.Extensions {
+		switch {
+		case ext.Id.Equal(oidExtensionAuthorityKeyId):
+			if len(authorityKeyId) == 0 {
+				continue
+			}
+			ext.Value, err = asn1.Marshal(authKeyId{Id: authorityKeyId})
+		case ext.Id.Equal(oidExtensionSubjectKeyId):
+			ext.Value, err = asn1.Marshal(tpl.SubjectKeyId)
+		}
+		if err != nil {
+			return nil, err
+		}
+		tpl.ExtraExtensions = append(tpl.ExtraExtensions, ext)
+	}
+
+	signatureAlgorithm := options.SignatureAlgorithm
+	if signatureAlgorithm == "" {
+		signatureAlgorithm = signatureAlgorithmName(x.SignatureAlgorithm)
+	}
+
+	renewed, err := signCertificate(tpl, pub, pKey, signatureAlgorithm, x.IsCA, issuer, secureEntropy)
+	if err != nil {
+		return nil, err
+	}
+	renewed.CertificateAuthority = x.IsCA
+
+	if pKey != nil {
+		pKeyBytes, err := x509.MarshalPKCS8PrivateKey(pKey)
+		if err != nil {
+			return nil, err
+		}
+		renewed.KeyData = hex.EncodeToString(pKeyBytes)
+	}
+
+	return renewed, nil
+}
+
+// authKeyId describes the authority key identifier extension as defined in RFC 5280 section 4.2.1.1
+type authKeyId struct {
+	Id []byte `asn1:"optional,tag:0"`
+}
+
+// renewedValidity returns the validity period for the renewal of the given certificate
+func renewedValidity(x *x509.Certificate, options RenewOptions) (time.Time, time.Time, error) {
+	if options.Validity.NotBefore != "" || options.Validity.NotAfter != "" {
+		if !options.Validity.IsValid() {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid validity")
+		}
+		notBefore, notAfter := options.Validity.mustDates()
+		return notBefore, notAfter, nil
+	}
+
+	length := x.NotAfter.Sub(x.NotBefore)
+	switch options.ValidityMode {
+	case "", RenewValidityShift:
+		notBefore := time.Now().UTC().Truncate(24 * time.Hour)
+		return notBefore, notBefore.Add(length), nil
+	case RenewValidityExtend:
+		return x.NotBefore, x.NotAfter.Add(length), nil
+	default:
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid validity mode '%s'", options.ValidityMode)
+	}
+}
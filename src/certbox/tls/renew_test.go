@@ -0,0 +1,305 @@
+package tls_test
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/tls-inspector/certbox/tls"
+)
+
+func assertRenewedExtensions(t *testing.T, original, renewed *x509.Certificate, rotated bool) {
+	if !bytes.Equal(original.RawSubject, renewed.RawSubject) {
+		t.Errorf("Renewed certificate subject does not match original")
+	}
+	if original.SerialNumber.Cmp(renewed.SerialNumber) == 0 {
+		t.Errorf("Renewed certificate has the same serial number as the original")
+	}
+	if len(original.Extensions) != len(renewed.Extensions) {
+		t.Fatalf("Unexpected number of extensions. Expected %d got %d", len(original.Extensions), len(renewed.Extensions))
+	}
+	for i, ext := range original.Extensions {
+		r := renewed.Extensions[i]
+		if !ext.Id.Equal(r.Id) {
+			t.Errorf("Extension at index %d does not match. Expected %s got %s", i, ext.Id, r.Id)
+			continue
+		}
+		if ext.Id.String() == "2.5.29.35" || (rotated && ext.Id.String() == "2.5.29.14") {
+			continue
+		}
+		if ext.Critical != r.Critical || !bytes.Equal(ext.Value, r.Value) {
+			t.Errorf("Extension %s does not match original", ext.Id)
+		}
+	}
+}
+
+func TestRenewCertificate(t *testing.T) {
+	t.Parallel()
+
+	root, err := tls.GenerateCertificate(tls.CertificateRequest{
+		KeyType:            tls.KeyTypeECDSA_256,
+		SignatureAlgorithm: tls.SignatureAlgorithmSHA256,
+		Subject:            tls.Name{CommonName: "example.com Root"},
+		Validity: tls.DateRange{
+			NotBefore: "2001-01-01",
+			NotAfter:  "2002-01-01",
+		},
+		IsCertificateAuthority: true,
+	}, nil)
+	if err != nil {
+		t.Fatalf("Error generating root certificate: %s", err.Error())
+	}
+	leaf, err := tls.GenerateCertificate(tls.CertificateRequest{
+		KeyType:            tls.KeyTypeECDSA_256,
+		SignatureAlgorithm: tls.SignatureAlgorithmSHA256,
+		Subject:            tls.Name{CommonName: "foo.example.com"},
+		Validity: tls.DateRange{
+			NotBefore: "2001-01-01",
+			NotAfter:  "2001-04-01",
+		},
+		AlternateNames: []tls.AlternateName{
+			{Type: tls.AlternateNameTypeDNS, Value: "foo.example.com"},
+			{Type: tls.AlternateNameTypeIP, Value: "127.0.0.1"},
+		},
+		Usage: tls.KeyUsage{
+			DigitalSignature: true,
+			ServerAuth:       true,
+		},
+		StatusProviders: tls.StatusProviders{
+			OCSP: []string{"http://ocsp.example.com"},
+		},
+		Extensions: []tls.Extension{
+			{OID: stringExtensionId, Value: stringExtensionValue},
+		},
+	}, root)
+	if err != nil {
+		t.Fatalf("Error generating leaf certificate: %s", err.Error())
+	}
+	original := leaf.X509()
+
+	renewed, err := tls.RenewCertificate(*leaf, root, tls.RenewOptions{})
+	if err != nil {
+		t.Fatalf("Error renewing certificate: %s", err.Error())
+	}
+	x := renewed.X509()
+	if err := x.CheckSignatureFrom(root.X509()); err != nil {
+		t.Errorf("Renewed certificate not signed by issuer: %s", err.Error())
+	}
+	if !bytes.Equal(original.RawSubjectPublicKeyInfo, x.RawSubjectPublicKeyInfo) {
+		t.Errorf("Renewed certificate does not use the original key")
+	}
+	if renewed.KeyData != leaf.KeyData {
+		t.Errorf("Renewed certificate does not include the original key")
+	}
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	if !x.NotBefore.Equal(today) {
+		t.Errorf("Unexpected not before date. Expected '%s' got '%s'", today, x.NotBefore)
+	}
+	if x.NotAfter.Sub(x.NotBefore) != original.NotAfter.Sub(original.NotBefore) {
+		t.Errorf("Renewed validity length does not match original")
+	}
+	assertRenewedExtensions(t, original, x, false)
+}
+
+func TestRenewCertificateRotateKey(t *testing.T) {
+	t.Parallel()
+
+	root, err := tls.GenerateCertificate(tls.CertificateRequest{
+		KeyType:            tls.KeyTypeECDSA_256,
+		SignatureAlgorithm: tls.SignatureAlgorithmSHA256,
+		Subject:            tls.Name{CommonName: "example.com Root"},
+		Validity: tls.DateRange{
+			NotBefore: "2001-01-01",
+			NotAfter:  "2002-01-01",
+		},
+		IsCertificateAuthority: true,
+	}, nil)
+	if err != nil {
+		t.Fatalf("Error generating root certificate: %s", err.Error())
+	}
+	leaf, err := tls.GenerateCertificate(tls.CertificateRequest{
+		KeyType:            tls.KeyTypeECDSA_256,
+		SignatureAlgorithm: tls.SignatureAlgorithmSHA256,
+		Subject:            tls.Name{CommonName: "foo.example.com"},
+		Validity: tls.DateRange{
+			NotBefore: "2001-01-01",
+			NotAfter:  "2001-04-01",
+		},
+		AlternateNames: []tls.AlternateName{
+			{Type: tls.AlternateNameTypeDNS, Value: "foo.example.com"},
+			{Type: tls.AlternateNameTypeIP, Value: "127.0.0.1"},
+		},
+		Usage: tls.KeyUsage{
+			DigitalSignature: true,
+			ServerAuth:       true,
+		},
+		StatusProviders: tls.StatusProviders{
+			OCSP: []string{"http://ocsp.example.com"},
+		},
+		Extensions: []tls.Extension{
+			{OID: stringExtensionId, Value: stringExtensionValue},
+		},
+	}, root)
+	if err != nil {
+		t.Fatalf("Error generating leaf certificate: %s", err.Error())
+	}
+	original := leaf.X509()
+
+	renewed, err := tls.RenewCertificate(*leaf, root, tls.RenewOptions{
+		RotateKey:    true,
+		ValidityMode: tls.RenewValidityExtend,
+	})
+	if err != nil {
+		t.Fatalf("Error renewing certificate: %s", err.Error())
+	}
+	x := renewed.X509()
+	if bytes.Equal(original.RawSubjectPublicKeyInfo, x.RawSubjectPublicKeyInfo) {
+		t.Errorf("Renewed certificate uses the original key")
+	}
+	if renewed.KeyData == "" || renewed.KeyData == leaf.KeyData {
+		t.Errorf("Renewed certificate does not include a new key")
+	}
+	if bytes.Equal(original.SubjectKeyId, x.SubjectKeyId) {
+		t.Errorf("Subject key identifier not updated for new key")
+	}
+	if !x.NotBefore.Equal(original.NotBefore) {
+		t.Errorf("Unexpected not before date. Expected '%s' got '%s'", original.NotBefore, x.NotBefore)
+	}
+	expectedNotAfter := original.NotAfter.Add(original.NotAfter.Sub(original.NotBefore))
+	if !x.NotAfter.Equal(expectedNotAfter) {
+		t.Errorf("Unexpected not after date. Expected '%s' got '%s'", expectedNotAfter, x.NotAfter)
+	}
+	assertRenewedExtensions(t, original, x, true)
+}
+
+func TestRenewCertificateSelfSigned(t *testing.T) {
+	t.Parallel()
+
+	root, err := tls.GenerateCertificate(tls.CertificateRequest{
+		KeyType:            tls.KeyTypeECDSA_256,
+		SignatureAlgorithm: tls.SignatureAlgorithmSHA256,
+		Subject:            tls.Name{CommonName: "example.com Root"},
+		Validity: tls.DateRange{
+			NotBefore: "2001-01-01",
+			NotAfter:  "2002-01-01",
+		},
+		IsCertificateAuthority: true,
+	}, nil)
+	if err != nil {
+		t.Fatalf("Error generating root certificate: %s", err.Error())
+	}
+	original := root.X509()
+
+	renewed, err := tls.RenewCertificate(*root, nil, tls.RenewOptions{
+		Validity: tls.DateRange{
+			NotBefore: "2010-01-01",
+			NotAfter:  "2020-01-01",
+		},
+	})
+	if err != nil {
+		t.Fatalf("Error renewing certificate: %s", err.Error())
+	}
+	x := renewed.X509()
+	if err := x.CheckSignatureFrom(x); err != nil {
+		t.Errorf("Renewed certificate not self-signed: %s", err.Error())
+	}
+	if !renewed.CertificateAuthority {
+		t.Errorf("Renewed certificate should be a certificate authority")
+	}
+	if x.NotBefore.Format(time.DateOnly) != "2010-01-01" || x.NotAfter.Format(time.DateOnly) != "2020-01-01" {
+		t.Errorf("Unexpected validity %s - %s", x.NotBefore, x.NotAfter)
+	}
+	assertRenewedExtensions(t, original, x, false)
+
+	withoutKey := *root
+	withoutKey.KeyData = ""
+	if _, err := tls.RenewCertificate(withoutKey, nil, tls.RenewOptions{}); err == nil {
+		t.Errorf("No error seen when one expected for self-signed certificate without key")
+	}
+	if _, err := tls.RenewCertificate(*root, nil, tls.RenewOptions{ValidityMode: "bogus"}); err == nil {
+		t.Errorf("No error seen when one expected for invalid validity mode")
+	}
+}
+
+func TestRenewCertificateIssuerWithoutKeyID(t *testing.T) {
+	t.Parallel()
+
+	root, err := tls.GenerateCertificate(tls.CertificateRequest{
+		KeyType:            tls.KeyTypeECDSA_256,
+		SignatureAlgorithm: tls.SignatureAlgorithmSHA256,
+		Subject:            tls.Name{CommonName: "example.com Root"},
+		Validity: tls.DateRange{
+			NotBefore: "2001-01-01",
+			NotAfter:  "2002-01-01",
+		},
+		IsCertificateAuthority: true,
+	}, nil)
+	if err != nil {
+		t.Fatalf("Error generating root certificate: %s", err.Error())
+	}
+	leaf, err := tls.GenerateCertificate(tls.CertificateRequest{
+		KeyType:            tls.KeyTypeECDSA_256,
+		SignatureAlgorithm: tls.SignatureAlgorithmSHA256,
+		Subject:            tls.Name{CommonName: "foo.example.com"},
+		Validity: tls.DateRange{
+			NotBefore: "2001-01-01",
+			NotAfter:  "2001-04-01",
+		},
+	}, root)
+	if err != nil {
+		t.Fatalf("Error generating leaf certificate: %s", err.Error())
+	}
+	if len(leaf.X509().AuthorityKeyId) == 0 {
+		t.Fatalf("Leaf should have an authority key identifier")
+	}
+
+	// Only certificate authorities are given a subject key identifier automatically
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Error generating key: %s", err.Error())
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "example.com Other Root"},
+		NotBefore:    time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC),
+		NotAfter:     time.Date(2010, 1, 1, 0, 0, 0, 0, time.UTC),
+		KeyUsage:     x509.KeyUsageCertSign,
+	}
+	data, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	if err != nil {
+		t.Fatalf("Error generating certificate: %s", err.Error())
+	}
+	keyData, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatalf("Error marshalling key: %s", err.Error())
+	}
+	issuer := &tls.Certificate{CertificateData: hex.EncodeToString(data), KeyData: hex.EncodeToString(keyData)}
+
+	renewed, err := tls.RenewCertificate(*leaf, issuer, tls.RenewOptions{})
+	if err != nil {
+		t.Fatalf("Error renewing certificate: %s", err.Error())
+	}
+	generated, err := tls.GenerateCertificate(tls.CertificateRequest{
+		KeyType:            tls.KeyTypeECDSA_256,
+		SignatureAlgorithm: tls.SignatureAlgorithmSHA256,
+		Subject:            tls.Name{CommonName: "foo.example.com"},
+		Validity: tls.DateRange{
+			NotBefore: "2001-01-01",
+			NotAfter:  "2001-04-01",
+		},
+	}, issuer)
+	if err != nil {
+		t.Fatalf("Error generating leaf certificate: %s", err.Error())
+	}
+	x := renewed.X509()
+	if len(x.AuthorityKeyId) == 0 || !bytes.Equal(x.AuthorityKeyId, generated.X509().AuthorityKeyId) {
+		t.Errorf("Renewed authority key identifier %x does not match generated certificate %x", x.AuthorityKeyId, generated.X509().AuthorityKeyId)
+	}
+}
@@ -0,0 +1,103 @@
+package tls
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+)
+
+// Serial number strategies
+const (
+	// SerialStrategyRandom assigns a random 128-bit serial number
+	SerialStrategyRandom = "random"
+	// SerialStrategyExplicit assigns the serial number in Value
+	SerialStrategyExplicit = "explicit"
+	// SerialStrategySequential assigns consecutive serial numbers to the certificates signed by each issuer within a
+	// batch, starting at Value or 1. A single certificate is assigned Value.
+	SerialStrategySequential = "sequential"
+	// SerialStrategyPrefixed assigns the prefix in Value followed by RandomBits random bits
+	SerialStrategyPrefixed = "prefixed"
+)
+
+// maxSerialBits is the largest serial number permitted by RFC 5280, which is 20 octets including the sign bit
+const maxSerialBits = 159
+
+const defaultSerialRandomBits = 64
+
+// SerialNumber describes how the serial number of a certificate is assigned
+type SerialNumber struct {
+	// Strategy defaults to SerialStrategyRandom
+	Strategy string
+	// Value is a decimal number, or hexadecimal if prefixed with 0x
+	Value string
+	// RandomBits is the number of random bits following the prefix of SerialStrategyPrefixed. Defaults to 64. When
+	// RandomBits is a multiple of 4 the hexadecimal serial number begins with the prefix.
+	RandomBits int
+}
+
+// serialNumber returns the serial number for a certificate using this strategy
+func (s SerialNumber) serialNumber(e *entropy) (*big.Int, error) {
+	var serial *big.Int
+	var err error
+
+	switch s.Strategy {
+	case "", SerialStrategyRandom:
+		return e.randomSerialNumber()
+	case SerialStrategySequential:
+		if s.Value == "" {
+			return big.NewInt(1), nil
+		}
+		serial, err = ParseSerial(s.Value)
+		if err != nil {
+			return nil, err
+		}
+	case SerialStrategyExplicit:
+		serial, err = ParseSerial(s.Value)
+		if err != nil {
+			return nil, err
+		}
+	case SerialStrategyPrefixed:
+		prefix, err := ParseSerial(s.Value)
+		if err != nil {
+			return nil, err
+		}
+		bits := s.RandomBits
+		if bits == 0 {
+			bits = defaultSerialRandomBits
+		}
+		if bits < 0 {
+			return nil, fmt.Errorf("invalid serial random bits %d", s.RandomBits)
+		}
+		suffix, err := rand.Int(e.reader, new(big.Int).Lsh(big.NewInt(1), uint(bits)))
+		if err != nil {
+			return nil, err
+		}
+		serial = new(big.Int).Lsh(prefix, uint(bits))
+		serial.Or(serial, suffix)
+	default:
+		return nil, fmt.Errorf("unknown serial strategy '%s'", s.Strategy)
+	}
+
+	if serial.Sign() <= 0 {
+		return nil, fmt.Errorf("serial number must be positive")
+	}
+	if serial.BitLen() > maxSerialBits {
+		return nil, fmt.Errorf("serial number is longer than 20 octets")
+	}
+	return serial, nil
+}
+
+// FormatSerialHex returns the given serial number in hexadecimal, with an even number of digits
+func FormatSerialHex(serial *big.Int) string {
+	value := serial.Text(16)
+	if serial.Sign() >= 0 && len(value)%2 == 1 {
+		value = "0" + value
+	}
+	return value
+}
+
+// setSerial sets the decimal and hexadecimal serial number of this certificate
+func (c *Certificate) setSerial(serial *big.Int) {
+	c.Serial = serial.String()
+	c.SerialHex = FormatSerialHex(serial)
+}
@@ -0,0 +1,88 @@
+package tls_test
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/tls-inspector/certbox/tls"
+)
+
+func serialRequest(serial tls.SerialNumber) tls.CertificateRequest {
+	return tls.CertificateRequest{
+		KeyType:            tls.KeyTypeECDSA_256,
+		SignatureAlgorithm: tls.SignatureAlgorithmSHA256,
+		Subject:            tls.Name{Organization: "example.com", CommonName: "example.com"},
+		Validity:           tls.DateRange{NotBefore: "2001-01-01", NotAfter: "2002-01-01"},
+		Usage:              tls.KeyUsage{DigitalSignature: true},
+		Serial:             serial,
+	}
+}
+
+func TestSerialNumberExplicit(t *testing.T) {
+	t.Parallel()
+
+	certificate, err := tls.GenerateCertificate(serialRequest(tls.SerialNumber{Strategy: tls.SerialStrategyExplicit, Value: "0x0a1b2c"}), nil)
+	if err != nil {
+		t.Fatalf("Error generating certificate: %s", err.Error())
+	}
+	if certificate.X509().SerialNumber.Int64() != 0x0a1b2c {
+		t.Errorf("Unexpected serial number %s", certificate.X509().SerialNumber)
+	}
+	if certificate.Serial != "662316" {
+		t.Errorf("Unexpected decimal serial number '%s'", certificate.Serial)
+	}
+	if certificate.SerialHex != "0a1b2c" {
+		t.Errorf("Unexpected hexadecimal serial number '%s'", certificate.SerialHex)
+	}
+}
+
+func TestSerialNumberPrefixed(t *testing.T) {
+	t.Parallel()
+
+	certificate, err := tls.GenerateCertificate(serialRequest(tls.SerialNumber{Strategy: tls.SerialStrategyPrefixed, Value: "0x5eed", RandomBits: 32}), nil)
+	if err != nil {
+		t.Fatalf("Error generating certificate: %s", err.Error())
+	}
+	prefix := new(big.Int).Rsh(certificate.X509().SerialNumber, 32)
+	if prefix.Int64() != 0x5eed {
+		t.Errorf("Unexpected serial number prefix %x", prefix)
+	}
+	if !strings.HasPrefix(certificate.SerialHex, "5eed") {
+		t.Errorf("Unexpected hexadecimal serial number '%s'", certificate.SerialHex)
+	}
+}
+
+func TestSerialNumberRandom(t *testing.T) {
+	t.Parallel()
+
+	certificate, err := tls.GenerateCertificate(serialRequest(tls.SerialNumber{}), nil)
+	if err != nil {
+		t.Fatalf("Error generating certificate: %s", err.Error())
+	}
+	if certificate.Serial != certificate.X509().SerialNumber.String() {
+		t.Errorf("Decimal serial number does not match certificate")
+	}
+	if len(certificate.SerialHex)%2 != 0 || certificate.SerialHex != tls.FormatSerialHex(certificate.X509().SerialNumber) {
+		t.Errorf("Hexadecimal serial number does not match certificate")
+	}
+}
+
+func TestSerialNumberInvalid(t *testing.T) {
+	t.Parallel()
+
+	invalid := map[string]tls.SerialNumber{
+		"zero":            {Strategy: tls.SerialStrategyExplicit, Value: "0"},
+		"negative":        {Strategy: tls.SerialStrategyExplicit, Value: "-1"},
+		"missing value":   {Strategy: tls.SerialStrategyExplicit},
+		"too long":        {Strategy: tls.SerialStrategyExplicit, Value: "0x" + strings.Repeat("ff", 20)},
+		"prefix too long": {Strategy: tls.SerialStrategyPrefixed, Value: "0x" + strings.Repeat("ff", 16), RandomBits: 64},
+		"negative bits":   {Strategy: tls.SerialStrategyPrefixed, Value: "1", RandomBits: -1},
+		"unknown":         {Strategy: "ascending", Value: "1"},
+	}
+	for name, serial := range invalid {
+		if _, err := tls.GenerateCertificate(serialRequest(serial), nil); err == nil {
+			t.Errorf("No error seen when one expected for %s serial number", name)
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package tls
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"fmt"
+	"strings"
+)
+
+// SignCSR will issue a certificate for the given PEM encoded certificate signing request, signed by the issuer.
+//
+// The subject, alternate names, key usage and any other requested extensions are copied from the CSR. The following
+// properties of overrides are applied as policy:
+//
+//   - Validity and SignatureAlgorithm are always taken from overrides.
+//   - Usage, when any usage is set, replaces the key usage requested by the CSR.
+//   - AlternateNames, when not empty, is used as an allow-list. Only requested alternate names that match the type
+//     and value of an entry are kept; all others are removed.
+//   - IsCertificateAuthority, StatusProviders and Extensions are applied as with GenerateCertificate.
+//
+// The returned certificate does not include any key data.
+func SignCSR(csrPEM []byte, issuer *Certificate, overrides CertificateRequest) (*Certificate, error) {
+	if issuer == nil || issuer.KeyData == "" {
+		return nil, fmt.Errorf("issuer with private key required")
+	}
+
+	block, _ := pem.Decode(csrPEM)
+	if block == nil {
+		return nil, fmt.Errorf("csr is not valid PEM")
+	}
+	csr, err := x509.ParseCertificateRequest(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid csr: %s", err.Error())
+	}
+	if err := csr.CheckSignature(); err != nil {
+		return nil, fmt.Errorf("invalid csr signature: %s", err.Error())
+	}
+
+	if !overrides.Validity.IsValid() {
+		return nil, fmt.Errorf("invalid validity")
+	}
+
+	request := overrides
+	request.AlternateNames = nil
+	if request.Usage.isEmpty() {
+		request.Usage, err = requestedUsage(csr.Extensions)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	tpl, err := request.template(csr.PublicKey, secureEntropy)
+	if err != nil {
+		return nil, err
+	}
+	tpl.Subject = csr.Subject
+	tpl.RawSubject = csr.RawSubject
+
+	allowed := func(nameType, value string) bool {
+		if len(overrides.AlternateNames) == 0 {
+			return true
+		}
+		for _, name := range overrides.AlternateNames {
+			if name.Type == nameType && strings.EqualFold(name.Value, value) {
+				return true
+			}
+		}
+		return false
+	}
+	for _, dns := range csr.DNSNames {
+		if allowed(AlternateNameTypeDNS, dns) {
+			tpl.DNSNames = append(tpl.DNSNames, dns)
+		}
+	}
+	for _, email := range csr.EmailAddresses {
+		if allowed(AlternateNameTypeEmail, email) {
+			tpl.EmailAddresses = append(tpl.EmailAddresses, email)
+		}
+	}
+	for _, ip := range csr.IPAddresses {
+		if allowed(AlternateNameTypeIP, ip.String()) {
+			tpl.IPAddresses = append(tpl.IPAddresses, ip)
+		}
+	}
+	for _, uri := range csr.URIs {
+		if allowed(AlternateNameTypeURI, uri.String()) {
+			tpl.URIs = append(tpl.URIs, uri)
+		}
+	}
+
+	for _, ext := range csr.Extensions {
+		if isKnownExtensionOid(ext) || hasExtension(tpl.ExtraExtensions, ext.Id) {
+			continue
+		}
+		tpl.ExtraExtensions = append(tpl.ExtraExtensions, ext)
+	}
+
+	return signCertificate(tpl, csr.PublicKey, nil, request.SignatureAlgorithm, request.IsCertificateAuthority, issuer, secureEntropy)
+}
+
+// requestedUsage returns the key usage and extended key usage requested by the given CSR extensions
+func requestedUsage(extensions []pkix.Extension) (KeyUsage, error) {
+	var usage x509.KeyUsage
+	extUsage := []x509.ExtKeyUsage{}
+	customEKUs := []string{}
+
+	for _, ext := range extensions {
+		switch {
+		case ext.Id.Equal(oidExtensionKeyUsage):
+			var bits asn1.BitString
+			if _, err := asn1.Unmarshal(ext.Value, &bits); err != nil {
+				return KeyUsage{}, fmt.Errorf("invalid requested key usage: %s", err.Error())
+			}
+			for i := 0; i < 9; i++ {
+				if bits.At(i) != 0 {
+					usage |= 1 << uint(i)
+				}
+			}
+		case ext.Id.Equal(oidExtensionExtendedKeyUsage):
+			var oids []asn1.ObjectIdentifier
+			if _, err := asn1.Unmarshal(ext.Value, &oids); err != nil {
+				return KeyUsage{}, fmt.Errorf("invalid requested extended key usage: %s", err.Error())
+			}
+			for _, oid := range oids {
+				if eku, known := extKeyUsageFromOid(oid); known {
+					extUsage = append(extUsage, eku)
+				} else {
+					customEKUs = append(customEKUs, oid.String())
+				}
+			}
+		}
+	}
+
+	u := x509KeyUsageToInternal(usage, extUsage)
+	if len(customEKUs) > 0 {
+		u.CustomEKUs = customEKUs
+	}
+	return u, nil
+}
+
+var extKeyUsageOids = map[string]x509.ExtKeyUsage{
+	"1.3.6.1.5.5.7.3.1": x509.ExtKeyUsageServerAuth,
+	"1.3.6.1.5.5.7.3.2": x509.ExtKeyUsageClientAuth,
+	"1.3.6.1.5.5.7.3.3": x509.ExtKeyUsageCodeSigning,
+	"1.3.6.1.5.5.7.3.4": x509.ExtKeyUsageEmailProtection,
+	"1.3.6.1.5.5.7.3.8": x509.ExtKeyUsageTimeStamping,
+	"1.3.6.1.5.5.7.3.9": x509.ExtKeyUsageOCSPSigning,
+}
+
+func extKeyUsageFromOid(oid asn1.ObjectIdentifier) (x509.ExtKeyUsage, bool) {
+	eku, known := extKeyUsageOids[oid.String()]
+	return eku, known
+}
+
+func hasExtension(extensions []pkix.Extension, oid asn1.ObjectIdentifier) bool {
+	for _, ext := range extensions {
+		if ext.Id.Equal(oid) {
+			return true
+		}
+	}
+	return false
+}
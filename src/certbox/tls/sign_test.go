@@ -0,0 +1,230 @@
+package tls_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"testing"
+
+	"github.com/tls-inspector/certbox/tls"
+)
+
+func TestSignCSR(t *testing.T) {
+	t.Parallel()
+
+	root, err := tls.GenerateCertificate(tls.CertificateRequest{
+		KeyType:            tls.KeyTypeECDSA_384,
+		SignatureAlgorithm: tls.SignatureAlgorithmSHA384,
+		Subject:            tls.Name{CommonName: "example.com Root"},
+		Validity: tls.DateRange{
+			NotBefore: "2001-01-01",
+			NotAfter:  "2002-01-01",
+		},
+		IsCertificateAuthority: true,
+	}, nil)
+	if err != nil {
+		t.Fatalf("Error generating root certificate: %s", err.Error())
+	}
+
+	csrPEM, _, err := tls.ExportCSR(&tls.CertificateRequest{
+		KeyType:            tls.KeyTypeECDSA_256,
+		SignatureAlgorithm: tls.SignatureAlgorithmSHA256,
+		Subject: tls.Name{
+			Organization: "example.com",
+			Country:      "CA",
+			CommonName:   "foo.example.com",
+		},
+		AlternateNames: []tls.AlternateName{
+			{Type: tls.AlternateNameTypeDNS, Value: "foo.example.com"},
+			{Type: tls.AlternateNameTypeDNS, Value: "evil.example.net"},
+		},
+		Validity: tls.DateRange{
+			NotBefore: "2001-01-01",
+			NotAfter:  "2002-01-01",
+		},
+		Extensions: []tls.Extension{
+			{OID: stringExtensionId, Value: stringExtensionValue},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Error generating CSR: %s", err.Error())
+	}
+
+	certificate, err := tls.SignCSR(csrPEM, root, tls.CertificateRequest{
+		SignatureAlgorithm: tls.SignatureAlgorithmSHA384,
+		Validity: tls.DateRange{
+			NotBefore: "2001-02-01",
+			NotAfter:  "2001-03-01",
+		},
+		Usage: tls.KeyUsage{
+			DigitalSignature: true,
+			ServerAuth:       true,
+		},
+		AlternateNames: []tls.AlternateName{
+			{Type: tls.AlternateNameTypeDNS, Value: "FOO.example.com"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Error signing CSR: %s", err.Error())
+	}
+
+	if certificate.KeyData != "" {
+		t.Errorf("Signed certificate should not have key data")
+	}
+
+	x := certificate.X509()
+	if err := x.CheckSignatureFrom(root.X509()); err != nil {
+		t.Errorf("Certificate not signed by issuer: %s", err.Error())
+	}
+	if x.Subject.CommonName != "foo.example.com" {
+		t.Errorf("Unexpected subject common name '%s'", x.Subject.CommonName)
+	}
+	if len(x.DNSNames) != 1 || x.DNSNames[0] != "foo.example.com" {
+		t.Errorf("Unexpected DNS names %v", x.DNSNames)
+	}
+	if x.NotBefore.Format("2006-01-02") != "2001-02-01" {
+		t.Errorf("Unexpected not before date %s", x.NotBefore)
+	}
+	if x.KeyUsage != x509.KeyUsageDigitalSignature {
+		t.Errorf("Unexpected key usage %d", x.KeyUsage)
+	}
+	if len(x.ExtKeyUsage) != 1 || x.ExtKeyUsage[0] != x509.ExtKeyUsageServerAuth {
+		t.Errorf("Unexpected extended key usage %v", x.ExtKeyUsage)
+	}
+
+	foundStringExtension := false
+	for _, extension := range x.Extensions {
+		if extension.Id.String() != stringExtensionId {
+			continue
+		}
+		var value string
+		if _, err := asn1.Unmarshal(extension.Value, &value); err != nil {
+			t.Fatalf("Error decoding string value: %s", err.Error())
+		}
+		foundStringExtension = value == stringExtensionValue
+	}
+	if !foundStringExtension {
+		t.Errorf("Requested extension not copied from CSR")
+	}
+
+	certPEM, keyPEM, err := tls.ExportPEM(certificate)
+	if err != nil {
+		t.Fatalf("Error exporting signed certificate: %s", err.Error())
+	}
+	if len(certPEM) == 0 || keyPEM != nil {
+		t.Errorf("Unexpected PEM export for certificate without key")
+	}
+}
+
+func TestSignCSRInvalidSignature(t *testing.T) {
+	t.Parallel()
+
+	root, err := tls.GenerateCertificate(tls.CertificateRequest{
+		KeyType:            tls.KeyTypeECDSA_384,
+		SignatureAlgorithm: tls.SignatureAlgorithmSHA384,
+		Subject:            tls.Name{CommonName: "example.com Root"},
+		Validity: tls.DateRange{
+			NotBefore: "2001-01-01",
+			NotAfter:  "2002-01-01",
+		},
+		IsCertificateAuthority: true,
+	}, nil)
+	if err != nil {
+		t.Fatalf("Error generating root certificate: %s", err.Error())
+	}
+
+	csrDER, _, err := tls.GenerateCSR(tls.CertificateRequest{
+		KeyType:            tls.KeyTypeECDSA_256,
+		SignatureAlgorithm: tls.SignatureAlgorithmSHA256,
+		Subject:            tls.Name{CommonName: "foo.example.com"},
+		Validity: tls.DateRange{
+			NotBefore: "2001-01-01",
+			NotAfter:  "2002-01-01",
+		},
+	})
+	if err != nil {
+		t.Fatalf("Error generating CSR: %s", err.Error())
+	}
+
+	// Flip a bit in the signature
+	csrDER[len(csrDER)-1] ^= 0xFF
+	csrPEM, _, err := tls.ConvertDERtoPEM(csrDER, nil)
+	if err != nil {
+		t.Fatalf("Error encoding CSR: %s", err.Error())
+	}
+
+	if _, err := tls.SignCSR(csrPEM, root, tls.CertificateRequest{
+		SignatureAlgorithm: tls.SignatureAlgorithmSHA256,
+		Validity: tls.DateRange{
+			NotBefore: "2001-01-01",
+			NotAfter:  "2002-01-01",
+		},
+	}); err == nil {
+		t.Errorf("No error seen when one expected for invalid CSR signature")
+	}
+}
+
+func TestSignCSRRequestedUsage(t *testing.T) {
+	t.Parallel()
+
+	root, err := tls.GenerateCertificate(tls.CertificateRequest{
+		KeyType:            tls.KeyTypeECDSA_384,
+		SignatureAlgorithm: tls.SignatureAlgorithmSHA384,
+		Subject:            tls.Name{CommonName: "example.com Root"},
+		Validity: tls.DateRange{
+			NotBefore: "2001-01-01",
+			NotAfter:  "2002-01-01",
+		},
+		IsCertificateAuthority: true,
+	}, nil)
+	if err != nil {
+		t.Fatalf("Error generating root certificate: %s", err.Error())
+	}
+
+	// CSRs generated by certbox don't request any usage, so create one by hand
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Error generating key: %s", err.Error())
+	}
+	keyUsage, _ := asn1.Marshal(asn1.BitString{Bytes: []byte{0x80}, BitLength: 1})
+	extKeyUsage, _ := asn1.Marshal([]asn1.ObjectIdentifier{{1, 3, 6, 1, 5, 5, 7, 3, 2}, {1, 2, 3, 4}})
+	csrDER, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+		Subject: pkix.Name{CommonName: "client"},
+		ExtraExtensions: []pkix.Extension{
+			{Id: asn1.ObjectIdentifier{2, 5, 29, 15}, Critical: true, Value: keyUsage},
+			{Id: asn1.ObjectIdentifier{2, 5, 29, 37}, Value: extKeyUsage},
+		},
+	}, key)
+	if err != nil {
+		t.Fatalf("Error generating CSR: %s", err.Error())
+	}
+	csrPEM, _, err := tls.ConvertDERtoPEM(csrDER, nil)
+	if err != nil {
+		t.Fatalf("Error encoding CSR: %s", err.Error())
+	}
+
+	certificate, err := tls.SignCSR(csrPEM, root, tls.CertificateRequest{
+		SignatureAlgorithm: tls.SignatureAlgorithmSHA256,
+		Validity: tls.DateRange{
+			NotBefore: "2001-01-01",
+			NotAfter:  "2002-01-01",
+		},
+	})
+	if err != nil {
+		t.Fatalf("Error signing CSR: %s", err.Error())
+	}
+
+	x := certificate.X509()
+	if x.KeyUsage != x509.KeyUsageDigitalSignature {
+		t.Errorf("Unexpected key usage %d", x.KeyUsage)
+	}
+	if len(x.ExtKeyUsage) != 1 || x.ExtKeyUsage[0] != x509.ExtKeyUsageClientAuth {
+		t.Errorf("Unexpected extended key usage %v", x.ExtKeyUsage)
+	}
+	if len(x.UnknownExtKeyUsage) != 1 || x.UnknownExtKeyUsage[0].String() != "1.2.3.4" {
+		t.Errorf("Unexpected custom extended key usage %v", x.UnknownExtKeyUsage)
+	}
+}
@@ -0,0 +1,592 @@
+package tls
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var signatureAlgorithmTextNames = map[string]string{
+	"1.2.840.113549.1.1.4":  "md5WithRSAEncryption",
+	"1.2.840.113549.1.1.5":  "sha1WithRSAEncryption",
+	"1.2.840.113549.1.1.10": "rsassaPss",
+	"1.2.840.113549.1.1.11": "sha256WithRSAEncryption",
+	"1.2.840.113549.1.1.12": "sha384WithRSAEncryption",
+	"1.2.840.113549.1.1.13": "sha512WithRSAEncryption",
+	"1.2.840.10045.4.1":     "ecdsa-with-SHA1",
+	"1.2.840.10045.4.3.2":   "ecdsa-with-SHA256",
+	"1.2.840.10045.4.3.3":   "ecdsa-with-SHA384",
+	"1.2.840.10045.4.3.4":   "ecdsa-with-SHA512",
+	"1.3.101.112":           "ED25519",
+}
+
+var publicKeyAlgorithmTextNames = map[string]string{
+	"1.2.840.113549.1.1.1": "rsaEncryption",
+	"1.2.840.10045.2.1":    "id-ecPublicKey",
+	"1.3.101.112":          "ED25519",
+}
+
+var curveTextNames = map[string]string{
+	"P-224": "secp224r1",
+	"P-256": "prime256v1",
+	"P-384": "secp384r1",
+	"P-521": "secp521r1",
+}
+
+var revocationReasonTextNames = map[int]string{
+	RevocationReasonUnspecified:          "Unspecified",
+	RevocationReasonKeyCompromise:        "Key Compromise",
+	RevocationReasonCACompromise:         "CA Compromise",
+	RevocationReasonAffiliationChanged:   "Affiliation Changed",
+	RevocationReasonSuperseded:           "Superseded",
+	RevocationReasonCessationOfOperation: "Cessation Of Operation",
+	RevocationReasonCertificateHold:      "Certificate Hold",
+	RevocationReasonRemoveFromCRL:        "Remove From CRL",
+	RevocationReasonPrivilegeWithdrawn:   "Privilege Withdrawn",
+	RevocationReasonAACompromise:         "AA Compromise",
+}
+
+// Text returns a human readable description of every certificate, certificate request and certificate revocation
+// list in the given PEM or DER encoded data, laid out like the text output of OpenSSL. Other PEM blocks, such as
+// private keys, are ignored.
+func Text(data []byte) (string, error) {
+	if block, _ := pem.Decode(data); block == nil {
+		return derText(data)
+	}
+
+	text := strings.Builder{}
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+
+		var blockText string
+		var err error
+		switch block.Type {
+		case "CERTIFICATE":
+			blockText, err = CertificateText(Certificate{CertificateData: hex.EncodeToString(block.Bytes)})
+		case "CERTIFICATE REQUEST", "NEW CERTIFICATE REQUEST":
+			blockText, err = certificateRequestText(block.Bytes)
+		case "X509 CRL":
+			blockText, err = crlText(block.Bytes)
+		default:
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		text.WriteString(blockText)
+	}
+	if text.Len() == 0 {
+		return "", fmt.Errorf("no certificates, certificate requests or revocation lists found")
+	}
+	return text.String(), nil
+}
+
+// derText returns the text of a DER encoded certificate, certificate request or certificate revocation list
+func derText(data []byte) (string, error) {
+	if _, err := x509.ParseCertificate(data); err == nil {
+		return CertificateText(Certificate{CertificateData: hex.EncodeToString(data)})
+	}
+	if _, err := x509.ParseCertificateRequest(data); err == nil {
+		return certificateRequestText(data)
+	}
+	if _, err := x509.ParseRevocationList(data); err == nil {
+		return crlText(data)
+	}
+	return "", fmt.Errorf("data is not a certificate, certificate request or revocation list")
+}
+
+// CertificateText returns a human readable description of the given certificate, laid out like the output of
+// openssl x509 -text
+func CertificateText(certificate Certificate) (string, error) {
+	details, err := InspectCertificate(certificate)
+	if err != nil {
+		return "", err
+	}
+	serial, _ := new(big.Int).SetString(details.Serial, 10)
+
+	w := &textWriter{}
+	w.line(0, "Certificate:")
+	w.line(4, "Data:")
+	w.line(8, "Version: %d (0x%x)", details.Version, details.Version-1)
+	w.serial(8, "Serial Number:", serial)
+	w.line(8, "Signature Algorithm: %s", signatureAlgorithmTextName(details.SignatureAlgorithmOID))
+	w.line(8, "Issuer: %s", nameText(details.Issuer))
+	w.line(8, "Validity")
+	w.line(12, "Not Before: %s", dateText(details.NotBefore))
+	w.line(12, "Not After : %s", dateText(details.NotAfter))
+	w.line(8, "Subject: %s", nameText(details.Subject))
+	w.publicKey(8, details.PublicKey)
+	if len(details.Extensions) > 0 {
+		w.line(8, "X509v3 extensions:")
+		w.extensions(12, details.Extensions)
+	}
+	w.signature(details.SignatureAlgorithmOID, details.Signature)
+	return w.String(), nil
+}
+
+// certificateRequestText returns a human readable description of the given DER encoded certificate request, laid out
+// like the output of openssl req -text
+func certificateRequestText(data []byte) (string, error) {
+	csr, err := x509.ParseCertificateRequest(data)
+	if err != nil {
+		return "", fmt.Errorf("invalid csr: %s", err.Error())
+	}
+	publicKey, err := publicKeyDetails(csr.RawSubjectPublicKeyInfo, csr.PublicKeyAlgorithm, csr.PublicKey)
+	if err != nil {
+		return "", err
+	}
+	signatureAlgorithm := signatureAlgorithmOID(csr.Raw)
+
+	w := &textWriter{}
+	w.line(0, "Certificate Request:")
+	w.line(4, "Data:")
+	w.line(8, "Version: %d (0x%x)", csr.Version+1, csr.Version)
+	w.line(8, "Subject: %s", nameText(nameDetailsFromRaw(csr.RawSubject)))
+	w.publicKey(8, publicKey)
+	w.line(8, "Attributes:")
+	if len(csr.Extensions) == 0 {
+		w.line(12, "(none)")
+	} else {
+		w.line(12, "Requested Extensions:")
+		w.extensions(16, extensionsDetails(csr.Extensions))
+	}
+	w.signature(signatureAlgorithm, hex.EncodeToString(csr.Signature))
+	return w.String(), nil
+}
+
+// crlText returns a human readable description of the given DER encoded certificate revocation list, laid out like the
+// output of openssl crl -text
+func crlText(data []byte) (string, error) {
+	crl, err := x509.ParseRevocationList(data)
+	if err != nil {
+		return "", fmt.Errorf("invalid crl: %s", err.Error())
+	}
+
+	// The version is optional and only present in v2 CRLs
+	version := 1
+	var tbs asn1.RawValue
+	if _, err := asn1.Unmarshal(crl.RawTBSRevocationList, &tbs); err == nil {
+		var first asn1.RawValue
+		if _, err := asn1.Unmarshal(tbs.Bytes, &first); err == nil && first.Tag == asn1.TagInteger {
+			version = 2
+		}
+	}
+	signatureAlgorithm := signatureAlgorithmOID(crl.Raw)
+
+	w := &textWriter{}
+	w.line(0, "Certificate Revocation List (CRL):")
+	w.line(8, "Version %d (0x%x)", version, version-1)
+	w.line(8, "Signature Algorithm: %s", signatureAlgorithmTextName(signatureAlgorithm))
+	w.line(8, "Issuer: %s", nameText(nameDetailsFromRaw(crl.RawIssuer)))
+	w.line(8, "Last Update: %s", dateText(crl.ThisUpdate.UTC().Format(time.RFC3339)))
+	if crl.NextUpdate.IsZero() {
+		w.line(8, "Next Update: NONE")
+	} else {
+		w.line(8, "Next Update: %s", dateText(crl.NextUpdate.UTC().Format(time.RFC3339)))
+	}
+	if len(crl.Extensions) > 0 {
+		w.line(8, "CRL extensions:")
+		w.extensions(12, extensionsDetails(crl.Extensions))
+	}
+
+	if len(crl.RevokedCertificateEntries) == 0 {
+		w.line(0, "No Revoked Certificates.")
+	} else {
+		w.line(0, "Revoked Certificates:")
+	}
+	for _, entry := range crl.RevokedCertificateEntries {
+		w.line(4, "Serial Number: %s", strings.ToUpper(FormatSerialHex(entry.SerialNumber)))
+		w.line(8, "Revocation Date: %s", dateText(entry.RevocationTime.UTC().Format(time.RFC3339)))
+		if len(entry.Extensions) > 0 {
+			w.line(8, "CRL entry extensions:")
+			w.extensions(12, extensionsDetails(entry.Extensions))
+		}
+	}
+	w.signature(signatureAlgorithm, hex.EncodeToString(crl.Signature))
+	return w.String(), nil
+}
+
+// extensionsDetails returns the details of extensions that don't belong to a certificate
+func extensionsDetails(extensions []pkix.Extension) []ExtensionDetails {
+	details := []ExtensionDetails{}
+	for _, ext := range extensions {
+		details = append(details, extensionDetails(nil, ext))
+	}
+	return details
+}
+
+// signatureAlgorithmOID returns the signature algorithm of the given DER encoded certificate, certificate request or
+// certificate revocation list
+func signatureAlgorithmOID(data []byte) string {
+	var signed struct {
+		TBS                asn1.RawValue
+		SignatureAlgorithm pkix.AlgorithmIdentifier
+		Signature          asn1.BitString
+	}
+	if _, err := asn1.Unmarshal(data, &signed); err != nil {
+		return ""
+	}
+	return signed.SignatureAlgorithm.Algorithm.String()
+}
+
+func signatureAlgorithmTextName(oid string) string {
+	if name, known := signatureAlgorithmTextNames[oid]; known {
+		return name
+	}
+	return oid
+}
+
+// nameText returns the given name in the order it is encoded, such as C = US, O = Example, CN = example.com
+func nameText(name NameDetails) string {
+	rdns := []string{}
+	for _, rdn := range name.RDNs {
+		attributes := []string{}
+		for _, attribute := range rdn {
+			nameType := attribute.ShortName
+			if nameType == "" {
+				nameType = attribute.OID
+			}
+			value := attribute.Value
+			if attribute.Type == ASN1TypeRaw {
+				// RFC 4514 describes values that aren't a string as their hexadecimal DER encoding prefixed by #
+				attributes = append(attributes, nameType+" = #"+value)
+				continue
+			}
+			if strings.ContainsAny(value, ",+;<>\"\\=") || strings.TrimSpace(value) != value {
+				value = "\"" + strings.ReplaceAll(value, "\"", "\\\"") + "\""
+			}
+			attributes = append(attributes, nameType+" = "+value)
+		}
+		rdns = append(rdns, strings.Join(attributes, " + "))
+	}
+	return strings.Join(rdns, ", ")
+}
+
+// dateText returns the given RFC 3339 timestamp formatted such as May  7 16:25:26 2034 GMT
+func dateText(value string) string {
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return value
+	}
+	return t.UTC().Format("Jan _2 15:04:05 2006 GMT")
+}
+
+// colonHex returns the given hexadecimal string in upper case with each byte separated by a colon
+func colonHex(value string) string {
+	pairs := []string{}
+	for i := 0; i+1 < len(value); i += 2 {
+		pairs = append(pairs, strings.ToUpper(value[i:i+2]))
+	}
+	return strings.Join(pairs, ":")
+}
+
+func generalNameText(name GeneralName) string {
+	switch name.Type {
+	case AlternateNameTypeDNS:
+		return "DNS:" + name.Value
+	case AlternateNameTypeEmail:
+		return "email:" + name.Value
+	case AlternateNameTypeIP:
+		return "IP Address:" + name.Value
+	case AlternateNameTypeURI:
+		return "URI:" + name.Value
+	case GeneralNameTypeDirectory:
+		return "DirName:" + name.Value
+	case GeneralNameTypeOther:
+		return "othername:" + name.OID + ":" + name.Value
+	case GeneralNameTypeRegisteredID:
+		return "Registered ID:" + name.Value
+	}
+	return "<unsupported>:" + name.Value
+}
+
+type textWriter struct {
+	strings.Builder
+}
+
+func (w *textWriter) line(indent int, format string, args ...any) {
+	w.WriteString(strings.Repeat(" ", indent))
+	fmt.Fprintf(w, format, args...)
+	w.WriteString("\n")
+}
+
+// hexDump writes data as colon separated hexadecimal bytes, perLine bytes to a line
+func (w *textWriter) hexDump(indent int, perLine int, data []byte) {
+	for i := 0; i < len(data); i += perLine {
+		end := min(i+perLine, len(data))
+		pairs := []string{}
+		for _, b := range data[i:end] {
+			pairs = append(pairs, fmt.Sprintf("%02x", b))
+		}
+		separator := ":"
+		if end == len(data) {
+			separator = ""
+		}
+		w.line(indent, "%s%s", strings.Join(pairs, ":"), separator)
+	}
+}
+
+// serial writes a serial number inline if it is small enough, otherwise as hexadecimal bytes on the following line
+func (w *textWriter) serial(indent int, label string, serial *big.Int) {
+	if serial == nil {
+		w.line(indent, "%s", label)
+		return
+	}
+	if serial.IsInt64() && serial.Sign() >= 0 {
+		w.line(indent, "%s %d (0x%x)", label, serial.Int64(), serial.Int64())
+		return
+	}
+	w.line(indent, "%s", label)
+	w.line(indent+4, "%s", strings.ToLower(colonHex(FormatSerialHex(serial))))
+}
+
+func (w *textWriter) publicKey(indent int, key PublicKeyDetails) {
+	algorithm, known := publicKeyAlgorithmTextNames[key.AlgorithmOID]
+	if !known {
+		algorithm = key.AlgorithmOID
+	}
+	value, _ := hex.DecodeString(key.Value)
+
+	w.line(indent, "Subject Public Key Info:")
+	w.line(indent+4, "Public Key Algorithm: %s", algorithm)
+	switch key.Algorithm {
+	case "RSA":
+		modulus, _ := hex.DecodeString(key.Modulus)
+		if len(modulus) > 0 && modulus[0]&0x80 != 0 {
+			modulus = append([]byte{0}, modulus...)
+		}
+		w.line(indent+8, "Public-Key: (%d bit)", key.Bits)
+		w.line(indent+8, "Modulus:")
+		w.hexDump(indent+12, 15, modulus)
+		w.line(indent+8, "Exponent: %d (0x%x)", key.Exponent, key.Exponent)
+	case "ECDSA":
+		w.line(indent+8, "Public-Key: (%d bit)", key.Bits)
+		w.line(indent+8, "pub:")
+		w.hexDump(indent+12, 15, value)
+		if name, known := curveTextNames[key.Curve]; known {
+			w.line(indent+8, "ASN1 OID: %s", name)
+		}
+		w.line(indent+8, "NIST CURVE: %s", key.Curve)
+	case "Ed25519":
+		w.line(indent+8, "ED25519 Public-Key:")
+		w.line(indent+8, "pub:")
+		w.hexDump(indent+12, 15, value)
+	default:
+		w.line(indent+8, "Unable to load Public Key")
+		w.hexDump(indent+12, 15, value)
+	}
+}
+
+func (w *textWriter) signature(algorithmOID string, signature string) {
+	data, _ := hex.DecodeString(signature)
+	w.line(4, "Signature Algorithm: %s", signatureAlgorithmTextName(algorithmOID))
+	w.line(4, "Signature Value:")
+	w.hexDump(8, 18, data)
+}
+
+func (w *textWriter) extensions(indent int, extensions []ExtensionDetails) {
+	for _, ext := range extensions {
+		label := ext.OID
+		if ext.Name != "" {
+			label = ext.Name
+			if strings.HasPrefix(ext.OID, "2.5.29.") {
+				label = "X509v3 " + label
+			}
+		}
+		critical := ""
+		if ext.Critical {
+			critical = "critical"
+		}
+		w.line(indent, "%s: %s", label, critical)
+		w.extensionValue(indent+4, ext)
+	}
+}
+
+func (w *textWriter) extensionValue(indent int, ext ExtensionDetails) {
+	if ext.Error != "" {
+		raw, _ := hex.DecodeString(ext.Raw)
+		w.line(indent, "<invalid: %s>", ext.Error)
+		w.hexDump(indent, 18, raw)
+		return
+	}
+
+	switch ext.OID {
+	case oidExtensionSubjectKeyId.String():
+		w.line(indent, "%s", colonHex(ext.SubjectKeyID))
+	case oidExtensionAuthorityKeyId.String():
+		aki := ext.AuthorityKeyID
+		if len(aki.Issuer) == 0 && aki.SerialHex == "" {
+			w.line(indent, "%s", colonHex(aki.KeyID))
+			return
+		}
+		if aki.KeyID != "" {
+			w.line(indent, "keyid:%s", colonHex(aki.KeyID))
+		}
+		for _, name := range aki.Issuer {
+			w.line(indent, "%s", generalNameText(name))
+		}
+		if aki.SerialHex != "" {
+			w.line(indent, "serial:%s", colonHex(aki.SerialHex))
+		}
+	case oidExtensionKeyUsage.String():
+		w.line(indent, "%s", strings.Join(ext.KeyUsage, ", "))
+	case oidExtensionExtendedKeyUsage.String():
+		w.line(indent, "%s", strings.Join(ext.ExtendedKeyUsage, ", "))
+	case oidExtensionBasicConstraints.String():
+		text := "CA:FALSE"
+		if ext.BasicConstraints.CertificateAuthority {
+			text = "CA:TRUE"
+		}
+		if ext.BasicConstraints.MaxPathLen != nil {
+			text += fmt.Sprintf(", pathlen:%d", *ext.BasicConstraints.MaxPathLen)
+		}
+		w.line(indent, "%s", text)
+	case oidExtensionSubjectAltName.String(), oidExtensionIssuerAltName.String():
+		names := []string{}
+		for _, name := range ext.AlternateNames {
+			names = append(names, generalNameText(name))
+		}
+		w.line(indent, "%s", strings.Join(names, ", "))
+	case oidExtensionNameConstraints.String():
+		if ext.NameConstraints == nil {
+			w.asn1(indent, ext)
+			return
+		}
+		w.nameConstraints(indent, *ext.NameConstraints)
+	case oidExtensionCertificatePolicies.String():
+		for _, policy := range ext.Policies {
+			w.line(indent, "Policy: %s", policy.OID)
+			for _, cps := range policy.CPS {
+				w.line(indent+2, "CPS: %s", cps)
+			}
+			for _, notice := range policy.UserNotices {
+				w.line(indent+2, "User Notice:")
+				if notice.Organization != "" {
+					w.line(indent+4, "Organization: %s", notice.Organization)
+				}
+				if len(notice.NoticeNumbers) > 0 {
+					numbers := []string{}
+					for _, number := range notice.NoticeNumbers {
+						numbers = append(numbers, strconv.Itoa(number))
+					}
+					w.line(indent+4, "Number%s: %s", plural(len(numbers)), strings.Join(numbers, ", "))
+				}
+				if notice.ExplicitText != "" {
+					w.line(indent+4, "Explicit Text: %s", notice.ExplicitText)
+				}
+			}
+		}
+	case oidExtensionPolicyMappings.String():
+		for _, mapping := range ext.PolicyConstraints.Mappings {
+			w.line(indent, "%s:%s", mapping.IssuerDomainPolicy, mapping.SubjectDomainPolicy)
+		}
+	case oidExtensionPolicyConstraints.String():
+		constraints := []string{}
+		if ext.PolicyConstraints.RequireExplicitPolicy > 0 || ext.PolicyConstraints.RequireExplicitPolicyZero {
+			constraints = append(constraints, fmt.Sprintf("Require Explicit Policy:%d", ext.PolicyConstraints.RequireExplicitPolicy))
+		}
+		if ext.PolicyConstraints.InhibitPolicyMapping > 0 || ext.PolicyConstraints.InhibitPolicyMappingZero {
+			constraints = append(constraints, fmt.Sprintf("Inhibit Policy Mapping:%d", ext.PolicyConstraints.InhibitPolicyMapping))
+		}
+		w.line(indent, "%s", strings.Join(constraints, ", "))
+	case oidExtensionInhibitAnyPolicy.String():
+		w.line(indent, "%d", ext.PolicyConstraints.InhibitAnyPolicy)
+	case oidExtensionCRLDistPoints.String():
+		w.line(indent, "Full Name:")
+		for _, name := range ext.CRLDistributionPoints {
+			w.line(indent+2, "%s", generalNameText(name))
+		}
+	case oidExtensionAuthorityInfo.String():
+		for _, access := range ext.AuthorityInfoAccess {
+			w.line(indent, "%s - %s", access.Method, generalNameText(access.Location))
+		}
+	case oidExtensionCRLNumber.String(), oidExtensionDeltaCRLIndicator.String():
+		if ext.ASN1 != nil && ext.ASN1.Type == ASN1TypeInteger {
+			w.line(indent, "%s", ext.ASN1.Value)
+			return
+		}
+		w.asn1(indent, ext)
+	case oidExtensionCRLReason.String():
+		if ext.ASN1 != nil && ext.ASN1.Type == ASN1TypeEnumerated {
+			if reason, err := strconv.Atoi(ext.ASN1.Value); err == nil && revocationReasonTextNames[reason] != "" {
+				w.line(indent, "%s", revocationReasonTextNames[reason])
+				return
+			}
+		}
+		w.asn1(indent, ext)
+	default:
+		w.asn1(indent, ext)
+	}
+}
+
+func (w *textWriter) nameConstraints(indent int, constraints NameConstraints) {
+	subtrees := func(label string, dns, email, ip, uri []string) {
+		if len(dns)+len(email)+len(ip)+len(uri) == 0 {
+			return
+		}
+		w.line(indent, "%s:", label)
+		for _, name := range dns {
+			w.line(indent+2, "DNS:%s", name)
+		}
+		for _, name := range email {
+			w.line(indent+2, "email:%s", name)
+		}
+		for _, name := range ip {
+			// Ranges are written as an address and mask, such as 10.0.0.0/255.0.0.0
+			if _, ipRange, err := net.ParseCIDR(name); err == nil {
+				name = ipRange.IP.String() + "/" + net.IP(ipRange.Mask).String()
+			}
+			w.line(indent+2, "IP:%s", name)
+		}
+		for _, name := range uri {
+			w.line(indent+2, "URI:%s", name)
+		}
+	}
+	subtrees("Permitted", constraints.PermittedDNSDomains, constraints.PermittedEmailAddresses, constraints.PermittedIPRanges, constraints.PermittedURIDomains)
+	subtrees("Excluded", constraints.ExcludedDNSDomains, constraints.ExcludedEmailAddresses, constraints.ExcludedIPRanges, constraints.ExcludedURIDomains)
+}
+
+// asn1 writes the value of an extension that has no specific layout as its ASN.1 structure, or as hexadecimal bytes
+// if it isn't valid DER
+func (w *textWriter) asn1(indent int, ext ExtensionDetails) {
+	if ext.ASN1 == nil {
+		raw, _ := hex.DecodeString(ext.Raw)
+		w.hexDump(indent, 18, raw)
+		return
+	}
+	w.asn1Value(indent, *ext.ASN1)
+}
+
+func (w *textWriter) asn1Value(indent int, value ASN1Value) {
+	label := strings.ToUpper(value.Type)
+	if value.Tagging != "" {
+		label = fmt.Sprintf("[%d] %s", value.Tag, label)
+	}
+	if value.Type == ASN1TypeSequence || value.Type == ASN1TypeSet {
+		w.line(indent, "%s:", label)
+		for _, child := range value.Children {
+			w.asn1Value(indent+2, child)
+		}
+		return
+	}
+	w.line(indent, "%s:%s", label, value.Value)
+}
+
+func plural(count int) string {
+	if count == 1 {
+		return ""
+	}
+	return "s"
+}
@@ -0,0 +1,200 @@
+package tls_test
+
+import (
+	"encoding/pem"
+	"strings"
+	"testing"
+
+	"github.com/tls-inspector/certbox/tls"
+)
+
+// superfishText is the output of openssl x509 -text for pemCert, except for the format of the directory name
+const superfishText = `Certificate:
+    Data:
+        Version: 3 (0x2)
+        Serial Number:
+            d2:fc:13:87:a9:44:dc:e7
+        Signature Algorithm: sha1WithRSAEncryption
+        Issuer: O = "Superfish, Inc.", L = SF, ST = CA, C = US, CN = "Superfish, Inc."
+        Validity
+            Not Before: May 12 16:25:26 2014 GMT
+            Not After : May  7 16:25:26 2034 GMT
+        Subject: O = "Superfish, Inc.", L = SF, ST = CA, C = US, CN = "Superfish, Inc."
+        Subject Public Key Info:
+            Public Key Algorithm: rsaEncryption
+                Public-Key: (1024 bit)
+                Modulus:
+                    00:e8:f3:4a:18:76:5f:19:3f:b1:cf:58:e9:7f:43:
+                    07:09:95:80:35:c5:0f:fe:71:31:27:81:99:12:26:
+                    20:a5:df:8f:6a:fc:42:55:39:ee:09:38:89:d9:e0:
+                    36:c4:ac:01:82:5b:d5:39:e6:f9:8f:07:88:df:fe:
+                    ee:f6:a1:14:ce:a9:74:45:d8:fd:f0:17:57:2a:82:
+                    e1:7a:2e:12:93:5a:ac:8a:d7:15:63:d1:b7:9b:55:
+                    80:0f:58:bc:1c:49:ed:20:62:dd:b6:4c:a5:3a:eb:
+                    1c:3d:a0:ff:7a:71:a6:d3:10:78:33:ae:4b:c2:1c:
+                    fd:92:4a:a1:c3:e7:41:a4:2d
+                Exponent: 65537 (0x10001)
+        X509v3 extensions:
+            X509v3 Basic Constraints: 
+                CA:TRUE
+            X509v3 Subject Key Identifier: 
+                FB:98:B3:53:7F:14:44:2E:E8:EE:D5:09:9A:5E:0E:56:86:A8:35:88
+            X509v3 Authority Key Identifier: 
+                keyid:FB:98:B3:53:7F:14:44:2E:E8:EE:D5:09:9A:5E:0E:56:86:A8:35:88
+                DirName:CN=Superfish\, Inc.,C=US,ST=CA,L=SF,O=Superfish\, Inc.
+                serial:D2:FC:13:87:A9:44:DC:E7
+    Signature Algorithm: sha1WithRSAEncryption
+    Signature Value:
+        a4:7c:a0:ec:0a:4a:c7:70:c4:71:68:f3:3b:22:e2:dc:9c:8d:
+        d0:92:fe:73:7e:72:2b:55:44:9b:1b:b4:42:eb:1f:af:be:ba:
+        e3:93:a3:d4:8b:18:c2:94:f0:b3:a6:bd:65:34:4c:cd:24:f8:
+        19:0b:c5:15:0a:da:f3:57:8b:a9:86:cf:6c:c3:ee:84:2f:85:
+        0b:19:14:17:98:b4:0c:d4:96:8b:e9:1c:cc:95:c9:4e:d0:aa:
+        4b:01:a5:f6:df:49:12:81:6a:be:d5:be:ce:76:7d:4e:ac:8b:
+        88:e3:30:ed:31:84:50:8f:bc:f1:50:2a:5b:4a:a6:5e:7c:0f:
+        71:fa
+`
+
+func TestCertificateText(t *testing.T) {
+	t.Parallel()
+
+	// Private keys are never included
+	text, err := tls.Text([]byte(pemCert + "\n" + pemPlainKey))
+	if err != nil {
+		t.Fatalf("Error formatting certificate: %s", err.Error())
+	}
+	if text != superfishText {
+		t.Errorf("Unexpected certificate text:\n%s", text)
+	}
+
+	block, _ := pem.Decode([]byte(pemCert))
+	derText, err := tls.Text(block.Bytes)
+	if err != nil {
+		t.Fatalf("Error formatting certificate: %s", err.Error())
+	}
+	if derText != superfishText {
+		t.Errorf("Unexpected DER certificate text:\n%s", derText)
+	}
+}
+
+func TestCertificateTextExtensions(t *testing.T) {
+	t.Parallel()
+
+	request := tls.CertificateRequest{
+		KeyType:            tls.KeyTypeECDSA_256,
+		SignatureAlgorithm: tls.SignatureAlgorithmSHA256,
+		Subject:            tls.Name{Organization: "example.com", CommonName: "example.com Root", Country: "CA"},
+		Validity:           tls.DateRange{NotBefore: "2001-01-01", NotAfter: "2002-01-01"},
+		AlternateNames: []tls.AlternateName{
+			{Type: tls.AlternateNameTypeDNS, Value: "example.com"},
+			{Type: tls.AlternateNameTypeIP, Value: "192.0.2.1"},
+		},
+		Usage:                  tls.KeyUsage{DigitalSignature: true, CertSign: true, CRLSign: true, ServerAuth: true},
+		IsCertificateAuthority: true,
+		MaxPathLen:             2,
+		NameConstraints:        tls.NameConstraints{Critical: true, PermittedDNSDomains: []string{"example.com"}, ExcludedIPRanges: []string{"10.0.0.0/8"}},
+		Policies: []tls.CertificatePolicy{{
+			OID:         tls.PolicyDomainValidated,
+			CPS:         []string{"https://example.com/cps"},
+			UserNotices: []tls.UserNotice{{ExplicitText: "Hello"}},
+		}},
+		PolicyConstraints: tls.PolicyConstraints{RequireExplicitPolicy: 1, InhibitAnyPolicyZero: true},
+		StatusProviders: tls.StatusProviders{
+			CRL:  []string{"http://crl.example.com/root.crl"},
+			OCSP: []string{"http://ocsp.example.com"},
+		},
+		Extensions: []tls.Extension{{OID: "1.2.3.4.5", Value: "custom"}},
+	}
+	root, err := tls.GenerateCertificate(request, nil)
+	if err != nil {
+		t.Fatalf("Error generating certificate: %s", err.Error())
+	}
+	text, err := tls.CertificateText(*root)
+	if err != nil {
+		t.Fatalf("Error formatting certificate: %s", err.Error())
+	}
+
+	expected := []string{
+		"        Issuer: C = CA, O = example.com, CN = example.com Root\n",
+		"            Not Before: Jan  1 00:00:00 2001 GMT\n",
+		"            Public Key Algorithm: id-ecPublicKey\n",
+		"                NIST CURVE: P-256\n",
+		"            X509v3 Key Usage: critical\n                Digital Signature, Certificate Sign, CRL Sign\n",
+		"            X509v3 Extended Key Usage: \n                TLS Web Server Authentication\n",
+		"            X509v3 Basic Constraints: critical\n                CA:TRUE, pathlen:2\n",
+		"            X509v3 Subject Alternative Name: \n                DNS:example.com, IP Address:192.0.2.1\n",
+		"                Permitted:\n                  DNS:example.com\n                Excluded:\n                  IP:10.0.0.0/255.0.0.0\n",
+		"                Policy: 2.23.140.1.2.1\n                  CPS: https://example.com/cps\n                  User Notice:\n                    Explicit Text: Hello\n",
+		"            X509v3 Policy Constraints: critical\n                Require Explicit Policy:1\n",
+		"            X509v3 Inhibit Any Policy: critical\n                0\n",
+		"            X509v3 CRL Distribution Points: \n                Full Name:\n                  URI:http://crl.example.com/root.crl\n",
+		"            Authority Information Access: \n                OCSP - URI:http://ocsp.example.com\n",
+		"            1.2.3.4.5: \n                PRINTABLESTRING:custom\n",
+		"    Signature Algorithm: ecdsa-with-SHA256\n",
+	}
+	for _, line := range expected {
+		if !strings.Contains(text, line) {
+			t.Errorf("Certificate text does not contain:\n%s", line)
+		}
+	}
+
+	csr, _, err := tls.GenerateCSR(request)
+	if err != nil {
+		t.Fatalf("Error generating CSR: %s", err.Error())
+	}
+	text, err = tls.Text(csr)
+	if err != nil {
+		t.Fatalf("Error formatting CSR: %s", err.Error())
+	}
+	expected = []string{
+		"Certificate Request:\n    Data:\n        Version: 1 (0x0)\n",
+		"        Attributes:\n            Requested Extensions:\n",
+		"                X509v3 Subject Alternative Name: \n                    DNS:example.com, IP Address:192.0.2.1\n",
+	}
+	for _, line := range expected {
+		if !strings.Contains(text, line) {
+			t.Errorf("CSR text does not contain:\n%s", line)
+		}
+	}
+
+	crl, err := tls.GenerateCRL(tls.CRLRequest{
+		Number:     "2",
+		ThisUpdate: "2001-02-01",
+		NextUpdate: "2001-03-01",
+		Revoked:    []tls.RevokedCertificate{{Serial: "0x1000", RevocationDate: "2001-01-15", ReasonCode: tls.RevocationReasonKeyCompromise}},
+	}, root)
+	if err != nil {
+		t.Fatalf("Error generating CRL: %s", err.Error())
+	}
+	text, err = tls.Text(pem.EncodeToMemory(&pem.Block{Type: "X509 CRL", Bytes: crl}))
+	if err != nil {
+		t.Fatalf("Error formatting CRL: %s", err.Error())
+	}
+	expected = []string{
+		"Certificate Revocation List (CRL):\n        Version 2 (0x1)\n",
+		"        Last Update: Feb  1 00:00:00 2001 GMT\n        Next Update: Mar  1 00:00:00 2001 GMT\n",
+		"            X509v3 CRL Number: \n                2\n",
+		"Revoked Certificates:\n    Serial Number: 1000\n        Revocation Date: Jan 15 00:00:00 2001 GMT\n",
+		"            X509v3 CRL Reason Code: \n                Key Compromise\n",
+	}
+	for _, line := range expected {
+		if !strings.Contains(text, line) {
+			t.Errorf("CRL text does not contain:\n%s", line)
+		}
+	}
+}
+
+func TestTextInvalid(t *testing.T) {
+	t.Parallel()
+
+	invalid := map[string]string{
+		"empty":       "",
+		"garbage":     "not a certificate",
+		"private key": pemPlainKey,
+	}
+	for name, data := range invalid {
+		if _, err := tls.Text([]byte(data)); err == nil {
+			t.Errorf("No error seen when one expected for %s", name)
+		}
+	}
+}
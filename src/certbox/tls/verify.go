@@ -0,0 +1,339 @@
+package tls
+
+import (
+	"bytes"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net"
+	"slices"
+	"time"
+)
+
+// Chain verification failure reasons
+const (
+	VerifyFailureExpired             = "expired"
+	VerifyFailureNotYetValid         = "not_yet_valid"
+	VerifyFailureUnknownAuthority    = "unknown_authority"
+	VerifyFailureNameMismatch        = "name_mismatch"
+	VerifyFailureUsageMismatch       = "usage_mismatch"
+	VerifyFailureConstraintViolation = "constraint_violation"
+	VerifyFailureRevoked             = "revoked"
+	// VerifyFailureInvalidCRL is a CRL for a certificate in the chain that has an invalid signature or has expired
+	VerifyFailureInvalidCRL = "invalid_crl"
+	// VerifyFailureInvalid is any other reason, such as an unsupported signature algorithm
+	VerifyFailureInvalid = "invalid"
+)
+
+// VerifyOptions describes the options for verifying a certificate chain
+type VerifyOptions struct {
+	// DNSName and IPAddress, if set, must be a name of the leaf certificate
+	DNSName   string
+	IPAddress string
+	// Usage describes the extended key usages that are required of the leaf and permitted by its issuers. The basic key
+	// usages are ignored.
+	Usage KeyUsage
+	// Time is the time to verify the chain at, in any of the formats supported by DateRange. Defaults to now.
+	Time string
+	// CRLs are PEM or DER encoded certificate revocation lists. Each certificate in a chain is checked against the
+	// CRLs of its issuer.
+	CRLs [][]byte
+}
+
+// VerifyResult describes the result of verifying a certificate chain
+type VerifyResult struct {
+	Valid bool
+	// Chains contains every path from the leaf to a trusted root, beginning with the leaf
+	Chains [][]*Certificate
+	// Failures describes every reason the chain is not valid
+	Failures []VerifyFailure
+}
+
+// VerifyFailure describes a single reason a certificate chain is not valid
+type VerifyFailure struct {
+	Reason string
+	// Subject and SerialHex identify the certificate that failed, if any
+	Subject   string
+	SerialHex string
+	Message   string
+}
+
+// VerifyChain will verify that the leaf certificate chains to one of the trusted roots, through any of the given
+// intermediates, and that it is valid for the given options. At least one root is required.
+func VerifyChain(leaf Certificate, intermediates []Certificate, roots []Certificate, options VerifyOptions) (*VerifyResult, error) {
+	if len(roots) == 0 {
+		return nil, fmt.Errorf("at least one trusted root is required")
+	}
+
+	leafX, err := parseCertificate(leaf)
+	if err != nil {
+		return nil, fmt.Errorf("invalid leaf: %s", err.Error())
+	}
+	all := []*x509.Certificate{leafX}
+	intermediatePool := x509.NewCertPool()
+	for i, intermediate := range intermediates {
+		x, err := parseCertificate(intermediate)
+		if err != nil {
+			return nil, fmt.Errorf("invalid intermediate %d: %s", i, err.Error())
+		}
+		intermediatePool.AddCert(x)
+		all = append(all, x)
+	}
+	rootPool := x509.NewCertPool()
+	for i, root := range roots {
+		x, err := parseCertificate(root)
+		if err != nil {
+			return nil, fmt.Errorf("invalid root %d: %s", i, err.Error())
+		}
+		rootPool.AddCert(x)
+		all = append(all, x)
+	}
+
+	currentTime := time.Now().UTC()
+	if options.Time != "" {
+		currentTime, err = parseDate(options.Time)
+		if err != nil {
+			return nil, fmt.Errorf("invalid verification time: %s", err.Error())
+		}
+	}
+	if options.IPAddress != "" && net.ParseIP(options.IPAddress) == nil {
+		return nil, fmt.Errorf("invalid ip address '%s'", options.IPAddress)
+	}
+	customUsages, err := options.Usage.customExtendedUsage()
+	if err != nil {
+		return nil, err
+	}
+	crls, err := parseCRLs(options.CRLs)
+	if err != nil {
+		return nil, err
+	}
+
+	failures := &verifyFailures{failures: []VerifyFailure{}}
+
+	// Paths are first built without regard for names or usage. If they can't be built because of an expired
+	// certificate, they are built again at a time that all of the certificates are valid so that the other problems
+	// of the chain can still be found.
+	verifyOptions := x509.VerifyOptions{
+		Roots:         rootPool,
+		Intermediates: intermediatePool,
+		CurrentTime:   currentTime,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	}
+	chains, err := leafX.Verify(verifyOptions)
+	var invalid x509.CertificateInvalidError
+	if errors.As(err, &invalid) && invalid.Reason == x509.Expired {
+		verifyOptions.CurrentTime = validTime(all)
+		chains, err = leafX.Verify(verifyOptions)
+	}
+	if err != nil {
+		failures.addError(err)
+	}
+
+	checked := map[*x509.Certificate]bool{}
+	checkValidity := func(x *x509.Certificate) {
+		if checked[x] {
+			return
+		}
+		checked[x] = true
+		if currentTime.Before(x.NotBefore) {
+			failures.add(VerifyFailureNotYetValid, x, fmt.Sprintf("certificate is not valid until %s", x.NotBefore.UTC().Format(time.RFC3339)))
+		}
+		if currentTime.After(x.NotAfter) {
+			failures.add(VerifyFailureExpired, x, fmt.Sprintf("certificate expired at %s", x.NotAfter.UTC().Format(time.RFC3339)))
+		}
+	}
+	checkValidity(leafX)
+	for _, chain := range chains {
+		for _, x := range chain {
+			checkValidity(x)
+		}
+	}
+
+	// Verify accepts a chain that permits any one of KeyUsages, so each required usage is checked on its own
+	for _, usage := range options.Usage.extendedUsage() {
+		if len(chains) > 0 {
+			usageOptions := verifyOptions
+			usageOptions.KeyUsages = []x509.ExtKeyUsage{usage}
+			if _, err := leafX.Verify(usageOptions); err != nil {
+				failures.add(VerifyFailureUsageMismatch, leafX, err.Error())
+			}
+		} else if !hasExtendedKeyUsage(leafX, usage) {
+			failures.add(VerifyFailureUsageMismatch, leafX, "certificate specifies an incompatible key usage")
+		}
+	}
+	for _, usage := range customUsages {
+		if !hasCustomExtendedKeyUsage(leafX, usage) {
+			failures.add(VerifyFailureUsageMismatch, leafX, fmt.Sprintf("certificate does not permit extended key usage %s", usage.String()))
+		}
+	}
+
+	if options.DNSName != "" {
+		if err := leafX.VerifyHostname(options.DNSName); err != nil {
+			failures.add(VerifyFailureNameMismatch, leafX, err.Error())
+		}
+	}
+	if options.IPAddress != "" {
+		if err := leafX.VerifyHostname(options.IPAddress); err != nil {
+			failures.add(VerifyFailureNameMismatch, leafX, err.Error())
+		}
+	}
+
+	for _, chain := range chains {
+		checkRevocation(chain, crls, currentTime, failures)
+	}
+
+	result := &VerifyResult{
+		Valid:    len(failures.failures) == 0,
+		Chains:   [][]*Certificate{},
+		Failures: failures.failures,
+	}
+	for _, chain := range chains {
+		certificates := []*Certificate{}
+		for _, x := range chain {
+			certificate, err := ImportDERCertificate(x.Raw)
+			if err != nil {
+				return nil, err
+			}
+			certificates = append(certificates, certificate)
+		}
+		result.Chains = append(result.Chains, certificates)
+	}
+	return result, nil
+}
+
+// parseCertificate returns the parsed certificate data, or an error if it is invalid
+func parseCertificate(certificate Certificate) (*x509.Certificate, error) {
+	data, err := hex.DecodeString(certificate.CertificateData)
+	if err != nil {
+		return nil, err
+	}
+	return x509.ParseCertificate(data)
+}
+
+// parseCRLs parses every PEM or DER encoded certificate revocation list in data
+func parseCRLs(data [][]byte) ([]*x509.RevocationList, error) {
+	crls := []*x509.RevocationList{}
+	for i, crlData := range data {
+		blocks := [][]byte{}
+		for rest := crlData; ; {
+			var block *pem.Block
+			block, rest = pem.Decode(rest)
+			if block == nil {
+				break
+			}
+			if block.Type == "X509 CRL" {
+				blocks = append(blocks, block.Bytes)
+			}
+		}
+		if len(blocks) == 0 {
+			blocks = append(blocks, crlData)
+		}
+
+		for _, block := range blocks {
+			crl, err := x509.ParseRevocationList(block)
+			if err != nil {
+				return nil, fmt.Errorf("invalid crl %d: %s", i, err.Error())
+			}
+			crls = append(crls, crl)
+		}
+	}
+	return crls, nil
+}
+
+// validTime returns a time at which all of the given certificates are valid, or the latest NotBefore date if there
+// is no such time
+func validTime(certificates []*x509.Certificate) time.Time {
+	t := certificates[0].NotBefore
+	for _, x := range certificates {
+		if x.NotBefore.After(t) {
+			t = x.NotBefore
+		}
+	}
+	return t
+}
+
+// hasExtendedKeyUsage returns true if x permits the given extended key usage
+func hasExtendedKeyUsage(x *x509.Certificate, usage x509.ExtKeyUsage) bool {
+	if len(x.ExtKeyUsage) == 0 && len(x.UnknownExtKeyUsage) == 0 {
+		return true
+	}
+	return slices.Contains(x.ExtKeyUsage, x509.ExtKeyUsageAny) || slices.Contains(x.ExtKeyUsage, usage)
+}
+
+// hasCustomExtendedKeyUsage returns true if x permits the given extended key usage OID
+func hasCustomExtendedKeyUsage(x *x509.Certificate, usage asn1.ObjectIdentifier) bool {
+	if len(x.ExtKeyUsage) == 0 && len(x.UnknownExtKeyUsage) == 0 {
+		return true
+	}
+	return slices.Contains(x.ExtKeyUsage, x509.ExtKeyUsageAny) || slices.ContainsFunc(x.UnknownExtKeyUsage, usage.Equal)
+}
+
+// checkRevocation checks each certificate in chain against the CRLs of its issuer
+func checkRevocation(chain []*x509.Certificate, crls []*x509.RevocationList, currentTime time.Time, failures *verifyFailures) {
+	for i := 0; i+1 < len(chain); i++ {
+		x, issuer := chain[i], chain[i+1]
+		for _, crl := range crls {
+			if !bytes.Equal(crl.RawIssuer, issuer.RawSubject) {
+				continue
+			}
+			if err := crl.CheckSignatureFrom(issuer); err != nil {
+				failures.add(VerifyFailureInvalidCRL, issuer, fmt.Sprintf("invalid crl signature: %s", err.Error()))
+				continue
+			}
+			if !crl.NextUpdate.IsZero() && currentTime.After(crl.NextUpdate) {
+				failures.add(VerifyFailureInvalidCRL, issuer, fmt.Sprintf("crl expired at %s", crl.NextUpdate.UTC().Format(time.RFC3339)))
+			}
+			for _, entry := range crl.RevokedCertificateEntries {
+				if entry.SerialNumber.Cmp(x.SerialNumber) == 0 && !entry.RevocationTime.After(currentTime) {
+					failures.add(VerifyFailureRevoked, x, fmt.Sprintf("certificate was revoked at %s", entry.RevocationTime.UTC().Format(time.RFC3339)))
+				}
+			}
+		}
+	}
+}
+
+// verifyFailures is a list of verification failures without duplicates
+type verifyFailures struct {
+	failures []VerifyFailure
+}
+
+func (f *verifyFailures) add(reason string, x *x509.Certificate, message string) {
+	failure := VerifyFailure{Reason: reason, Message: message}
+	if x != nil {
+		failure.Subject = nameDetailsFromRaw(x.RawSubject).DN
+		failure.SerialHex = FormatSerialHex(x.SerialNumber)
+	}
+	if !slices.Contains(f.failures, failure) {
+		f.failures = append(f.failures, failure)
+	}
+}
+
+// addError adds the failure described by an error from building a chain
+func (f *verifyFailures) addError(err error) {
+	var unknown x509.UnknownAuthorityError
+	if errors.As(err, &unknown) {
+		f.add(VerifyFailureUnknownAuthority, unknown.Cert, err.Error())
+		return
+	}
+	var invalid x509.CertificateInvalidError
+	if !errors.As(err, &invalid) {
+		f.add(VerifyFailureInvalid, nil, err.Error())
+		return
+	}
+
+	reason := VerifyFailureInvalid
+	switch invalid.Reason {
+	case x509.NotAuthorizedToSign, x509.CANotAuthorizedForThisName, x509.TooManyIntermediates, x509.NameConstraintsWithoutSANs, x509.UnconstrainedName, x509.TooManyConstraints:
+		reason = VerifyFailureConstraintViolation
+	case x509.IncompatibleUsage, x509.CANotAuthorizedForExtKeyUsage:
+		reason = VerifyFailureUsageMismatch
+	case x509.NameMismatch:
+		reason = VerifyFailureUnknownAuthority
+	case x509.Expired:
+		reason = VerifyFailureExpired
+	}
+	f.add(reason, invalid.Cert, err.Error())
+}
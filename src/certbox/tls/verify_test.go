@@ -0,0 +1,385 @@
+package tls_test
+
+import (
+	"encoding/hex"
+	"encoding/pem"
+	"testing"
+
+	"github.com/tls-inspector/certbox/tls"
+)
+
+func assertVerifyFailure(t *testing.T, result *tls.VerifyResult, reason string, subject *tls.Certificate) {
+	t.Helper()
+
+	if result.Valid {
+		t.Errorf("Chain should not be valid")
+	}
+	for _, failure := range result.Failures {
+		if failure.Reason == reason && (subject == nil || failure.SerialHex == subject.SerialHex) {
+			return
+		}
+	}
+	t.Errorf("No %s failure seen. Failures: %+v", reason, result.Failures)
+}
+
+func TestVerifyChain(t *testing.T) {
+	t.Parallel()
+
+	chain := generateLongCertificateChain(t)
+	leaf, intermediate, root := chain[0], chain[1], chain[2]
+
+	result, err := tls.VerifyChain(*leaf, []tls.Certificate{*intermediate}, []tls.Certificate{*root}, tls.VerifyOptions{
+		DNSName: "foo.example.com",
+		Usage:   tls.KeyUsage{ServerAuth: true},
+		Time:    "2001-06-01",
+	})
+	if err != nil {
+		t.Fatalf("Error verifying chain: %s", err.Error())
+	}
+	if !result.Valid {
+		t.Errorf("Chain should be valid. Failures: %+v", result.Failures)
+	}
+	if len(result.Failures) != 0 {
+		t.Errorf("Unexpected failures: %+v", result.Failures)
+	}
+	if result.Failures == nil {
+		t.Errorf("Failures should be empty, not nil")
+	}
+	if len(result.Chains) != 1 {
+		t.Fatalf("Unexpected number of chains. Expected 1 got %d", len(result.Chains))
+	}
+	if len(result.Chains[0]) != 3 {
+		t.Fatalf("Unexpected chain length. Expected 3 got %d", len(result.Chains[0]))
+	}
+	for i, expected := range chain {
+		if result.Chains[0][i].CertificateData != expected.CertificateData {
+			t.Errorf("Unexpected certificate at position %d in chain", i)
+		}
+	}
+}
+
+func TestVerifyChainExpired(t *testing.T) {
+	t.Parallel()
+
+	chain := generateLongCertificateChain(t)
+	leaf, intermediate, root := chain[0], chain[1], chain[2]
+
+	result, err := tls.VerifyChain(*leaf, []tls.Certificate{*intermediate}, []tls.Certificate{*root}, tls.VerifyOptions{
+		Time: "2003-01-01",
+	})
+	if err != nil {
+		t.Fatalf("Error verifying chain: %s", err.Error())
+	}
+	assertVerifyFailure(t, result, tls.VerifyFailureExpired, leaf)
+	assertVerifyFailure(t, result, tls.VerifyFailureExpired, root)
+	if len(result.Chains) != 1 {
+		t.Errorf("Chain should still be built for an expired certificate")
+	}
+
+	result, err = tls.VerifyChain(*leaf, []tls.Certificate{*intermediate}, []tls.Certificate{*root}, tls.VerifyOptions{
+		Time: "2000-01-01",
+	})
+	if err != nil {
+		t.Fatalf("Error verifying chain: %s", err.Error())
+	}
+	assertVerifyFailure(t, result, tls.VerifyFailureNotYetValid, leaf)
+}
+
+func TestVerifyChainUnknownAuthority(t *testing.T) {
+	t.Parallel()
+
+	chain := generateLongCertificateChain(t)
+	leaf, root := chain[0], chain[2]
+
+	result, err := tls.VerifyChain(*leaf, nil, []tls.Certificate{*root}, tls.VerifyOptions{
+		Time: "2001-06-01",
+	})
+	if err != nil {
+		t.Fatalf("Error verifying chain: %s", err.Error())
+	}
+	assertVerifyFailure(t, result, tls.VerifyFailureUnknownAuthority, leaf)
+	if len(result.Chains) != 0 {
+		t.Errorf("Unexpected chains without an intermediate")
+	}
+}
+
+func TestVerifyChainNameMismatch(t *testing.T) {
+	t.Parallel()
+
+	chain := generateLongCertificateChain(t)
+	leaf, intermediate, root := chain[0], chain[1], chain[2]
+
+	result, err := tls.VerifyChain(*leaf, []tls.Certificate{*intermediate}, []tls.Certificate{*root}, tls.VerifyOptions{
+		DNSName: "bar.example.com",
+		Time:    "2001-06-01",
+	})
+	if err != nil {
+		t.Fatalf("Error verifying chain: %s", err.Error())
+	}
+	assertVerifyFailure(t, result, tls.VerifyFailureNameMismatch, leaf)
+
+	result, err = tls.VerifyChain(*leaf, []tls.Certificate{*intermediate}, []tls.Certificate{*root}, tls.VerifyOptions{
+		IPAddress: "192.0.2.1",
+		Time:      "2001-06-01",
+	})
+	if err != nil {
+		t.Fatalf("Error verifying chain: %s", err.Error())
+	}
+	assertVerifyFailure(t, result, tls.VerifyFailureNameMismatch, leaf)
+}
+
+func TestVerifyChainUsageMismatch(t *testing.T) {
+	t.Parallel()
+
+	chain := generateLongCertificateChain(t)
+	leaf, intermediate, root := chain[0], chain[1], chain[2]
+
+	result, err := tls.VerifyChain(*leaf, []tls.Certificate{*intermediate}, []tls.Certificate{*root}, tls.VerifyOptions{
+		Usage: tls.KeyUsage{ClientAuth: true},
+		Time:  "2001-06-01",
+	})
+	if err != nil {
+		t.Fatalf("Error verifying chain: %s", err.Error())
+	}
+	assertVerifyFailure(t, result, tls.VerifyFailureUsageMismatch, leaf)
+	if len(result.Chains) != 1 {
+		t.Errorf("Chain should still be built for a usage mismatch")
+	}
+}
+
+func TestVerifyChainMultipleUsages(t *testing.T) {
+	t.Parallel()
+
+	chain := generateLongCertificateChain(t)
+	leaf, intermediate, root := chain[0], chain[1], chain[2]
+
+	// The leaf only permits server auth, so requiring both usages must fail
+	result, err := tls.VerifyChain(*leaf, []tls.Certificate{*intermediate}, []tls.Certificate{*root}, tls.VerifyOptions{
+		Usage: tls.KeyUsage{ServerAuth: true, ClientAuth: true},
+		Time:  "2001-06-01",
+	})
+	if err != nil {
+		t.Fatalf("Error verifying chain: %s", err.Error())
+	}
+	assertVerifyFailure(t, result, tls.VerifyFailureUsageMismatch, leaf)
+	mismatches := 0
+	for _, failure := range result.Failures {
+		if failure.Reason == tls.VerifyFailureUsageMismatch {
+			mismatches++
+		}
+	}
+	if mismatches != 1 {
+		t.Errorf("Unexpected number of usage mismatches %d. Failures: %+v", mismatches, result.Failures)
+	}
+}
+
+func TestVerifyChainConstraintViolation(t *testing.T) {
+	t.Parallel()
+
+	request := func(commonName string, isCA bool) tls.CertificateRequest {
+		return tls.CertificateRequest{
+			KeyType:            tls.KeyTypeECDSA_256,
+			SignatureAlgorithm: tls.SignatureAlgorithmSHA256,
+			Subject:            tls.Name{CommonName: commonName},
+			Validity: tls.DateRange{
+				NotBefore: "2001-01-01",
+				NotAfter:  "2002-01-01",
+			},
+			Usage: tls.KeyUsage{
+				DigitalSignature: true,
+				CertSign:         isCA,
+				ServerAuth:       !isCA,
+			},
+			IsCertificateAuthority: isCA,
+		}
+	}
+
+	root, err := tls.GenerateCertificate(request("example.com Root", true), nil)
+	if err != nil {
+		t.Fatalf("Error generating certificate: %s", err.Error())
+	}
+	intermediate, err := tls.GenerateCertificate(request("example.com Intermediate", true), root)
+	if err != nil {
+		t.Fatalf("Error generating certificate: %s", err.Error())
+	}
+	leaf, err := tls.GenerateCertificate(request("foo.example.com", false), intermediate)
+	if err != nil {
+		t.Fatalf("Error generating certificate: %s", err.Error())
+	}
+
+	// Certbox won't issue certificates that violate the constraints of their issuer, so the root is reissued with the
+	// same key and a path length constraint after the chain is issued
+	keyData, err := hex.DecodeString(root.KeyData)
+	if err != nil {
+		t.Fatalf("Error decoding key: %s", err.Error())
+	}
+	rootRequest := request("example.com Root", true)
+	rootRequest.PrivateKey = string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyData}))
+	rootRequest.MaxPathLenZero = true
+	root, err = tls.GenerateCertificate(rootRequest, nil)
+	if err != nil {
+		t.Fatalf("Error generating certificate: %s", err.Error())
+	}
+
+	result, err := tls.VerifyChain(*leaf, []tls.Certificate{*intermediate}, []tls.Certificate{*root}, tls.VerifyOptions{
+		Time: "2001-06-01",
+	})
+	if err != nil {
+		t.Fatalf("Error verifying chain: %s", err.Error())
+	}
+	assertVerifyFailure(t, result, tls.VerifyFailureConstraintViolation, nil)
+}
+
+// generateRevocationChain returns a root that may sign certificates and CRLs, and a leaf signed by it
+func generateRevocationChain(t *testing.T) (*tls.Certificate, *tls.Certificate) {
+	t.Helper()
+
+	root, err := tls.GenerateCertificate(tls.CertificateRequest{
+		KeyType:            tls.KeyTypeECDSA_256,
+		SignatureAlgorithm: tls.SignatureAlgorithmSHA256,
+		Subject:            tls.Name{Organization: "example.com", CommonName: "example.com Root"},
+		Validity: tls.DateRange{
+			NotBefore: "2001-01-01",
+			NotAfter:  "2002-01-01",
+		},
+		Usage:                  tls.KeyUsage{CertSign: true, CRLSign: true},
+		IsCertificateAuthority: true,
+	}, nil)
+	if err != nil {
+		t.Fatalf("Error generating certificate: %s", err.Error())
+	}
+	leaf, err := tls.GenerateCertificate(tls.CertificateRequest{
+		KeyType:            tls.KeyTypeECDSA_256,
+		SignatureAlgorithm: tls.SignatureAlgorithmSHA256,
+		Subject:            tls.Name{Organization: "example.com", CommonName: "foo.example.com"},
+		Validity: tls.DateRange{
+			NotBefore: "2001-01-01",
+			NotAfter:  "2002-01-01",
+		},
+		Usage: tls.KeyUsage{DigitalSignature: true, ServerAuth: true},
+	}, root)
+	if err != nil {
+		t.Fatalf("Error generating certificate: %s", err.Error())
+	}
+	return root, leaf
+}
+
+func TestVerifyChainRevoked(t *testing.T) {
+	t.Parallel()
+
+	root, leaf := generateRevocationChain(t)
+
+	crl, err := tls.GenerateCRL(tls.CRLRequest{
+		Number:     "1",
+		ThisUpdate: "2001-02-01",
+		NextUpdate: "2001-03-01",
+		Revoked: []tls.RevokedCertificate{
+			{
+				Serial:         leaf.Serial,
+				RevocationDate: "2001-01-15",
+				ReasonCode:     tls.RevocationReasonKeyCompromise,
+			},
+		},
+	}, root)
+	if err != nil {
+		t.Fatalf("Error generating CRL: %s", err.Error())
+	}
+
+	result, err := tls.VerifyChain(*leaf, nil, []tls.Certificate{*root}, tls.VerifyOptions{
+		Time: "2001-02-15",
+		CRLs: [][]byte{pem.EncodeToMemory(&pem.Block{Type: "X509 CRL", Bytes: crl})},
+	})
+	if err != nil {
+		t.Fatalf("Error verifying chain: %s", err.Error())
+	}
+	assertVerifyFailure(t, result, tls.VerifyFailureRevoked, leaf)
+	for _, failure := range result.Failures {
+		if failure.Reason == tls.VerifyFailureInvalidCRL {
+			t.Errorf("Unexpected CRL failure: %+v", failure)
+		}
+	}
+
+	// Before the revocation date
+	result, err = tls.VerifyChain(*leaf, nil, []tls.Certificate{*root}, tls.VerifyOptions{
+		Time: "2001-01-10",
+		CRLs: [][]byte{crl},
+	})
+	if err != nil {
+		t.Fatalf("Error verifying chain: %s", err.Error())
+	}
+	if !result.Valid {
+		t.Errorf("Chain should be valid before the revocation date. Failures: %+v", result.Failures)
+	}
+
+	// After the CRL has expired
+	result, err = tls.VerifyChain(*leaf, nil, []tls.Certificate{*root}, tls.VerifyOptions{
+		Time: "2001-04-01",
+		CRLs: [][]byte{crl},
+	})
+	if err != nil {
+		t.Fatalf("Error verifying chain: %s", err.Error())
+	}
+	assertVerifyFailure(t, result, tls.VerifyFailureInvalidCRL, root)
+}
+
+func TestVerifyChainInvalidCRLSignature(t *testing.T) {
+	t.Parallel()
+
+	root, leaf := generateRevocationChain(t)
+
+	// An impostor root with the same subject but a different key
+	impostor, err := tls.GenerateCertificate(tls.CertificateRequest{
+		KeyType:            tls.KeyTypeECDSA_256,
+		SignatureAlgorithm: tls.SignatureAlgorithmSHA256,
+		Subject:            root.Subject,
+		Validity: tls.DateRange{
+			NotBefore: "2001-01-01",
+			NotAfter:  "2002-01-01",
+		},
+		Usage:                  tls.KeyUsage{CRLSign: true},
+		IsCertificateAuthority: true,
+	}, nil)
+	if err != nil {
+		t.Fatalf("Error generating certificate: %s", err.Error())
+	}
+	crl, err := tls.GenerateCRL(tls.CRLRequest{
+		Number:     "1",
+		ThisUpdate: "2001-02-01",
+		NextUpdate: "2001-03-01",
+	}, impostor)
+	if err != nil {
+		t.Fatalf("Error generating CRL: %s", err.Error())
+	}
+
+	result, err := tls.VerifyChain(*leaf, nil, []tls.Certificate{*root}, tls.VerifyOptions{
+		Time: "2001-02-15",
+		CRLs: [][]byte{crl},
+	})
+	if err != nil {
+		t.Fatalf("Error verifying chain: %s", err.Error())
+	}
+	assertVerifyFailure(t, result, tls.VerifyFailureInvalidCRL, root)
+}
+
+func TestVerifyChainInvalid(t *testing.T) {
+	t.Parallel()
+
+	chain := generateLongCertificateChain(t)
+	leaf, root := chain[0], chain[2]
+
+	if _, err := tls.VerifyChain(*leaf, nil, nil, tls.VerifyOptions{}); err == nil {
+		t.Errorf("No error seen when one expected for missing roots")
+	}
+	if _, err := tls.VerifyChain(*leaf, nil, []tls.Certificate{*root}, tls.VerifyOptions{Time: "invalid"}); err == nil {
+		t.Errorf("No error seen when one expected for invalid time")
+	}
+	if _, err := tls.VerifyChain(*leaf, nil, []tls.Certificate{*root}, tls.VerifyOptions{IPAddress: "192.0.2"}); err == nil {
+		t.Errorf("No error seen when one expected for invalid ip address")
+	}
+	if _, err := tls.VerifyChain(*leaf, nil, []tls.Certificate{*root}, tls.VerifyOptions{CRLs: [][]byte{[]byte("invalid")}}); err == nil {
+		t.Errorf("No error seen when one expected for invalid CRL")
+	}
+	if _, err := tls.VerifyChain(tls.Certificate{CertificateData: "invalid"}, nil, []tls.Certificate{*root}, tls.VerifyOptions{}); err == nil {
+		t.Errorf("No error seen when one expected for invalid leaf")
+	}
+}
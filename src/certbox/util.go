@@ -1,6 +1,10 @@
 package certbox
 
-import "strings"
+import (
+	"strings"
+
+	"github.com/tls-inspector/certbox/tls"
+)
 
 func filenameSafeString(in string) (out string) {
 	out = in
@@ -28,9 +32,19 @@ func filenameSafeString(in string) (out string) {
 	}
 
 	// Don't allow UNIX "hidden" files
-	if out[0] == '.' {
+	if len(out) > 0 && out[0] == '.' {
 		out = "_" + out
 	}
 
 	return out
 }
+
+// certificateFilename returns the file name for the given certificate without an extension, made up of its common
+// name and the start of its serial number
+func certificateFilename(certificate tls.Certificate) string {
+	serial := certificate.Serial
+	if len(serial) > 8 {
+		serial = serial[0:8]
+	}
+	return filenameSafeString(certificate.Subject.CommonName) + "_" + serial
+}
@@ -0,0 +1,17 @@
+package certbox
+
+import "github.com/tls-inspector/certbox/tls"
+
+// VerifyChainParameters parameters for verifying a certificate chain
+type VerifyChainParameters struct {
+	Leaf          tls.Certificate
+	Intermediates []tls.Certificate
+	Roots         []tls.Certificate
+	Options       tls.VerifyOptions
+}
+
+// VerifyChain will verify that the leaf certificate chains to one of the trusted roots and is valid for the given
+// options, returning every chain that was built and every reason the chain is not valid
+func VerifyChain(parameters VerifyChainParameters) (*tls.VerifyResult, error) {
+	return tls.VerifyChain(parameters.Leaf, parameters.Intermediates, parameters.Roots, parameters.Options)
+}
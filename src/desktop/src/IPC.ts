@@ -1,4 +1,4 @@
-import { Certificate, CertificateRequest, ExportedFile, ExportFormatType, RuntimeVersions } from './shared/types';
+import { Certificate, CertificateRequest, ExportedFile, ExportFormatType, RuntimeVersions, CertificateDetails, VerifyOptions, VerifyResult, LintFinding, CertificateDiff } from './shared/types';
 import { Options } from './shared/options';
 
 interface PreloadBridge {
@@ -8,6 +8,13 @@ interface PreloadBridge {
     generateCertificate: (requests: CertificateRequest[], importedRoot: Certificate) => Promise<Certificate[]>
     exportCSR: (request: CertificateRequest) => Promise<ExportedFile[]>
     exportCertificates: (certificates: Certificate[], format: ExportFormatType, password: string) => Promise<boolean>
+    inspectCertificate: (certificate: Certificate) => Promise<CertificateDetails>
+    inspectText: (certificate: Certificate) => Promise<string>
+    verifyChain: (leaf: Certificate, intermediates: Certificate[], roots: Certificate[], options: VerifyOptions) => Promise<VerifyResult>
+    lintCertificate: (certificate: Certificate) => Promise<LintFinding[]>
+    lintRequest: (request: CertificateRequest, issuer: Certificate) => Promise<LintFinding[]>
+    diffCertificates: (a: Certificate, b: Certificate) => Promise<CertificateDiff>
+    diffCertificateRequest: (certificate: Certificate, request: CertificateRequest, issuer: Certificate) => Promise<CertificateDiff>
     showCertificateContextMenu: (isRoot: boolean) => Promise<'delete' | 'duplicate'>
     cloneCertificate: () => Promise<CertificateRequest>
     runtimeVersions: () => Promise<RuntimeVersions>
@@ -74,6 +81,69 @@ export class IPC {
         return IPC.preload.exportCertificates(certificates, format, password);
     }
 
+    /**
+     * Get a detailed breakdown of a certificate
+     * @param certificate The certificate to inspect
+     */
+    public static inspectCertificate(certificate: Certificate): Promise<CertificateDetails> {
+        return IPC.preload.inspectCertificate(certificate);
+    }
+
+    /**
+     * Get a human readable description of a certificate, laid out like the text output of OpenSSL
+     * @param certificate The certificate to describe
+     */
+    public static inspectText(certificate: Certificate): Promise<string> {
+        return IPC.preload.inspectText(certificate);
+    }
+
+    /**
+     * Verify that a certificate chains to one of the trusted roots
+     * @param leaf The certificate to verify
+     * @param intermediates Untrusted intermediate certificates used to build the chain
+     * @param roots Trusted root certificates
+     * @param options Names, usage, time and revocation lists to verify with
+     */
+    public static verifyChain(leaf: Certificate, intermediates: Certificate[], roots: Certificate[], options: VerifyOptions): Promise<VerifyResult> {
+        return IPC.preload.verifyChain(leaf, intermediates, roots, options);
+    }
+
+    /**
+     * Check a certificate against the CA/Browser Forum Baseline Requirements and RFC 5280
+     * @param certificate The certificate to lint
+     */
+    public static lintCertificate(certificate: Certificate): Promise<LintFinding[]> {
+        return IPC.preload.lintCertificate(certificate);
+    }
+
+    /**
+     * Check a certificate request before it is generated
+     * @param request The certificate request to lint
+     * @param issuer Optional issuer of the certificate. The certificate is self-signed if not specified.
+     */
+    public static lintRequest(request: CertificateRequest, issuer: Certificate): Promise<LintFinding[]> {
+        return IPC.preload.lintRequest(request, issuer);
+    }
+
+    /**
+     * Compare two certificates field by field
+     * @param a The first certificate
+     * @param b The certificate to compare with the first
+     */
+    public static diffCertificates(a: Certificate, b: Certificate): Promise<CertificateDiff> {
+        return IPC.preload.diffCertificates(a, b);
+    }
+
+    /**
+     * Compare a certificate with the certificate that a request would generate
+     * @param certificate The existing certificate
+     * @param request The certificate request
+     * @param issuer Optional issuer of the request. The request is self-signed if not specified.
+     */
+    public static diffCertificateRequest(certificate: Certificate, request: CertificateRequest, issuer: Certificate): Promise<CertificateDiff> {
+        return IPC.preload.diffCertificateRequest(certificate, request, issuer);
+    }
+
     /**
      * Show the certificate context menu when the user right clicks on a certificate
      * @param isRoot If the selected certificate is a root certificate
@@ -1,4 +1,4 @@
-import { Certificate, CertificateRequest, ExportFormatType, ExportedFile, RuntimeVersions } from './shared/types';
+import { Certificate, CertificateRequest, ExportFormatType, ExportedFile, RuntimeVersions, CertificateDetails, VerifyOptions, VerifyResult, LintFinding, CertificateDiff } from './shared/types';
 import { Options } from './shared/options';
 import { IInterop } from './shared/IInterop';
 import { IPC } from './IPC';
@@ -35,6 +35,27 @@ export const Interop: IInterop = {
     cloneCertificate: function (): Promise<CertificateRequest> {
         return IPC.cloneCertificate();
     },
+    inspectCertificate: function (certificate: Certificate): Promise<CertificateDetails> {
+        return IPC.inspectCertificate(certificate);
+    },
+    inspectText: function (certificate: Certificate): Promise<string> {
+        return IPC.inspectText(certificate);
+    },
+    verifyChain: function (leaf: Certificate, intermediates: Certificate[], roots: Certificate[], options: VerifyOptions): Promise<VerifyResult> {
+        return IPC.verifyChain(leaf, intermediates, roots, options);
+    },
+    lintCertificate: function (certificate: Certificate): Promise<LintFinding[]> {
+        return IPC.lintCertificate(certificate);
+    },
+    lintRequest: function (request: CertificateRequest, issuer: Certificate): Promise<LintFinding[]> {
+        return IPC.lintRequest(request, issuer);
+    },
+    diffCertificates: function (a: Certificate, b: Certificate): Promise<CertificateDiff> {
+        return IPC.diffCertificates(a, b);
+    },
+    diffCertificateRequest: function (certificate: Certificate, request: CertificateRequest, issuer: Certificate): Promise<CertificateDiff> {
+        return IPC.diffCertificateRequest(certificate, request, issuer);
+    },
     getVersions: function (): Promise<RuntimeVersions> {
         return IPC.runtimeVersions();
     },
@@ -1,4 +1,4 @@
-import { Certificate, CertificateRequest } from '../shared/types';
+import { Certificate, CertificateRequest, OCSPResponderOptions, CertificateDetails, VerifyOptions, VerifyResult, LintFinding, CertificateDiff } from '../shared/types';
 import { spawn, ChildProcessWithoutNullStreams } from 'child_process';
 import { log } from './log';
 
@@ -14,6 +14,24 @@ enum CertGenActions {
     ConvertDERtoPEM = 'CONVERT_DER_PEM',
     ExtractPKCS12 = 'EXTRACT_P12',
     CreatePKCS12 = 'CREATE_PKCS12',
+    GenerateCRL = 'GENERATE_CRL',
+    ParseCRL = 'PARSE_CRL',
+    OCSPResponder = 'OCSP_RESPONDER',
+    SignCSR = 'SIGN_CSR',
+    RenewCertificate = 'RENEW_CERTIFICATE',
+    FaithfulCloneCertificate = 'FAITHFUL_CLONE_CERTIFICATE',
+    CloneCertificateChain = 'CLONE_CERTIFICATE_CHAIN',
+    ProbeServer = 'PROBE_SERVER',
+    InspectCertificate = 'INSPECT_CERTIFICATE',
+    InspectText = 'INSPECT_TEXT',
+    VerifyChain = 'VERIFY_CHAIN',
+    Lint = 'LINT',
+    DiffCertificates = 'DIFF_CERTIFICATES',
+}
+
+export interface OCSPResponder {
+    Address: string;
+    stop: () => void;
 }
 
 export class certgen {
@@ -146,4 +164,148 @@ export class certgen {
             return response.Version;
         });
     }
-}
\ No newline at end of file
+
+    public static async inspectCertificate(certificate: Certificate): Promise<CertificateDetails> {
+        const config = {
+            Certificate: certificate,
+        };
+
+        log.debug('Inspecting certificate', config);
+        return this.runCertgen(CertGenActions.InspectCertificate, config).then(output => {
+            return JSON.parse(output) as CertificateDetails;
+        });
+    }
+
+    public static async inspectText(certificate: Certificate): Promise<string> {
+        const config = {
+            Certificate: certificate,
+        };
+
+        log.debug('Inspecting certificate text', config);
+        return this.runCertgen(CertGenActions.InspectText, config).then(output => {
+            return JSON.parse(output) as string;
+        });
+    }
+
+    public static async verifyChain(leaf: Certificate, intermediates: Certificate[], roots: Certificate[], options: VerifyOptions): Promise<VerifyResult> {
+        const config = {
+            Leaf: leaf,
+            Intermediates: intermediates,
+            Roots: roots,
+            Options: options,
+        };
+
+        log.debug('Verifying chain', config);
+        return this.runCertgen(CertGenActions.VerifyChain, config).then(output => {
+            return JSON.parse(output) as VerifyResult;
+        });
+    }
+
+    public static async lintCertificate(certificate: Certificate): Promise<LintFinding[]> {
+        const config = {
+            Certificate: certificate,
+        };
+
+        log.debug('Linting certificate', config);
+        return this.runCertgen(CertGenActions.Lint, config).then(output => {
+            return JSON.parse(output) as LintFinding[];
+        });
+    }
+
+    public static async lintRequest(request: CertificateRequest, issuer: Certificate): Promise<LintFinding[]> {
+        const config = {
+            Request: request,
+            Issuer: issuer,
+        };
+
+        log.debug('Linting certificate request', config);
+        return this.runCertgen(CertGenActions.Lint, config).then(output => {
+            return JSON.parse(output) as LintFinding[];
+        });
+    }
+
+    public static async diffCertificates(a: Certificate, b: Certificate): Promise<CertificateDiff> {
+        const config = {
+            A: a,
+            B: b,
+        };
+
+        log.debug('Comparing certificates', config);
+        return this.runCertgen(CertGenActions.DiffCertificates, config).then(output => {
+            return JSON.parse(output) as CertificateDiff;
+        });
+    }
+
+    public static async diffCertificateRequest(certificate: Certificate, request: CertificateRequest, issuer: Certificate): Promise<CertificateDiff> {
+        const config = {
+            A: certificate,
+            Request: request,
+            Issuer: issuer,
+        };
+
+        log.debug('Comparing certificate with request', config);
+        return this.runCertgen(CertGenActions.DiffCertificates, config).then(output => {
+            return JSON.parse(output) as CertificateDiff;
+        });
+    }
+
+    /**
+     * Start an OCSP responder. The responder runs until stop is called, unlike other certgen actions which resolve
+     * once the process exits.
+     */
+    public static async startOCSPResponder(options: OCSPResponderOptions): Promise<OCSPResponder> {
+        return new Promise((resolve, reject) => {
+            let process: ChildProcessWithoutNullStreams;
+            try {
+                log.debug(certgen.certgenExePath, [CertGenActions.OCSPResponder]);
+                process = spawn(certgen.certgenExePath, [CertGenActions.OCSPResponder]);
+            } catch (err) {
+                log.error('Error spawning process', err);
+                reject(err);
+                return;
+            }
+
+            interface responseType {
+                Address: string;
+            }
+
+            let started = false;
+            let output = '';
+            process.stdout.on('data', data => {
+                log.debug('stdout', data.toString());
+                if (started) {
+                    return;
+                }
+                output += data;
+                const newline = output.indexOf('\n');
+                if (newline === -1) {
+                    return;
+                }
+                started = true;
+                const response = JSON.parse(output.substring(0, newline)) as responseType;
+                resolve({
+                    Address: response.Address,
+                    stop: () => {
+                        process.kill();
+                    },
+                });
+            });
+
+            let error = '';
+            process.stderr.on('data', data => {
+                log.error('stderr', data.toString());
+                error += data;
+            });
+
+            process.on('close', code => {
+                if (!started) {
+                    log.error('Certgen error', { code: code, error: error });
+                    reject(error.trim());
+                }
+            });
+
+            process.stdin.write(JSON.stringify(options));
+            process.stdin.end();
+        });
+    }
+}
@@ -1,5 +1,5 @@
 import { BrowserWindow, ipcMain, shell, WebContents } from 'electron';
-import { Certificate, CertificateRequest, ExportFormatType } from '../shared/types';
+import { Certificate, CertificateRequest, ExportFormatType, VerifyOptions } from '../shared/types';
 import { Dialog } from './dialog';
 import { Exporter } from './exporter';
 import { Menu } from './menu';
@@ -51,6 +51,48 @@ ipcMain.handle('export_certificates', async (event, args) => {
     }
 });
 
+ipcMain.handle('inspect_certificate', async (event, args) => {
+    const certificate = args[0] as Certificate;
+    return certgen.inspectCertificate(certificate);
+});
+
+ipcMain.handle('inspect_text', async (event, args) => {
+    const certificate = args[0] as Certificate;
+    return certgen.inspectText(certificate);
+});
+
+ipcMain.handle('verify_chain', async (event, args) => {
+    const leaf = args[0] as Certificate;
+    const intermediates = args[1] as Certificate[];
+    const roots = args[2] as Certificate[];
+    const options = args[3] as VerifyOptions;
+    return certgen.verifyChain(leaf, intermediates, roots, options);
+});
+
+ipcMain.handle('lint_certificate', async (event, args) => {
+    const certificate = args[0] as Certificate;
+    return certgen.lintCertificate(certificate);
+});
+
+ipcMain.handle('lint_request', async (event, args) => {
+    const request = args[0] as CertificateRequest;
+    const issuer = args[1] as Certificate;
+    return certgen.lintRequest(request, issuer);
+});
+
+ipcMain.handle('diff_certificates', async (event, args) => {
+    const a = args[0] as Certificate;
+    const b = args[1] as Certificate;
+    return certgen.diffCertificates(a, b);
+});
+
+ipcMain.handle('diff_certificate_request', async (event, args) => {
+    const certificate = args[0] as Certificate;
+    const request = args[1] as CertificateRequest;
+    const issuer = args[2] as Certificate;
+    return certgen.diffCertificateRequest(certificate, request, issuer);
+});
+
 ipcMain.handle('show_certificate_context_menu', async (event, args) => {
     const isRoot = args[0] as boolean;
 
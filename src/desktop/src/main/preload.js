@@ -8,6 +8,13 @@ contextBridge.exposeInMainWorld('IPC', {
     generateCertificate: (requests, importedRoot) => ipcRenderer.invoke('generate_certificate', [requests, importedRoot]),
     exportCSR: (request) => ipcRenderer.invoke('export_csr', [request]),
     exportCertificates: (certificates, format, password) => ipcRenderer.invoke('export_certificates', [certificates, format, password]),
+    inspectCertificate: (certificate) => ipcRenderer.invoke('inspect_certificate', [certificate]),
+    inspectText: (certificate) => ipcRenderer.invoke('inspect_text', [certificate]),
+    verifyChain: (leaf, intermediates, roots, options) => ipcRenderer.invoke('verify_chain', [leaf, intermediates, roots, options]),
+    lintCertificate: (certificate) => ipcRenderer.invoke('lint_certificate', [certificate]),
+    lintRequest: (request, issuer) => ipcRenderer.invoke('lint_request', [request, issuer]),
+    diffCertificates: (a, b) => ipcRenderer.invoke('diff_certificates', [a, b]),
+    diffCertificateRequest: (certificate, request, issuer) => ipcRenderer.invoke('diff_certificate_request', [certificate, request, issuer]),
     showCertificateContextMenu: (isRoot) => ipcRenderer.invoke('show_certificate_context_menu', [isRoot]),
     cloneCertificate: () => ipcRenderer.invoke('clone_certificate'),
     runtimeVersions: () => ipcRenderer.invoke('runtime_versions', []),
@@ -19,8 +19,8 @@ export const DateRangeEdit: React.FC<DateRangeEditProps> = (props: DateRangeEdit
 
     React.useEffect(() => {
         setValue(value => {
-            value.NotBefore = NotBefore + 'T00:00:00.00Z';
-            value.NotAfter = NotAfter + 'T23:59:59.99Z';
+            value.NotBefore = NotBefore;
+            value.NotAfter = NotAfter;
             return {...value};
         });
     }, [NotBefore, NotAfter]);
@@ -8,6 +8,7 @@ import { Input, NumberInput } from './Input';
 import { Icon } from './Icon';
 import '../css/Checkbox.scss';
 import { Radio } from './Radio';
+import { Checkbox } from './Checkbox';
 
 interface ExtensionsEditProps {
     defaultValue: CertificateExtension[];
@@ -73,7 +74,7 @@ export const ExtensionsEdit: React.FC<ExtensionsEditProps> = (props: ExtensionsE
                         <Button small onClick={editExtension(idx)}><Icon.Pencil /></Button>
                         <Button small onClick={deleteExtension(idx)}><Icon.Trash /></Button>
                         <strong>{ext.OID} </strong>
-                        <code>{ext.Value as string}</code>
+                        <code>{ext.Raw ?? (ext.ASN1 ? ext.ASN1.Type : ext.Value as string)}</code>
                     </div>);
                 })
             }
@@ -88,10 +89,16 @@ interface ExtensionDialogProps {
 }
 const ExtensionDialog: React.FC<ExtensionDialogProps> = (props: ExtensionDialogProps) => {
     const [OID, SetOID] = React.useState(props.defaultValue.OID);
-    const [Value, SetValue] = React.useState(props.defaultValue.Value);
+    const [Critical, SetCritical] = React.useState(props.defaultValue.Critical ?? false);
+    const [Value, SetValue] = React.useState(props.defaultValue.Raw ?? props.defaultValue.Value);
     const [ValueType, SetValueType] = React.useState('String');
 
     React.useEffect(() => {
+        if (props.defaultValue.Raw) {
+            SetValueType('Raw');
+            return;
+        }
+
         switch (typeof props.defaultValue.Value) {
             case 'string':
                 if (!Number.isNaN(Date.parse(props.defaultValue.Value))) {
@@ -127,6 +134,10 @@ const ExtensionDialog: React.FC<ExtensionDialogProps> = (props: ExtensionDialogP
         SetValue(value);
     };
 
+    const changeRawValue = (value: string) => {
+        SetValue(value);
+    };
+
     const valueTypeChoices = [
         {
             label: 'String',
@@ -139,6 +150,10 @@ const ExtensionDialog: React.FC<ExtensionDialogProps> = (props: ExtensionDialogP
         {
             label: 'Time',
             value: 'Time',
+        },
+        {
+            label: 'Raw DER (hex)',
+            value: 'Raw',
         }
     ];
 
@@ -150,6 +165,8 @@ const ExtensionDialog: React.FC<ExtensionDialogProps> = (props: ExtensionDialogP
                 return (<NumberInput placeholder="1234" label="Value" defaultValue={Value as number} onChange={changeNumberValue} required />);
             case 'Time':
                 return (<Input type="datetime-local" placeholder="example" label="Value" defaultValue={Value as string} onChange={changeTimeValue} required />);
+            case 'Raw':
+                return (<Input type="text" placeholder="0403010203" label="Value" defaultValue={Value as string} onChange={changeRawValue} required />);
         }
     };
 
@@ -164,10 +181,22 @@ const ExtensionDialog: React.FC<ExtensionDialogProps> = (props: ExtensionDialogP
         {
             label: 'Apply',
             onClick: () => {
-                props.onChange({
-                    OID: OID,
-                    Value: Value,
-                });
+                if (ValueType === 'Raw') {
+                    props.onChange({
+                        OID: OID,
+                        Critical: Critical,
+                        Raw: Value as string,
+                    });
+                } else if (props.defaultValue.ASN1 && Value === undefined) {
+                    // Structured values can't be edited here, so keep the value unless a new one was entered
+                    props.onChange({ OID: OID, Critical: Critical, ASN1: props.defaultValue.ASN1 });
+                } else {
+                    props.onChange({
+                        OID: OID,
+                        Critical: Critical,
+                        Value: Value,
+                    });
+                }
                 return Promise.resolve(true);
             }
         }
@@ -176,6 +205,7 @@ const ExtensionDialog: React.FC<ExtensionDialogProps> = (props: ExtensionDialogP
     return (
         <Dialog title="Custom Extended Key Usage" buttons={buttons}>
             <Input type="oid" placeholder="1.3.6.1.5.5.7.3.1" label="OID" defaultValue={OID} onChange={changeOID} required />
+            <Checkbox label="Critical" defaultValue={Critical} onChange={SetCritical} />
             <Radio label="Type" choices={valueTypeChoices} defaultValue={ValueType} onChange={changeValueType} />
             { valueField() }
         </Dialog>
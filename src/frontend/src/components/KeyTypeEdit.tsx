@@ -31,8 +31,20 @@ export const KeyTypeEdit: React.FC<KeyTypeEditProps> = (props: KeyTypeEditProps)
         props.onChangeSignatureAlgorithm(SignatureAlgorithm);
     }, [SignatureAlgorithm]);
 
+    const isRSA = (keyType: EKeyType) => {
+        return keyType == EKeyType.KeyTypeRSA_2048 || keyType == EKeyType.KeyTypeRSA_3072 || keyType == EKeyType.KeyTypeRSA_4096 || keyType == EKeyType.KeyTypeRSA_8192;
+    };
+
+    const isPSS = (signatureAlgorithm: ESignatureAlgorithm) => {
+        return signatureAlgorithm == ESignatureAlgorithm.SignatureAlgorithmSHA256PSS || signatureAlgorithm == ESignatureAlgorithm.SignatureAlgorithmSHA384PSS || signatureAlgorithm == ESignatureAlgorithm.SignatureAlgorithmSHA512PSS;
+    };
+
     const didChangeKeyType = (t: string) => {
         SetKeyType(t as EKeyType);
+        // PSS signatures are only available with RSA keys
+        if (!isRSA(t as EKeyType) && isPSS(SignatureAlgorithm)) {
+            SetSignatureAlgorithm(ESignatureAlgorithm.SignatureAlgorithmSHA256);
+        }
     };
 
     const didChangeSignatureAlgorithm = (t: string) => {
@@ -47,6 +59,10 @@ export const KeyTypeEdit: React.FC<KeyTypeEditProps> = (props: KeyTypeEditProps)
                 label: 'RSA (2048)',
                 value: EKeyType.KeyTypeRSA_2048,
             },
+            {
+                label: 'RSA (3072)',
+                value: EKeyType.KeyTypeRSA_3072,
+            },
             {
                 label: 'RSA (4096)',
                 value: EKeyType.KeyTypeRSA_4096,
@@ -62,6 +78,14 @@ export const KeyTypeEdit: React.FC<KeyTypeEditProps> = (props: KeyTypeEditProps)
             {
                 label: 'ECDSA (P384)',
                 value: EKeyType.KeyTypeECDSA_384,
+            },
+            {
+                label: 'ECDSA (P521)',
+                value: EKeyType.KeyTypeECDSA_521,
+            },
+            {
+                label: 'Ed25519',
+                value: EKeyType.KeyTypeEd25519,
             }
         ];
         signatureAlgorithmChoices = [
@@ -78,6 +102,22 @@ export const KeyTypeEdit: React.FC<KeyTypeEditProps> = (props: KeyTypeEditProps)
                 value: ESignatureAlgorithm.SignatureAlgorithmSHA512,
             }
         ];
+        if (isRSA(KeyType)) {
+            signatureAlgorithmChoices.push(
+                {
+                    label: 'SHA-256 (PSS)',
+                    value: ESignatureAlgorithm.SignatureAlgorithmSHA256PSS,
+                },
+                {
+                    label: 'SHA-384 (PSS)',
+                    value: ESignatureAlgorithm.SignatureAlgorithmSHA384PSS,
+                },
+                {
+                    label: 'SHA-512 (PSS)',
+                    value: ESignatureAlgorithm.SignatureAlgorithmSHA512PSS,
+                }
+            );
+        }
     } else {
         keyTypeChoices = [
             {
@@ -101,12 +141,14 @@ export const KeyTypeEdit: React.FC<KeyTypeEditProps> = (props: KeyTypeEditProps)
         return (<div className="mt-1"><Icon.Label icon={<Icon.ExclamationTriangle color='yellow'/>} label="The selected key type may take a few moments to generate." /></div>);
     };
 
+    // The signature algorithm radio is recreated when the available choices change, so that it shows the algorithm
+    // selected by didChangeKeyType
     return (
         <Section title="Cryptography">
             <Radio label="Key Type" choices={keyTypeChoices} defaultValue={KeyType} onChange={didChangeKeyType} />
             {rsaWarning()}
             <div className="mt-2">
-                <Radio label="Signature Algorithm" choices={signatureAlgorithmChoices} defaultValue={SignatureAlgorithm} onChange={didChangeSignatureAlgorithm} />
+                <Radio key={isRSA(KeyType) ? 'rsa' : 'other'} label="Signature Algorithm" choices={signatureAlgorithmChoices} defaultValue={SignatureAlgorithm} onChange={didChangeSignatureAlgorithm} />
             </div>
         </Section>
     );
@@ -1,4 +1,4 @@
-import { Certificate, CertificateRequest, ExportFormatType, RuntimeVersions, ExportedFile } from './types';
+import { Certificate, CertificateRequest, ExportFormatType, RuntimeVersions, ExportedFile, CertificateDetails, VerifyOptions, VerifyResult, LintFinding, CertificateDiff } from './types';
 import { Options } from './options';
 
 export interface IInterop {
@@ -14,6 +14,13 @@ export interface IInterop {
     zipFiles: (files: ExportedFile[]) => Promise<ExportedFile>
     saveFile: (file: ExportedFile) => void
     cloneCertificate: () => Promise<CertificateRequest>
+    inspectCertificate: (certificate: Certificate) => Promise<CertificateDetails>
+    inspectText: (certificate: Certificate) => Promise<string>
+    verifyChain: (leaf: Certificate, intermediates: Certificate[], roots: Certificate[], options: VerifyOptions) => Promise<VerifyResult>
+    lintCertificate: (certificate: Certificate) => Promise<LintFinding[]>
+    lintRequest: (request: CertificateRequest, issuer: Certificate) => Promise<LintFinding[]>
+    diffCertificates: (a: Certificate, b: Certificate) => Promise<CertificateDiff>
+    diffCertificateRequest: (certificate: Certificate, request: CertificateRequest, issuer: Certificate) => Promise<CertificateDiff>
     onShowAboutDialog: (callback: () => void) => void
     onShowOptionsDialog: (callback: () => void) => void
     getVersions: () => Promise<RuntimeVersions>
@@ -1,6 +1,8 @@
 import { Calendar } from '../services/Calendar';
 
 export interface Certificate {
+    Serial: string;
+    SerialHex: string;
     CertificateAuthority: boolean;
     Subject: Name;
     CertificateData: string;
@@ -9,33 +11,155 @@ export interface Certificate {
 
 export enum KeyType {
 	KeyTypeRSA_2048 = 'rsa2048',
+	KeyTypeRSA_3072 = 'rsa3072',
 	KeyTypeRSA_4096 = 'rsa4096',
 	KeyTypeRSA_8192 = 'rsa8192',
 	KeyTypeECDSA_256 = 'ecc256',
 	KeyTypeECDSA_384 = 'ecc384',
+	KeyTypeECDSA_521 = 'ecc521',
+	KeyTypeEd25519 = 'ed25519',
 }
 
 export enum SignatureAlgorithm {
     SignatureAlgorithmSHA256 = 'sha256',
     SignatureAlgorithmSHA384 = 'sha384',
     SignatureAlgorithmSHA512 = 'sha512',
+    SignatureAlgorithmSHA256PSS = 'sha256-pss',
+    SignatureAlgorithmSHA384PSS = 'sha384-pss',
+    SignatureAlgorithmSHA512PSS = 'sha512-pss',
 }
 
 export interface CertificateRequest {
+    Label?: string;
+    IssuerLabel?: string;
     KeyType: KeyType;
+    PrivateKey?: string;
+    PrivateKeyPassword?: string;
     SignatureAlgorithm: SignatureAlgorithm;
     Subject: Name;
     Validity: DateRange;
     AlternateNames?: AlternateName[];
     Usage: KeyUsage;
     IsCertificateAuthority?: boolean;
+    NameConstraints?: NameConstraints;
+    MaxPathLen?: number;
+    MaxPathLenZero?: boolean;
+    Policies?: CertificatePolicy[];
+    PolicyConstraints?: PolicyConstraints;
+    StatusProviders?: StatusProviders;
     Imported?: boolean;
     Extensions?: CertificateExtension[];
+    Serial?: SerialNumber;
+}
+
+export enum SerialStrategy {
+    Random = 'random',
+    Explicit = 'explicit',
+    Sequential = 'sequential',
+    Prefixed = 'prefixed',
+}
+
+export interface SerialNumber {
+    Strategy?: SerialStrategy;
+    Value?: string;
+    RandomBits?: number;
+}
+
+export interface NameConstraints {
+    Critical?: boolean;
+    PermittedDNSDomains?: string[];
+    ExcludedDNSDomains?: string[];
+    PermittedEmailAddresses?: string[];
+    ExcludedEmailAddresses?: string[];
+    PermittedIPRanges?: string[];
+    ExcludedIPRanges?: string[];
+    PermittedURIDomains?: string[];
+    ExcludedURIDomains?: string[];
+}
+
+export enum CertificatePolicyOID {
+    AnyPolicy = '2.5.29.32.0',
+    ExtendedValidation = '2.23.140.1.1',
+    DomainValidated = '2.23.140.1.2.1',
+    OrganizationValidated = '2.23.140.1.2.2',
+    IndividualValidated = '2.23.140.1.2.3',
+}
+
+export interface CertificatePolicy {
+    OID: string;
+    CPS?: string[];
+    UserNotices?: UserNotice[];
+}
+
+export interface UserNotice {
+    Organization?: string;
+    NoticeNumbers?: number[];
+    ExplicitText?: string;
+}
+
+export interface PolicyMapping {
+    IssuerDomainPolicy: string;
+    SubjectDomainPolicy: string;
+}
+
+export interface PolicyConstraints {
+    Mappings?: PolicyMapping[];
+    RequireExplicitPolicy?: number;
+    RequireExplicitPolicyZero?: boolean;
+    InhibitPolicyMapping?: number;
+    InhibitPolicyMappingZero?: boolean;
+    InhibitAnyPolicy?: number;
+    InhibitAnyPolicyZero?: boolean;
+}
+
+export interface StatusProviders {
+    CRL?: string[];
+    OCSP?: string[];
+    CAIssuers?: string[];
 }
 
 export interface CertificateExtension {
     OID: string;
-    Value: unknown;
+    Critical?: boolean;
+    Value?: unknown;
+    /**
+     * Hex encoded DER value of the extension
+     */
+    Raw?: string;
+    ASN1?: ASN1Value;
+}
+
+export enum ASN1Type {
+    Boolean = 'boolean',
+    Integer = 'integer',
+    Enumerated = 'enumerated',
+    BitString = 'bitstring',
+    OctetString = 'octetstring',
+    Null = 'null',
+    OID = 'oid',
+    UTF8String = 'utf8string',
+    PrintableString = 'printablestring',
+    IA5String = 'ia5string',
+    NumericString = 'numericstring',
+    VisibleString = 'visiblestring',
+    UTCTime = 'utctime',
+    GeneralizedTime = 'generalizedtime',
+    Sequence = 'sequence',
+    Set = 'set',
+    Raw = 'raw',
+}
+
+export enum ASN1Tagging {
+    Implicit = 'implicit',
+    Explicit = 'explicit',
+}
+
+export interface ASN1Value {
+    Type: ASN1Type;
+    Value?: string;
+    Children?: ASN1Value[];
+    Tagging?: ASN1Tagging;
+    Tag?: number;
 }
 
 export function BlankCertificateRequest(isRoot: boolean): CertificateRequest {
@@ -86,6 +210,20 @@ export interface Name {
     Province: string;
     Country: string;
     CommonName: string;
+    OrganizationalUnit?: string[];
+    StreetAddress?: string[];
+    PostalCode?: string[];
+    SerialNumber?: string;
+    DomainComponent?: string[];
+    EmailAddress?: string[];
+    Attributes?: NameAttribute[];
+    RDNs?: NameAttribute[][];
+}
+
+export interface NameAttribute {
+    OID: string;
+    Value: string;
+    Type?: ASN1Type;
 }
 
 export interface DateRange {
@@ -138,6 +276,94 @@ export interface KeyUsage {
     CustomEKUs?: string[];
 }
 
+export enum RevocationReason {
+    Unspecified = 0,
+    KeyCompromise = 1,
+    CACompromise = 2,
+    AffiliationChanged = 3,
+    Superseded = 4,
+    CessationOfOperation = 5,
+    CertificateHold = 6,
+    RemoveFromCRL = 8,
+    PrivilegeWithdrawn = 9,
+    AACompromise = 10,
+}
+
+export interface RevokedCertificate {
+    Serial: string;
+    RevocationDate: string;
+    ReasonCode: RevocationReason;
+}
+
+export interface CRLRequest {
+    Number: string;
+    BaseCRLNumber?: string;
+    ThisUpdate?: string;
+    NextUpdate: string;
+    SignatureAlgorithm?: SignatureAlgorithm;
+    Revoked: RevokedCertificate[];
+}
+
+export enum OCSPStatusType {
+    Good = 'good',
+    Revoked = 'revoked',
+    Unknown = 'unknown',
+}
+
+export interface OCSPStatus {
+    Serial: string;
+    Status: OCSPStatusType;
+    RevocationDate?: string;
+    ReasonCode?: RevocationReason;
+}
+
+export interface OCSPResponderOptions {
+    Issuer: Certificate;
+    Responder?: Certificate;
+    Statuses: OCSPStatus[];
+    Validity?: string;
+    Address?: string;
+}
+
+export enum RenewValidityMode {
+    Shift = 'shift',
+    Extend = 'extend',
+}
+
+export interface RenewOptions {
+    RotateKey?: boolean;
+    KeyType?: KeyType;
+    Validity?: DateRange;
+    ValidityMode?: RenewValidityMode;
+    SignatureAlgorithm?: SignatureAlgorithm;
+}
+
+export interface FaithfulCloneOptions {
+    KeyType?: KeyType;
+}
+
+export enum StartTLSProtocol {
+    SMTP = 'smtp',
+    IMAP = 'imap',
+    POP3 = 'pop3',
+    LDAP = 'ldap',
+    Postgres = 'postgres',
+}
+
+export interface ProbeOptions {
+    Address: string;
+    ServerName?: string;
+    StartTLS?: StartTLSProtocol;
+    Timeout?: string;
+}
+
+export interface ProbeResult {
+    Certificates: Certificate[];
+    Requests: CertificateRequest[];
+    Version: string;
+    CipherSuite: string;
+}
+
 export interface RuntimeVersions {
     app: string;
     electron: string;
@@ -150,3 +376,159 @@ export interface ExportedFile {
     Mime: string;
     Data: string;
 }
+
+export interface NameAttributeDetails {
+    OID: string;
+    ShortName: string;
+    Value: string;
+    Type: ASN1Type;
+}
+
+export interface NameDetails {
+    DN: string;
+    RDNs: NameAttributeDetails[][];
+}
+
+export interface PublicKeyDetails {
+    Algorithm: string;
+    AlgorithmOID: string;
+    Bits: number;
+    Curve: string;
+    Modulus: string;
+    Exponent: number;
+    Value: string;
+}
+
+export enum GeneralNameType {
+    DNS = 'dns',
+    Email = 'email',
+    IP = 'ip',
+    URI = 'uri',
+    Directory = 'directory',
+    Other = 'other',
+    RegisteredID = 'registeredid',
+    Raw = 'raw',
+}
+
+export interface GeneralName {
+    Type: GeneralNameType;
+    Value: string;
+    OID: string;
+}
+
+export interface AuthorityKeyID {
+    KeyID: string;
+    Issuer?: GeneralName[];
+    SerialHex: string;
+}
+
+export interface BasicConstraints {
+    CertificateAuthority: boolean;
+    MaxPathLen?: number;
+}
+
+export interface AccessDescription {
+    Method: string;
+    Location: GeneralName;
+}
+
+export interface ExtensionDetails {
+    OID: string;
+    Name: string;
+    Critical: boolean;
+    Raw: string;
+    Error: string;
+    SubjectKeyID: string;
+    AuthorityKeyID?: AuthorityKeyID;
+    KeyUsage?: string[];
+    ExtendedKeyUsage?: string[];
+    BasicConstraints?: BasicConstraints;
+    AlternateNames?: GeneralName[];
+    NameConstraints?: NameConstraints;
+    Policies?: CertificatePolicy[];
+    PolicyConstraints?: PolicyConstraints;
+    CRLDistributionPoints?: GeneralName[];
+    AuthorityInfoAccess?: AccessDescription[];
+    ASN1?: ASN1Value;
+}
+
+export interface CertificateDetails {
+    Version: number;
+    Serial: string;
+    SerialHex: string;
+    SignatureAlgorithm: string;
+    SignatureAlgorithmOID: string;
+    Signature: string;
+    Issuer: NameDetails;
+    Subject: NameDetails;
+    NotBefore: string;
+    NotAfter: string;
+    PublicKey: PublicKeyDetails;
+    Fingerprints: {
+        SHA1: string;
+        SHA256: string;
+    };
+    Pins: {
+        SHA256: string;
+        SHA256Hex: string;
+    };
+    Extensions: ExtensionDetails[];
+}
+
+export interface VerifyOptions {
+    DNSName?: string;
+    IPAddress?: string;
+    Usage?: KeyUsage;
+    Time?: string;
+    /** Base64 encoded PEM or DER certificate revocation lists */
+    CRLs?: string[];
+}
+
+export enum VerifyFailureReason {
+    Expired = 'expired',
+    NotYetValid = 'not_yet_valid',
+    UnknownAuthority = 'unknown_authority',
+    NameMismatch = 'name_mismatch',
+    UsageMismatch = 'usage_mismatch',
+    ConstraintViolation = 'constraint_violation',
+    Revoked = 'revoked',
+    InvalidCRL = 'invalid_crl',
+    Invalid = 'invalid',
+}
+
+export interface VerifyFailure {
+    Reason: VerifyFailureReason;
+    Subject: string;
+    SerialHex: string;
+    Message: string;
+}
+
+export interface VerifyResult {
+    Valid: boolean;
+    Chains: Certificate[][];
+    Failures: VerifyFailure[];
+}
+
+export enum LintSeverity {
+    Error = 'error',
+    Warning = 'warning',
+    Notice = 'notice',
+}
+
+export interface LintFinding {
+    RuleID: string;
+    Severity: LintSeverity;
+    Source: string;
+    Message: string;
+}
+
+export interface CertificateDifference {
+    Field: string;
+    A: string;
+    B: string;
+}
+
+export interface CertificateDiff {
+    Differences: CertificateDifference[];
+    Text: string;
+}
@@ -1,5 +1,5 @@
 /* eslint-disable @typescript-eslint/no-unused-vars */
-import { Certificate, CertificateRequest, ExportFormatType, RuntimeVersions, ExportedFile } from './shared/types';
+import { Certificate, CertificateRequest, ExportFormatType, RuntimeVersions, ExportedFile, CertificateDetails, VerifyOptions, VerifyResult, LintFinding, CertificateDiff } from './shared/types';
 import { IInterop } from './shared/IInterop';
 import { Options } from './shared/options';
 import { Wasm } from './Wasm';
@@ -83,6 +83,42 @@ export const Interop: IInterop = {
             return Wasm.CloneCertificate(pemData);
         });
     },
+    inspectCertificate: function (certificate: Certificate): Promise<CertificateDetails> {
+        return Promise.resolve(Wasm.InspectCertificate({ Certificate: certificate }));
+    },
+    inspectText: function (certificate: Certificate): Promise<string> {
+        return Promise.resolve(Wasm.InspectText({ Certificate: certificate }));
+    },
+    verifyChain: function (leaf: Certificate, intermediates: Certificate[], roots: Certificate[], options: VerifyOptions): Promise<VerifyResult> {
+        return Promise.resolve(Wasm.VerifyChain({
+            Leaf: leaf,
+            Intermediates: intermediates,
+            Roots: roots,
+            Options: options
+        }));
+    },
+    lintCertificate: function (certificate: Certificate): Promise<LintFinding[]> {
+        return Promise.resolve(Wasm.Lint({ Certificate: certificate }));
+    },
+    lintRequest: function (request: CertificateRequest, issuer: Certificate): Promise<LintFinding[]> {
+        return Promise.resolve(Wasm.Lint({
+            Request: request,
+            Issuer: issuer
+        }));
+    },
+    diffCertificates: function (a: Certificate, b: Certificate): Promise<CertificateDiff> {
+        return Promise.resolve(Wasm.DiffCertificates({
+            A: a,
+            B: b
+        }));
+    },
+    diffCertificateRequest: function (certificate: Certificate, request: CertificateRequest, issuer: Certificate): Promise<CertificateDiff> {
+        return Promise.resolve(Wasm.DiffCertificates({
+            A: certificate,
+            Request: request,
+            Issuer: issuer
+        }));
+    },
     onShowAboutDialog: function (callback: () => void): void { },
     onShowOptionsDialog: function (callback: () => void): void { },
     getVersions: function (): Promise<RuntimeVersions> {
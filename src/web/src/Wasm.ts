@@ -1,4 +1,4 @@
-import { Certificate, CertificateRequest, RuntimeVersions, ExportedFile } from './shared/types';
+import { Certificate, CertificateRequest, RuntimeVersions, ExportedFile, CertificateDetails, VerifyOptions, VerifyResult, LintFinding, CertificateDiff } from './shared/types';
 import { Rand } from './services/Rand';
 
 export interface WasmError {
@@ -36,6 +36,34 @@ export interface ZipFilesResponse {
     File: ExportedFile;
 }
 
+export interface InspectCertificateParameters {
+    Certificate: Certificate;
+}
+
+export interface InspectTextParameters {
+    Certificate: Certificate;
+}
+
+export interface VerifyChainParameters {
+    Leaf: Certificate;
+    Intermediates?: Certificate[];
+    Roots: Certificate[];
+    Options?: VerifyOptions;
+}
+
+export interface LintParameters {
+    Request?: CertificateRequest;
+    Issuer?: Certificate;
+    Certificate?: Certificate;
+}
+
+export interface DiffCertificatesParameters {
+    A: Certificate;
+    B?: Certificate;
+    Request?: CertificateRequest;
+    Issuer?: Certificate;
+}
+
 interface WasmBridge {
     Ping: (...args: string[]) => string;
     ImportRootCertificate: (data: number[], password: string) => string;
@@ -46,6 +74,11 @@ interface WasmBridge {
     GetVersion: (...args: string[]) => string;
     GetVersions: (...args: string[]) => string;
     ZipFiles: (...args: string[]) => string;
+    InspectCertificate: (...args: string[]) => string;
+    InspectText: (...args: string[]) => string;
+    VerifyChain: (...args: string[]) => string;
+    Lint: (...args: string[]) => string;
+    DiffCertificates: (...args: string[]) => string;
 }
 
 export class Wasm {
@@ -133,4 +166,44 @@ export class Wasm {
         }
         return response as ZipFilesResponse;
     }
+
+    public static InspectCertificate(params: InspectCertificateParameters): CertificateDetails {
+        const response = JSON.parse(this.wasm.InspectCertificate(JSON.stringify(params)));
+        if ((response as WasmError).Error) {
+            throw new Error((response as WasmError).Error);
+        }
+        return response as CertificateDetails;
+    }
+
+    public static InspectText(params: InspectTextParameters): string {
+        const response = JSON.parse(this.wasm.InspectText(JSON.stringify(params)));
+        if ((response as WasmError).Error) {
+            throw new Error((response as WasmError).Error);
+        }
+        return response as string;
+    }
+
+    public static VerifyChain(params: VerifyChainParameters): VerifyResult {
+        const response = JSON.parse(this.wasm.VerifyChain(JSON.stringify(params)));
+        if ((response as WasmError).Error) {
+            throw new Error((response as WasmError).Error);
+        }
+        return response as VerifyResult;
+    }
+
+    public static Lint(params: LintParameters): LintFinding[] {
+        const response = JSON.parse(this.wasm.Lint(JSON.stringify(params)));
+        if ((response as WasmError).Error) {
+            throw new Error((response as WasmError).Error);
+        }
+        return response as LintFinding[];
+    }
+
+    public static DiffCertificates(params: DiffCertificatesParameters): CertificateDiff {
+        const response = JSON.parse(this.wasm.DiffCertificates(JSON.stringify(params)));
+        if ((response as WasmError).Error) {
+            throw new Error((response as WasmError).Error);
+        }
+        return response as CertificateDiff;
+    }
 }